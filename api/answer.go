@@ -6,6 +6,7 @@ import (
 	"LanShan/service"
 	"LanShan/utils"
 	"LanShan/utils/snowflake"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -55,10 +56,14 @@ func AnswerDetailHandler(c *gin.Context) {
 		return
 	}
 
-	// 2、根据id取出数据(查数据库)
-	answer, err := mysql.GetAnswerById(answerId)
+	// 2、根据id取出数据(查数据库)，未开始比赛中的题目的题解同样隐藏
+	answer, err := service.GetAnswerDetail(answerId, getProblemViewer(c))
 	if err != nil {
-		zap.L().Error("mysql.GetAnswerById(answerId) failed", zap.Error(err))
+		zap.L().Error("service.GetAnswerDetail(answerId) failed", zap.Error(err))
+		if errors.Is(err, service.ErrorProblemNotStarted) {
+			utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
@@ -70,7 +75,7 @@ func AnswerDetailHandler(c *gin.Context) {
 func AnswerListHandler(c *gin.Context) {
 	// 获取参数(从URL中获取id)
 	problemIdStr := c.Param("id")
-	problemId, err := strconv.ParseInt(problemIdStr, 10, 64)
+	problemId, err := strconv.ParseUint(problemIdStr, 10, 64)
 	if err != nil {
 		zap.L().Error("get problem detail with invalid param", zap.Error(err))
 		utils.ResponseError(c, utils.CodeInvalidParams)
@@ -79,8 +84,12 @@ func AnswerListHandler(c *gin.Context) {
 	// 获取分页参数
 	page, size := getPageInfo(c)
 	// 获取数据
-	data, err := mysql.GetAnswerList(page, size, problemId)
+	data, err := service.GetAnswerList(page, size, problemId, getProblemViewer(c))
 	if err != nil {
+		if errors.Is(err, service.ErrorProblemNotStarted) {
+			utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
@@ -97,28 +106,12 @@ func AnswerUpdateHandler(c *gin.Context) {
 		return
 	}
 
-	// 获取参数(从URL中获取id)
-	answerIdStr := c.Param("id")
-	answerId, err := strconv.ParseInt(answerIdStr, 10, 64)
-	pastAnswer, err := mysql.GetAnswerById(answerId)
-	if err != nil {
-		zap.L().Error("get answer detail with invalid param", zap.Error(err))
-		utils.ResponseError(c, utils.CodeServerBusy)
-	}
-
-	// 获取作者ID
-	UserID, err := getCurrentUserID(c)
-	if err != nil {
-		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
-		utils.ResponseError(c, utils.CodeNotLogin)
-		return
-	}
-	ok := UserID == pastAnswer.AuthorID
+	// 获取参数(从URL中获取id)并校验是否有权限修改
+	answerId, ok := checkAnswerPermission(c)
 	if !ok {
-		zap.L().Error("update problem with invalid param")
-		utils.ResponseError(c, utils.CodeInvalidParams)
 		return
 	}
+	newAnswer.AnswerID = uint64(answerId)
 
 	answer, err := mysql.UpdateAnswer(&newAnswer)
 	if err != nil {
@@ -132,35 +125,46 @@ func AnswerUpdateHandler(c *gin.Context) {
 }
 
 func AnswerDeleteHandler(c *gin.Context) {
-	// 获取参数(从URL中获取id)
-	answerIdStr := c.Param("id")
-	answerId, err := strconv.ParseInt(answerIdStr, 10, 64)
-	answer, err := service.GetProblemById(answerId)
+	// 获取参数(从URL中获取id)并校验是否有权限删除
+	answerId, ok := checkAnswerPermission(c)
+	if !ok {
+		return
+	}
+	err := mysql.DeleteAnswer(answerId)
 	if err != nil {
-		zap.L().Error("get answer detail with invalid param", zap.Error(err))
+		zap.L().Error("mysql.DeleteAnswer() failed", zap.Error(err))
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
 
-	// 获取作者ID
-	UserID, err := getCurrentUserID(c)
+	utils.ResponseSuccess(c, nil)
+}
+
+// checkAnswerPermission 从URL中获取题解id并校验当前用户能否管理该题解(作者、版主或管理员)，
+// 校验失败时直接返回响应
+func checkAnswerPermission(c *gin.Context) (answerId int64, ok bool) {
+	answerIdStr := c.Param("id")
+	answerId, err := strconv.ParseInt(answerIdStr, 10, 64)
 	if err != nil {
-		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
-		utils.ResponseError(c, utils.CodeNotLogin)
-		return
-	}
-	ok := UserID == answer.AuthorId
-	if !ok {
-		zap.L().Error("delete problem with invalid param")
+		zap.L().Error("get answer detail with invalid param", zap.Error(err))
 		utils.ResponseError(c, utils.CodeInvalidParams)
 		return
 	}
-	err = mysql.DeleteAnswer(answerId)
+	answer, err := mysql.GetAnswerById(answerId)
 	if err != nil {
-		zap.L().Error("mysql.DeleteAnswer() failed", zap.Error(err))
+		zap.L().Error("mysql.GetAnswerById(answerId) failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
-
-	utils.ResponseSuccess(c, nil)
+	if !canManage(c, answer.AuthorID) {
+		zap.L().Error("current user cannot manage the answer",
+			zap.Int64("answerID", answerId))
+		utils.ResponseError(c, utils.CodeNoPermission)
+		return
+	}
+	return answerId, true
 }
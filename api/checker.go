@@ -0,0 +1,55 @@
+package api
+
+import (
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CheckerUpdateHandler 设置题目的checker，自定义checker编译失败时返回编译信息
+func CheckerUpdateHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	var p models.ParamChecker
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("update checker with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	checker, err := service.SaveChecker(uint64(problemId), &p)
+	if err != nil {
+		zap.L().Error("service.SaveChecker() failed", zap.Error(err))
+		var ce *judge.CompileError
+		if errors.As(err, &ce) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, ce.Message)
+			return
+		}
+		if errors.Is(err, service.ErrorCheckerSource) || errors.Is(err, judge.ErrorUnknownLanguage) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, checker)
+}
+
+// CheckerDetailHandler 查询题目的checker，自定义checker的源代码只对作者可见
+func CheckerDetailHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	checker, err := service.GetChecker(uint64(problemId))
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, checker)
+}
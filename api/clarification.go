@@ -0,0 +1,106 @@
+package api
+
+import (
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// ClarificationCreateHandler 参赛者提问
+func ClarificationCreateHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	var p models.ParamClarification
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("create clarification with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	clar, err := service.CreateClarification(contestId, userID, &p)
+	if err != nil {
+		zap.L().Error("service.CreateClarification() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, clar)
+}
+
+// ClarificationAnswerHandler 回复答疑，仅比赛创建者、版主和管理员可用
+func ClarificationAnswerHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	clarificationId, err := strconv.ParseUint(c.Param("cid"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	var p models.ParamClarificationAnswer
+	if err = c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("answer clarification with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	if err = service.AnswerClarification(contestId, clarificationId, userID, getCurrentUserRole(c), &p); err != nil {
+		zap.L().Error("service.AnswerClarification() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+// ClarificationListHandler 答疑列表，查看后未读数清零
+func ClarificationListHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetClarificationList(contestId, userID, getCurrentUserRole(c))
+	if err != nil {
+		zap.L().Error("service.GetClarificationList() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ClarificationUnreadHandler 未读答疑数量，供客户端轮询
+func ClarificationUnreadHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetUnreadClarificationCount(contestId, userID, getCurrentUserRole(c))
+	if err != nil {
+		zap.L().Error("service.GetUnreadClarificationCount() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
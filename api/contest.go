@@ -0,0 +1,189 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// CreateContestHandler 创建比赛
+func CreateContestHandler(c *gin.Context) {
+	var p models.ParamContest
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("create contest with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	contest, err := service.CreateContest(userID, &p)
+	if err != nil {
+		zap.L().Error("service.CreateContest() failed", zap.Error(err))
+		if errors.Is(err, service.ErrorContestProblem) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, "题目不存在")
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, contest)
+}
+
+// ContestListHandler 分页展示比赛列表
+func ContestListHandler(c *gin.Context) {
+	page, size := getPageInfo(c)
+	data, err := service.GetContestList(page, size)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ContestDetailHandler 比赛详情
+func ContestDetailHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	// 未登录时按游客处理
+	userID, _ := getCurrentUserID(c)
+	data, err := service.GetContestDetail(contestId, userID, getCurrentUserRole(c))
+	if err != nil {
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ContestRegisterHandler 报名比赛
+func ContestRegisterHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	var p models.ParamContestRegister
+	// 公开比赛可以不带请求体
+	_ = c.ShouldBindJSON(&p)
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	if err = service.RegisterContest(contestId, userID, &p); err != nil {
+		zap.L().Error("service.RegisterContest() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+// ContestScoreboardHandler 比赛排行榜
+func ContestScoreboardHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	// 未登录时按游客处理
+	userID, _ := getCurrentUserID(c)
+	data, err := service.GetScoreboard(contestId, userID, getCurrentUserRole(c))
+	if err != nil {
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ContestUnfreezeHandler 比赛结束后解除封榜
+func ContestUnfreezeHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	if err := service.UnfreezeContest(contestId); err != nil {
+		zap.L().Error("service.UnfreezeContest() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+// ContestResolverHandler 以 NDJSON 导出滚榜事件序列，每行一个事件
+func ContestResolverHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	events, err := service.GetResolverEvents(contestId)
+	if err != nil {
+		zap.L().Error("service.GetResolverEvents() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="contest-%d.ndjson"`, contestId))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	for _, event := range events {
+		if err = enc.Encode(event); err != nil {
+			zap.L().Error("write resolver event failed", zap.Error(err))
+			return
+		}
+	}
+}
+
+// getContestID 从URL中获取比赛id，失败时直接返回响应
+func getContestID(c *gin.Context) (contestId uint64, ok bool) {
+	contestId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("get contest with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return 0, false
+	}
+	return contestId, true
+}
+
+// responseContestError 比赛相关的业务错误返回具体信息，其余按服务繁忙处理
+func responseContestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, service.ErrorContestEnded),
+		errors.Is(err, service.ErrorContestNotRunning),
+		errors.Is(err, service.ErrorContestPassword),
+		errors.Is(err, service.ErrorContestNotRegistered),
+		errors.Is(err, service.ErrorProblemNotInContest),
+		errors.Is(err, service.ErrorProblemInContest),
+		errors.Is(err, service.ErrorScoreboardHidden),
+		errors.Is(err, service.ErrorContestNotEnded),
+		errors.Is(err, service.ErrorVirtualExists),
+		errors.Is(err, service.ErrorContestParticipated),
+		errors.Is(err, service.ErrorVirtualNotStarted),
+		errors.Is(err, service.ErrorTeamConflict),
+		errors.Is(err, service.ErrorContestNotRated),
+		errors.Is(err, service.ErrorRatingFinalized),
+		errors.Is(err, service.ErrorRatingNoContestant):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	case errors.Is(err, service.ErrorContestPermission):
+		utils.ResponseError(c, utils.CodeNoPermission)
+	case errors.Is(err, service.ErrorNotTeamCaptain):
+		utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
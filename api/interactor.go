@@ -0,0 +1,74 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// InteractorUpdateHandler 设置题目的交互器，编译失败时返回编译信息
+func InteractorUpdateHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	var p models.ParamInteractor
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("update interactor with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	interactor, err := service.SaveInteractor(uint64(problemId), &p)
+	if err != nil {
+		zap.L().Error("service.SaveInteractor() failed", zap.Error(err))
+		var ce *judge.CompileError
+		if errors.As(err, &ce) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, ce.Message)
+			return
+		}
+		if errors.Is(err, judge.ErrorUnknownLanguage) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, interactor)
+}
+
+// InteractorDetailHandler 查询题目的交互器，非交互题返回空
+func InteractorDetailHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	interactor, err := service.GetInteractor(uint64(problemId))
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, interactor)
+}
+
+// InteractorDeleteHandler 删除交互器，题目恢复为普通题目
+func InteractorDeleteHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	if err := service.DeleteInteractor(uint64(problemId)); err != nil {
+		zap.L().Error("service.DeleteInteractor() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"LanShan/service"
+	"LanShan/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageListHandler 获取可用的评测语言列表
+func LanguageListHandler(c *gin.Context) {
+	utils.ResponseSuccess(c, service.GetLanguageList())
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"LanShan/api"
+	"LanShan/api/middlewares/jwt"
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// JWTAuthMiddleware 基于JWT的认证中间件
+func JWTAuthMiddleware() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		authHeader := c.Request.Header.Get("Authorization")
+		if authHeader == "" {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidToken, "请求头缺少Auth Token")
+			c.Abort()
+			return
+		}
+		// 按空格分割
+		parts := strings.SplitN(authHeader, " ", 2)
+		if !(len(parts) == 2 && parts[0] == "Bearer") {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidToken, "Token格式不对")
+			c.Abort()
+			return
+		}
+		// parts[1]是获取到的tokenString，我们使用之前定义好的解析JWT的函数来解析它
+		mc, err := jwt.ParseToken(parts[1])
+		if err != nil {
+			fmt.Println(err)
+			utils.ResponseError(c, utils.CodeInvalidToken)
+			c.Abort()
+			return
+		}
+		// 角色以数据库为准，Token有效期很长，被降级的用户不能继续使用旧Token中的角色
+		role, err := service.GetUserRole(mc.UserID)
+		if err != nil {
+			if errors.Is(err, mysql.ErrorUserNotExit) {
+				utils.ResponseError(c, utils.CodeInvalidToken)
+			} else {
+				utils.ResponseError(c, utils.CodeServerBusy)
+			}
+			c.Abort()
+			return
+		}
+		// 将当前请求的userID信息保存到请求的上下文c上
+		c.Set(api.ContextUserIDKey, mc.UserID)
+		c.Set(api.ContextUserRoleKey, role)
+		c.Next() // 后续的处理函数可以用过c.Get(ContextUserIDKey)来获取当前请求的用户信息
+	}
+}
+
+// RequireRole 只允许指定角色访问，需放在JWT认证中间件之后，管理员总是允许访问
+func RequireRole(roles ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role := c.GetString(api.ContextUserRoleKey)
+		if role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		utils.ResponseError(c, utils.CodeNoPermission)
+		c.Abort()
+	}
+}
+
+// QueryTokenMiddleware 浏览器建立WebSocket连接时无法设置请求头，
+// 允许通过 ?token= 传递Token，之后仍由JWT认证中间件校验
+func QueryTokenMiddleware() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if token := c.Query("token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
+
+// OptionalJWTMiddleware 公开接口使用，携带有效Token时记录当前用户信息，否则按游客继续处理
+func OptionalJWTMiddleware() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if mc, err := jwt.ParseToken(parts[1]); err == nil {
+				if role, err := service.GetUserRole(mc.UserID); err == nil {
+					c.Set(api.ContextUserIDKey, mc.UserID)
+					c.Set(api.ContextUserRoleKey, role)
+				}
+			}
+		}
+		c.Next()
+	}
+}
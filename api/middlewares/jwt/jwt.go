@@ -10,6 +10,7 @@ import (
 type MyClaims struct {
 	UserID   uint64 `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
@@ -24,11 +25,12 @@ func keyFunc(_ *jwt.Token) (i interface{}, err error) {
 const TokenExpireDuration = time.Hour * 2
 
 // GenToken 生成access token 和 refresh token
-func GenToken(userID uint64, username string) (aToken, rToken string, err error) {
+func GenToken(userID uint64, username, role string) (aToken, rToken string, err error) {
 	// 创建一个我们自己的声明
 	c := MyClaims{
 		userID,   // 自定义字段
 		username, // 自定义字段
+		role,     // 自定义字段，仅供前端展示，鉴权时以数据库中的角色为准
 		jwt.StandardClaims{ // JWT规定的7个官方字段
 			ExpiresAt: time.Now().Add(
 				time.Duration(viper.GetInt("auth.jwt_expire")) * time.Hour).Unix(), // 过期时间
@@ -61,8 +63,8 @@ func ParseToken(tokenString string) (claims *MyClaims, err error) {
 	return
 }
 
-// RefreshToken 刷新AccessToken
-func RefreshToken(aToken, rToken string) (newAToken, newRToken string, err error) {
+// RefreshToken 刷新AccessToken，新Token中的角色通过 getRole 重新查询，不沿用旧Token
+func RefreshToken(aToken, rToken string, getRole func(userID uint64) (string, error)) (newAToken, newRToken string, err error) {
 	// refresh token无效直接返回
 	if _, err = jwt.Parse(rToken, keyFunc); err != nil {
 		return
@@ -74,8 +76,12 @@ func RefreshToken(aToken, rToken string) (newAToken, newRToken string, err error
 	v, _ := err.(*jwt.ValidationError)
 
 	// 当access token是过期错误 并且 refresh token没有过期时就创建一个新的access token
-	if v.Errors == jwt.ValidationErrorExpired {
-		return GenToken(claims.UserID, claims.Username)
+	if v != nil && v.Errors == jwt.ValidationErrorExpired {
+		role, err := getRole(claims.UserID)
+		if err != nil {
+			return "", "", err
+		}
+		return GenToken(claims.UserID, claims.Username, role)
 	}
 	return
 }
@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/spf13/viper"
+	"testing"
+	"time"
+)
+
+// signedToken 生成指定过期时间的 access token
+func signedToken(t *testing.T, userID uint64, expiresAt time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, MyClaims{
+		UserID:         userID,
+		Username:       "alice",
+		Role:           "admin",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: expiresAt.Unix(), Issuer: "onlineJudge"},
+	}).SignedString(mySecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRefreshToken(t *testing.T) {
+	viper.Set("auth.jwt_expire", 1)
+	_, rToken, err := GenToken(1, "alice", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	getRole := func(userID uint64) (string, error) { return "user", nil }
+
+	// access token 未过期时不签发新 token
+	aToken, newRToken, err := RefreshToken(signedToken(t, 1, time.Now().Add(time.Hour)), rToken, getRole)
+	if err != nil || aToken != "" || newRToken != "" {
+		t.Errorf("RefreshToken(valid) = %q, %q, %v, want no new token", aToken, newRToken, err)
+	}
+
+	// access token 过期后重新签发，角色以 getRole 为准
+	aToken, _, err = RefreshToken(signedToken(t, 1, time.Now().Add(-time.Hour)), rToken, getRole)
+	if err != nil {
+		t.Fatalf("RefreshToken(expired) error = %v", err)
+	}
+	claims, err := ParseToken(aToken)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if claims.UserID != 1 || claims.Role != "user" {
+		t.Errorf("claims = %+v, want user 1 with role user", claims)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// PlagiarismCheckHandler 对比赛或题目的提交发起查重
+func PlagiarismCheckHandler(c *gin.Context) {
+	var p models.ParamPlagiarism
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("plagiarism check with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	report, err := service.StartPlagiarismCheck(userID, &p)
+	if err != nil {
+		zap.L().Error("service.StartPlagiarismCheck() failed", zap.Error(err))
+		responsePlagiarismError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, report)
+}
+
+// PlagiarismReportHandler 查重报告，按相似度降序列出可疑的提交对
+func PlagiarismReportHandler(c *gin.Context) {
+	reportId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetPlagiarismReport(reportId)
+	if err != nil {
+		responsePlagiarismError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// PlagiarismPairHandler 可疑提交对的两份源代码及相同片段
+func PlagiarismPairHandler(c *gin.Context) {
+	reportId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	pairId, err := strconv.ParseUint(c.Param("pid"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetPlagiarismPair(reportId, pairId)
+	if err != nil {
+		responsePlagiarismError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+func responsePlagiarismError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, service.ErrorPlagiarismScope):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
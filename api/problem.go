@@ -2,10 +2,14 @@ package api
 
 import (
 	"LanShan/models"
+	"LanShan/problemio"
 	"LanShan/service"
 	"LanShan/utils"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"net/http"
 	"strconv"
 )
 
@@ -31,6 +35,10 @@ func CreateProblemHandler(c *gin.Context) {
 	err = service.CreateProblem(&problem)
 	if err != nil {
 		zap.L().Error("service.CreateProblem failed", zap.Error(err))
+		if errors.Is(err, service.ErrorTagNotExist) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
@@ -38,12 +46,18 @@ func CreateProblemHandler(c *gin.Context) {
 	utils.ResponseSuccess(c, nil)
 }
 
-// ProblemListHandler 问题列表
+// ProblemListHandler 问题列表，可按版块、标签和难度筛选
 func ProblemListHandler(c *gin.Context) {
+	var p models.ParamProblemList
+	if err := c.ShouldBindQuery(&p); err != nil {
+		zap.L().Error("get problem list with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
 	// 获取分页参数
-	page, size := getPageInfo(c)
+	p.Page, p.Size = getPageInfo(c)
 	// 获取数据
-	data, err := service.GetProblemList(page, size)
+	data, err := service.GetProblemList(&p, getPreferredLocales(c), getProblemViewer(c))
 	if err != nil {
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
@@ -62,10 +76,14 @@ func ProblemDetailHandler(c *gin.Context) {
 		return
 	}
 
-	// 2、根据id取出id帖子数据(查数据库)
-	problem, err := service.GetProblemById(problemId)
+	// 2、根据id取出id帖子数据(查数据库)，按请求的语言选择题面
+	problem, err := service.GetLocalizedProblem(problemId, getPreferredLocales(c), getProblemViewer(c))
 	if err != nil {
 		zap.L().Error("service.GetProblem(problemID) failed", zap.Error(err))
+		if errors.Is(err, service.ErrorProblemNotStarted) {
+			utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
@@ -84,32 +102,24 @@ func ProblemUpdateHandler(c *gin.Context) {
 		return
 	}
 
-	// 获取参数(从URL中获取id)
-	problemIdStr := c.Param("id")
-	problemId, err := strconv.ParseInt(problemIdStr, 10, 64)
-	pastProblem, err := service.GetProblemById(problemId)
-	if err != nil {
-		zap.L().Error("get problem detail with invalid param", zap.Error(err))
-		utils.ResponseError(c, utils.CodeServerBusy)
+	// 获取参数(从URL中获取id)并校验是否有权限修改
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
 	}
 
-	// 获取作者ID
-	UserID, err := getCurrentUserID(c)
+	userID, err := getCurrentUserID(c)
 	if err != nil {
-		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
 		utils.ResponseError(c, utils.CodeNotLogin)
 		return
 	}
-	ok := UserID == pastProblem.AuthorId
-	if !ok {
-		zap.L().Error("update problem with invalid param")
-		utils.ResponseError(c, utils.CodeInvalidParams)
-		return
-	}
-
-	problem, err := service.UpdateProblem(&newProblem, problemId)
+	problem, err := service.UpdateProblem(&newProblem, problemId, userID)
 	if err != nil {
 		zap.L().Error("service.UpdateProblem() failed")
+		if errors.Is(err, service.ErrorTagNotExist) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
 		utils.ResponseError(c, utils.CodeServerBusy)
 		return
 	}
@@ -119,9 +129,29 @@ func ProblemUpdateHandler(c *gin.Context) {
 }
 
 func ProblemDeleteHandler(c *gin.Context) {
-	// 获取参数(从URL中获取id)
+	// 获取参数(从URL中获取id)并校验是否有权限修改
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	if err := service.DeleteProblem(problemId); err != nil {
+		zap.L().Error("service.DeleteProblem() failed", zap.Int64("problemID", problemId), zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+// checkProblemPermission 从URL中获取题目id并校验当前用户能否管理该题目(作者、版主或管理员)，
+// 校验失败时直接返回响应
+func checkProblemPermission(c *gin.Context) (problemId int64, ok bool) {
 	problemIdStr := c.Param("id")
 	problemId, err := strconv.ParseInt(problemIdStr, 10, 64)
+	if err != nil {
+		zap.L().Error("get problem detail with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
 	problem, err := service.GetProblemById(problemId)
 	if err != nil {
 		zap.L().Error("get problem detail with invalid param", zap.Error(err))
@@ -129,20 +159,77 @@ func ProblemDeleteHandler(c *gin.Context) {
 		return
 	}
 
-	// 获取作者ID
-	UserID, err := getCurrentUserID(c)
+	if !canManage(c, problem.AuthorId) {
+		zap.L().Error("current user cannot manage the problem",
+			zap.Int64("problemID", problemId))
+		utils.ResponseError(c, utils.CodeNoPermission)
+		return
+	}
+	return problemId, true
+}
+
+// ProblemImportHandler 上传 Polygon 题目包(zip)或 FPS XML 导入题目
+func ProblemImportHandler(c *gin.Context) {
+	var p models.ParamProblemImport
+	if err := c.ShouldBind(&p); err != nil {
+		zap.L().Error("import problem with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
 	if err != nil {
-		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
 		utils.ResponseError(c, utils.CodeNotLogin)
 		return
 	}
-	ok := UserID == problem.AuthorId
-	if !ok {
-		zap.L().Error("delete problem with invalid param")
+	fh, err := c.FormFile("file")
+	if err != nil {
+		zap.L().Error("import problem without file", zap.Error(err))
 		utils.ResponseError(c, utils.CodeInvalidParams)
 		return
 	}
-	service.DeleteProblem(problemId)
+	f, err := fh.Open()
+	if err != nil {
+		zap.L().Error("open uploaded file failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	defer f.Close()
 
-	utils.ResponseSuccess(c, nil)
+	data, err := service.ImportProblems(userID, &p, f, fh.Size)
+	if err != nil {
+		zap.L().Error("service.ImportProblems() failed", zap.Error(err))
+		if errors.Is(err, problemio.ErrorFormat) || errors.Is(err, problemio.ErrorTooLarge) ||
+			errors.Is(err, service.ErrorProblemImport) || errors.Is(err, service.ErrorTestCaseTooLarge) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ProblemExportHandler 导出题目，format 为 fps(默认)或 polygon
+func ProblemExportHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", problemio.FormatFPS)
+	if format != problemio.FormatFPS && format != problemio.FormatPolygon {
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, "format 只能是 fps 或 polygon")
+		return
+	}
+	export, err := service.ExportProblem(uint64(problemId), format)
+	if err != nil {
+		zap.L().Error("service.ExportProblem() failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Header("Content-Type", export.ContentType)
+	c.Status(http.StatusOK)
+	if err = export.Write(c.Writer); err != nil {
+		zap.L().Error("write problem export failed", zap.Error(err))
+	}
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// RatingFinalizeHandler 根据比赛最终排名计算等级分
+func RatingFinalizeHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	data, err := service.FinalizeRating(contestId)
+	if err != nil {
+		zap.L().Error("service.FinalizeRating() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// UserRatingHandler 用户等级分及历史变化
+func UserRatingHandler(c *gin.Context) {
+	userId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetUserRating(userId)
+	if err != nil {
+		zap.L().Error("service.GetUserRating() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorUserNotExit) {
+			utils.ResponseError(c, utils.CodeUserNotExist)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
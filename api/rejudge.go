@@ -0,0 +1,81 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// RejudgeHandler 按题目、用户、提交id或时间范围重新评测
+func RejudgeHandler(c *gin.Context) {
+	var p models.ParamRejudge
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("rejudge with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	rejudge, err := service.Rejudge(userID, &p)
+	if err != nil {
+		zap.L().Error("service.Rejudge() failed", zap.Error(err))
+		if errors.Is(err, service.ErrorRejudgeCondition) || errors.Is(err, service.ErrorRejudgeEmpty) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, rejudge)
+}
+
+// RejudgeDetailHandler 查询重新评测进度
+func RejudgeDetailHandler(c *gin.Context) {
+	rejudgeId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	rejudge, err := service.GetRejudge(rejudgeId)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, rejudge)
+}
+
+// SubmissionHistoryHandler 查询提交重新评测前的结果
+func SubmissionHistoryHandler(c *gin.Context) {
+	submissionId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetSubmissionHistory(submissionId, userID, getCurrentUserRole(c))
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
@@ -1,13 +1,17 @@
 package api
 
 import (
+	"LanShan/models"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 const (
-	ContextUserIDKey = "userID"
+	ContextUserIDKey   = "userID"
+	ContextUserRoleKey = "userRole"
 )
 
 var (
@@ -28,6 +32,33 @@ func getCurrentUserID(c *gin.Context) (userID uint64, err error) {
 	return
 }
 
+// getCurrentUserRole 获取当前用户的角色，旧Token中没有角色时视为普通用户
+func getCurrentUserRole(c *gin.Context) string {
+	role := c.GetString(ContextUserRoleKey)
+	if role == "" {
+		role = models.RoleUser
+	}
+	return role
+}
+
+// canManage 当前用户是否可以修改、删除 ownerID 发布的内容：本人、版主和管理员可以
+func canManage(c *gin.Context, ownerID uint64) bool {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		return false
+	}
+	if userID == ownerID {
+		return true
+	}
+	return models.IsManagerRole(getCurrentUserRole(c))
+}
+
+// getProblemViewer 当前查看题目的用户，公开接口需配合可选的JWT认证中间件，游客的用户id为0
+func getProblemViewer(c *gin.Context) *models.ProblemViewer {
+	userID, _ := getCurrentUserID(c)
+	return &models.ProblemViewer{UserID: userID, Role: getCurrentUserRole(c)}
+}
+
 func getPageInfo(c *gin.Context) (int64, int64) {
 	pageStr := c.Query("page")
 	SizeStr := c.Query("size")
@@ -47,3 +78,51 @@ func getPageInfo(c *gin.Context) (int64, int64) {
 	}
 	return page, size
 }
+
+// getPreferredLocales 按偏好顺序返回请求的语言：先取 ?lang=，再按 q 值取 Accept-Language，
+// 只保留主语言标签(如 zh-CN 取 zh)并去重
+func getPreferredLocales(c *gin.Context) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(part, ";")
+		tag := weighted{locale: strings.TrimSpace(fields[0]), q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				q, err := strconv.ParseFloat(f[2:], 64)
+				if err != nil {
+					q = 0
+				}
+				tag.q = q
+			}
+		}
+		if tag.locale == "" || tag.locale == "*" || tag.q <= 0 {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	candidates := make([]string, 0, len(tags)+1)
+	if lang := c.Query("lang"); lang != "" {
+		candidates = append(candidates, lang)
+	}
+	for _, tag := range tags {
+		candidates = append(candidates, tag.locale)
+	}
+	locales := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, locale := range candidates {
+		locale = strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+		if locale == "" || seen[locale] {
+			continue
+		}
+		seen[locale] = true
+		locales = append(locales, locale)
+	}
+	return locales
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// ProblemRevisionListHandler 题目的版本列表
+func ProblemRevisionListHandler(c *gin.Context) {
+	problemId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetProblemRevisions(problemId, getProblemViewer(c))
+	if err != nil {
+		responseRevisionError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ProblemRevisionDiffHandler 比较题目的两个版本
+func ProblemRevisionDiffHandler(c *gin.Context) {
+	problemId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	var p models.ParamRevisionDiff
+	if err = c.ShouldBindQuery(&p); err != nil {
+		zap.L().Error("diff revisions with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	data, err := service.GetProblemRevisionDiff(problemId, &p, getProblemViewer(c))
+	if err != nil {
+		responseRevisionError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ProblemRollbackHandler 把题目回滚到指定版本，需要题目作者、版主或管理员
+func ProblemRollbackHandler(c *gin.Context) {
+	var p models.ParamProblemRollback
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("rollback problem with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.RollbackProblem(uint64(problemId), p.Version, userID)
+	if err != nil {
+		zap.L().Error("service.RollbackProblem() failed", zap.Error(err))
+		responseRevisionError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+func responseRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, service.ErrorRevisionCurrent):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	case errors.Is(err, service.ErrorProblemNotStarted):
+		utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
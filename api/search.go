@@ -0,0 +1,27 @@
+package api
+
+import (
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SearchHandler 按关键词搜索题目或题解
+func SearchHandler(c *gin.Context) {
+	var p models.ParamSearch
+	if err := c.ShouldBindQuery(&p); err != nil {
+		zap.L().Error("search with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	p.Page, p.Size = getPageInfo(c)
+	data, err := service.Search(&p, getProblemViewer(c))
+	if err != nil {
+		zap.L().Error("service.Search() failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	streamPingInterval = 15 * time.Second // 保活间隔，防止代理断开空闲连接
+	streamWriteTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// 接口本身以Token鉴权，不依赖Cookie，允许跨域连接
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// watchSubmission 解析提交id并订阅评测进度，失败时直接返回错误响应
+func watchSubmission(c *gin.Context) (events <-chan *models.SubmissionEvent, cancel func(), ok bool) {
+	submissionId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		zap.L().Error("watch submission with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	events, cancel, err = service.WatchSubmission(submissionId, userID, getCurrentUserRole(c))
+	if err != nil {
+		zap.L().Error("service.WatchSubmission() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	return events, cancel, true
+}
+
+// SubmissionStreamHandler 以Server-Sent Events推送评测进度，事件名即事件类型，评测结束后关闭连接
+func SubmissionStreamHandler(c *gin.Context) {
+	events, cancel, ok := watchSubmission(c)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // 关闭nginx缓冲
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return event.Type != models.EventResult
+		case <-ticker.C:
+			// 注释行，客户端会忽略
+			_, err := w.Write([]byte(": ping\n\n"))
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// SubmissionWebSocketHandler 以WebSocket推送评测进度，每条消息是一个JSON事件，评测结束后关闭连接
+func SubmissionWebSocketHandler(c *gin.Context) {
+	events, cancel, ok := watchSubmission(c)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		zap.L().Error("upgrade websocket failed", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+	// 只读取控制消息，客户端断开时结束订阅
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err = conn.WriteJSON(event); err != nil {
+				return
+			}
+			if event.Type == models.EventResult {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+					time.Now().Add(streamWriteTimeout))
+				return
+			}
+		case <-ticker.C:
+			if err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// SubmitHandler 提交代码
+func SubmitHandler(c *gin.Context) {
+	// 1、获取参数及校验参数
+	var p models.ParamSubmit
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("submit with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+
+	// 获取提交者ID
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+
+	// 2、创建提交记录
+	submission, err := service.CreateSubmission(userID, getCurrentUserRole(c), &p)
+	if err != nil {
+		zap.L().Error("service.CreateSubmission failed", zap.Error(err))
+		if errors.Is(err, judge.ErrorUnknownLanguage) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		// 比赛相关的错误
+		responseContestError(c, err)
+		return
+	}
+
+	// 3、返回响应
+	utils.ResponseSuccess(c, gin.H{
+		"submission_id": strconv.FormatUint(submission.SubmissionID, 10),
+		"status":        submission.Status,
+	})
+}
+
+// SubmissionDetailHandler 根据Id查询提交详情
+func SubmissionDetailHandler(c *gin.Context) {
+	// 1、获取参数(从URL中获取id)
+	submissionIdStr := c.Param("id")
+	submissionId, err := strconv.ParseInt(submissionIdStr, 10, 64)
+	if err != nil {
+		zap.L().Error("get submission detail with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		zap.L().Error("GetCurrentUserID() failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+
+	// 2、根据id取出提交数据
+	submission, err := service.GetSubmissionByID(submissionId, userID, getCurrentUserRole(c))
+	if err != nil {
+		zap.L().Error("service.GetSubmissionByID(submissionId) failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+
+	// 3、返回响应
+	utils.ResponseSuccess(c, submission)
+}
+
+// SubmissionListHandler 按用户、题目、状态筛选提交记录
+func SubmissionListHandler(c *gin.Context) {
+	var p models.ParamSubmissionList
+	if err := c.ShouldBindQuery(&p); err != nil {
+		zap.L().Error("get submission list with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	// 获取分页参数
+	p.Page, p.Size = getPageInfo(c)
+	// 获取数据
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetSubmissionList(&p, userID, getCurrentUserRole(c))
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// SubtaskUpdateHandler 替换题目的全部子任务
+func SubtaskUpdateHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	var p models.ParamSubtasks
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("update subtask with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	subtasks, err := service.SaveSubtasks(uint64(problemId), &p)
+	if err != nil {
+		zap.L().Error("service.SaveSubtasks() failed", zap.Error(err))
+		if errors.Is(err, service.ErrorSubtaskInvalid) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, subtasks)
+}
+
+// SubtaskListHandler 获取题目的子任务列表
+func SubtaskListHandler(c *gin.Context) {
+	problemIdStr := c.Param("id")
+	problemId, err := strconv.ParseUint(problemIdStr, 10, 64)
+	if err != nil {
+		zap.L().Error("get subtask list with invalid param", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetSubtaskList(problemId)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
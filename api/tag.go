@@ -0,0 +1,87 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// TagListHandler 标签列表
+func TagListHandler(c *gin.Context) {
+	data, err := service.GetTagList()
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// TagCreateHandler 创建标签
+func TagCreateHandler(c *gin.Context) {
+	var p models.ParamTag
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("create tag with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	tag, err := service.CreateTag(&p)
+	if err != nil {
+		zap.L().Error("service.CreateTag() failed", zap.Error(err))
+		responseTagError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, tag)
+}
+
+// TagUpdateHandler 修改标签名
+func TagUpdateHandler(c *gin.Context) {
+	tagId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	var p models.ParamTag
+	if err = c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("update tag with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	tag, err := service.UpdateTag(tagId, &p)
+	if err != nil {
+		zap.L().Error("service.UpdateTag() failed", zap.Error(err))
+		responseTagError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, tag)
+}
+
+// TagDeleteHandler 删除标签，同时移除题目上的该标签
+func TagDeleteHandler(c *gin.Context) {
+	tagId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	if err = service.DeleteTag(tagId); err != nil {
+		zap.L().Error("service.DeleteTag() failed", zap.Error(err))
+		responseTagError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+func responseTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, mysql.ErrorTagExist):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
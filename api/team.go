@@ -0,0 +1,157 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// TeamCreateHandler 创建队伍，创建者为队长
+func TeamCreateHandler(c *gin.Context) {
+	var p models.ParamTeam
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("create team with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.CreateTeam(userID, &p)
+	if err != nil {
+		zap.L().Error("service.CreateTeam() failed", zap.Error(err))
+		responseTeamError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// TeamDetailHandler 队伍详情及队员
+func TeamDetailHandler(c *gin.Context) {
+	teamId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetTeamDetail(teamId)
+	if err != nil {
+		zap.L().Error("service.GetTeamDetail() failed", zap.Error(err))
+		responseTeamError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// TeamListHandler 当前用户所在的队伍
+func TeamListHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetUserTeams(userID)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// TeamInviteHandler 队长邀请用户入队
+func TeamInviteHandler(c *gin.Context) {
+	teamId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	var p models.ParamTeamInvite
+	if err = c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("invite team member with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.InviteTeamMember(teamId, userID, &p)
+	if err != nil {
+		zap.L().Error("service.InviteTeamMember() failed", zap.Error(err))
+		responseTeamError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// InvitationListHandler 当前用户待回复的入队邀请
+func InvitationListHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetPendingInvitations(userID)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// InvitationAcceptHandler 接受入队邀请
+func InvitationAcceptHandler(c *gin.Context) {
+	replyInvitation(c, true)
+}
+
+// InvitationDeclineHandler 拒绝入队邀请
+func InvitationDeclineHandler(c *gin.Context) {
+	replyInvitation(c, false)
+}
+
+func replyInvitation(c *gin.Context, accept bool) {
+	invitationId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	if err = service.ReplyTeamInvitation(invitationId, userID, accept); err != nil {
+		zap.L().Error("service.ReplyTeamInvitation() failed", zap.Error(err))
+		responseTeamError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+// responseTeamError 队伍相关的业务错误返回具体信息，其余按服务繁忙处理
+func responseTeamError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, mysql.ErrorUserNotExit):
+		utils.ResponseError(c, utils.CodeUserNotExist)
+	case errors.Is(err, service.ErrorNotTeamCaptain):
+		utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+	case errors.Is(err, mysql.ErrorTeamExist),
+		errors.Is(err, service.ErrorTeamFull),
+		errors.Is(err, service.ErrorTeamMember),
+		errors.Is(err, service.ErrorInvitationExists),
+		errors.Is(err, service.ErrorInvitationHandled),
+		errors.Is(err, service.ErrorTeamLocked):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
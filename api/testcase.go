@@ -0,0 +1,129 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// TestCaseUploadHandler 上传zip压缩包，替换题目的全部测试数据
+func TestCaseUploadHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		zap.L().Error("upload testcase without file", zap.Error(err))
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		zap.L().Error("open uploaded file failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	defer f.Close()
+
+	cases, err := service.UploadTestCases(uint64(problemId), f, fh.Size)
+	if err != nil {
+		zap.L().Error("service.UploadTestCases() failed", zap.Error(err))
+		if errors.Is(err, service.ErrorTestCaseFormat) || errors.Is(err, service.ErrorTestCaseTooLarge) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, cases)
+}
+
+// TestCaseListHandler 获取题目的测试数据列表，仅题目作者、版主和管理员可见
+func TestCaseListHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	data, err := service.GetTestCaseList(uint64(problemId))
+	if err != nil {
+		zap.L().Error("service.GetTestCaseList() failed", zap.Error(err))
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// TestCaseUpdateHandler 新增或替换单个测试点，表单字段 input、output 分别为输入输出文件
+func TestCaseUpdateHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index <= 0 {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	input, err := c.FormFile("input")
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	output, err := c.FormFile("output")
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	in, err := input.Open()
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	defer in.Close()
+	out, err := output.Open()
+	if err != nil {
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	defer out.Close()
+
+	tc, err := service.SaveTestCase(uint64(problemId), index, in, out)
+	if err != nil {
+		zap.L().Error("service.SaveTestCase() failed", zap.Error(err))
+		if errors.Is(err, service.ErrorTestCaseTooLarge) {
+			utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, tc)
+}
+
+// TestCaseDeleteHandler 删除单个测试点
+func TestCaseDeleteHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	if err = service.DeleteTestCase(uint64(problemId), index); err != nil {
+		zap.L().Error("service.DeleteTestCase() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			utils.ResponseError(c, utils.CodeInvalidParams)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
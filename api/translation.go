@@ -0,0 +1,82 @@
+package api
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/service"
+	"LanShan/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// ProblemTranslationListHandler 题目的全部译文
+func ProblemTranslationListHandler(c *gin.Context) {
+	problemId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	data, err := service.GetProblemTranslations(problemId, getProblemViewer(c))
+	if err != nil {
+		responseTranslationError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ProblemTranslationSaveHandler 新增或修改题目某种语言的译文，需要题目作者、版主或管理员
+func ProblemTranslationSaveHandler(c *gin.Context) {
+	var p models.ParamProblemTranslation
+	if err := c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("save problem translation with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.SaveProblemTranslation(uint64(problemId), c.Param("locale"), &p, userID)
+	if err != nil {
+		zap.L().Error("service.SaveProblemTranslation() failed", zap.Error(err))
+		responseTranslationError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// ProblemTranslationDeleteHandler 删除题目某种语言的译文，需要题目作者、版主或管理员
+func ProblemTranslationDeleteHandler(c *gin.Context) {
+	problemId, ok := checkProblemPermission(c)
+	if !ok {
+		return
+	}
+	if err := service.DeleteProblemTranslation(uint64(problemId), c.Param("locale")); err != nil {
+		zap.L().Error("service.DeleteProblemTranslation() failed", zap.Error(err))
+		responseTranslationError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
+
+func responseTranslationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		utils.ResponseError(c, utils.CodeInvalidParams)
+	case errors.Is(err, service.ErrorLocaleUnsupported),
+		errors.Is(err, service.ErrorTranslationOriginal),
+		errors.Is(err, service.ErrorTranslationInvalid):
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+	case errors.Is(err, service.ErrorProblemNotStarted):
+		utils.ResponseErrorWithMsg(c, utils.CodeNoPermission, err.Error())
+	default:
+		utils.ResponseError(c, utils.CodeServerBusy)
+	}
+}
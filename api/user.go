@@ -12,6 +12,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -103,10 +104,35 @@ func RefreshTokenHandler(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	aToken, rToken, err := jwt.RefreshToken(parts[1], rt)
+	aToken, rToken, err := jwt.RefreshToken(parts[1], rt, service.GetUserRole)
 	fmt.Println(err)
 	c.JSON(http.StatusOK, gin.H{
 		"access_token":  aToken,
 		"refresh_token": rToken,
 	})
 }
+
+// UserRoleHandler 管理员修改用户角色，鉴权时按数据库中的角色判断，修改后立即生效
+func UserRoleHandler(c *gin.Context) {
+	userId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams)
+		return
+	}
+	var p models.ParamUserRole
+	if err = c.ShouldBindJSON(&p); err != nil {
+		zap.L().Error("set user role with invalid param", zap.Error(err))
+		utils.ResponseErrorWithMsg(c, utils.CodeInvalidParams, err.Error())
+		return
+	}
+	if err = service.SetUserRole(userId, p.Role); err != nil {
+		zap.L().Error("service.SetUserRole() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorUserNotExit) {
+			utils.ResponseError(c, utils.CodeUserNotExist)
+			return
+		}
+		utils.ResponseError(c, utils.CodeServerBusy)
+		return
+	}
+	utils.ResponseSuccess(c, nil)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"LanShan/service"
+	"LanShan/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VirtualStartHandler 开始虚拟参赛
+func VirtualStartHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.StartVirtualParticipation(contestId, userID)
+	if err != nil {
+		zap.L().Error("service.StartVirtualParticipation() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
+
+// VirtualScoreboardHandler 虚拟参赛排行榜
+func VirtualScoreboardHandler(c *gin.Context) {
+	contestId, ok := getContestID(c)
+	if !ok {
+		return
+	}
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		utils.ResponseError(c, utils.CodeNotLogin)
+		return
+	}
+	data, err := service.GetVirtualScoreboard(contestId, userID)
+	if err != nil {
+		zap.L().Error("service.GetVirtualScoreboard() failed", zap.Error(err))
+		responseContestError(c, err)
+		return
+	}
+	utils.ResponseSuccess(c, data)
+}
@@ -35,9 +35,9 @@ func GetAnswerList(page, size int64, problemID int64) (answers []*models.Answer,
 
 func GetAnswerById(answerID int64) (answer *models.Answer, err error) {
 	answer = new(models.Answer)
-	sqlStr := `select answer_id, content, post_id, author_id, parent_id, create_time
+	sqlStr := `select answer_id, content, problem_id, author_id, parent_id, create_time
 	from answer
-	where comment_id = ?`
+	where answer_id = ?`
 	err = db.Get(answer, sqlStr, answerID)
 	if err == sql.ErrNoRows {
 		err = ErrorInvalidID
@@ -55,7 +55,9 @@ func UpdateAnswer(answer *models.Answer) (data *models.Answer, err error) {
 	sqlStr := `update answer set content = ? where answer_id = ?`
 	_, err = db.Exec(sqlStr, answer.Content, answer.AnswerID)
 	if err != nil {
+		zap.L().Error("update answer failed", zap.Error(err))
 		err = ErrorUpdateFailer
+		return
 	}
 	data, err = GetAnswerById(int64(answer.AnswerID))
 	return
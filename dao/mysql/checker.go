@@ -0,0 +1,39 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"go.uber.org/zap"
+)
+
+// GetChecker 查询题目的checker，未设置时返回 nil
+func GetChecker(problemID uint64) (checker *models.Checker, err error) {
+	checker = new(models.Checker)
+	sqlStr := `select problem_id, type, epsilon, language, source, update_time
+	from checker
+	where problem_id = ?`
+	err = db.Get(checker, sqlStr, problemID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("query checker failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// SaveChecker 新增或覆盖题目的checker
+func SaveChecker(checker *models.Checker) (err error) {
+	sqlStr := `insert into checker(problem_id, type, epsilon, language, source)
+	values(?,?,?,?,?)
+	on duplicate key update
+	type = values(type), epsilon = values(epsilon),
+	language = values(language), source = values(source)`
+	_, err = db.Exec(sqlStr, checker.ProblemID, checker.Type, checker.Epsilon, checker.Language, checker.Source)
+	if err != nil {
+		zap.L().Error("save checker failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"go.uber.org/zap"
+	"time"
+)
+
+const clarificationColumns = `c.clarification_id, c.contest_id, c.user_id, u.username, c.problem_label, c.question,
+	c.answer, c.public, c.answerer_id, c.answer_time, c.create_time`
+
+// clarificationNow 当前时间，精确到微秒。提问、回复和查看时间都取自评测服务的时钟，
+// 与数据库的时钟无关，同一秒内的先后也能正确比较
+func clarificationNow() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func CreateClarification(clar *models.Clarification) (err error) {
+	sqlStr := `insert into clarification(clarification_id, contest_id, user_id, problem_label, question, answer, create_time)
+	values(?,?,?,?,?,'',?)`
+	_, err = db.Exec(sqlStr, clar.ClarificationID, clar.ContestID, clar.UserID, clar.ProblemLabel, clar.Question,
+		clarificationNow())
+	if err != nil {
+		zap.L().Error("insert clarification failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+func GetClarificationByID(clarificationID uint64) (clar *models.Clarification, err error) {
+	clar = new(models.Clarification)
+	sqlStr := `select ` + clarificationColumns + `
+	from clarification c
+	join user u on u.user_id = c.user_id
+	where c.clarification_id = ?`
+	err = db.Get(clar, sqlStr, clarificationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query clarification failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// AnswerClarification 回复答疑，重复回复会覆盖之前的内容
+func AnswerClarification(clarificationID, answererID uint64, answer string, public bool) (err error) {
+	sqlStr := `update clarification
+	set answer = ?, public = ?, answerer_id = ?, answer_time = ?
+	where clarification_id = ?`
+	if _, err = db.Exec(sqlStr, answer, public, answererID, clarificationNow(), clarificationID); err != nil {
+		zap.L().Error("answer clarification failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// GetClarificationList 查询比赛答疑，按提问时间倒序。userID 为0时查询全部，
+// 否则只查询该用户的提问及公开的答疑
+func GetClarificationList(contestID, userID uint64) (clars []*models.Clarification, err error) {
+	sqlStr := `select ` + clarificationColumns + `
+	from clarification c
+	join user u on u.user_id = c.user_id
+	where c.contest_id = ?`
+	args := []interface{}{contestID}
+	if userID != 0 {
+		sqlStr += " and (c.user_id = ? or c.public = 1)"
+		args = append(args, userID)
+	}
+	sqlStr += " ORDER BY c.create_time DESC, c.id DESC"
+	clars = make([]*models.Clarification, 0, 16)
+	if err = db.Select(&clars, sqlStr, args...); err != nil {
+		zap.L().Error("query clarification list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetClarificationReadTime 查询用户上次查看答疑的时间，从未查看过时返回零值
+func GetClarificationReadTime(contestID, userID uint64) (readTime time.Time, err error) {
+	sqlStr := "select read_time from clarification_read where contest_id = ? and user_id = ?"
+	err = db.Get(&readTime, sqlStr, contestID, userID)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		zap.L().Error("query clarification read time failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// SetClarificationReadTime 记录用户查看答疑的时间
+func SetClarificationReadTime(contestID, userID uint64, readTime time.Time) (err error) {
+	sqlStr := `insert into clarification_read(contest_id, user_id, read_time) values(?,?,?)
+	on duplicate key update read_time = values(read_time)`
+	if _, err = db.Exec(sqlStr, contestID, userID, readTime.Truncate(time.Microsecond)); err != nil {
+		zap.L().Error("update clarification read time failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// CountNewQuestions 统计某时间之后的新提问数量
+func CountNewQuestions(contestID uint64, since time.Time) (count int64, err error) {
+	sqlStr := "select count(*) from clarification where contest_id = ? and create_time > ?"
+	if err = db.Get(&count, sqlStr, contestID, since); err != nil {
+		zap.L().Error("count clarification questions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// CountNewAnswers 统计某时间之后用户可见的新回复数量
+func CountNewAnswers(contestID, userID uint64, since time.Time) (count int64, err error) {
+	sqlStr := `select count(*) from clarification
+	where contest_id = ? and (user_id = ? or public = 1) and answer_time > ?`
+	if err = db.Get(&count, sqlStr, contestID, userID, since); err != nil {
+		zap.L().Error("count clarification answers failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
@@ -0,0 +1,291 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+// CreateContest 保存比赛及比赛题目
+func CreateContest(contest *models.Contest, problems []*models.ContestProblem) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `insert into contest(
+	contest_id, author_id, title, description, rule, visibility, password, start_time, duration, freeze, rated)
+	values(?,?,?,?,?,?,?,?,?,?,?)`
+	_, err = tx.Exec(sqlStr, contest.ContestID, contest.AuthorID, contest.Title, contest.Description,
+		contest.Rule, contest.Visibility, contest.Password, contest.StartTime, contest.Duration, contest.Freeze,
+		contest.Rated)
+	if err != nil {
+		zap.L().Error("insert contest failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	sqlStr = "insert into contest_problem(contest_id, problem_id, label) values(?,?,?)"
+	for _, p := range problems {
+		if _, err = tx.Exec(sqlStr, contest.ContestID, p.ProblemID, p.Label); err != nil {
+			zap.L().Error("insert contest problem failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit contest failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	return
+}
+
+func GetContestByID(contestID uint64) (contest *models.Contest, err error) {
+	contest = new(models.Contest)
+	sqlStr := `select contest_id, author_id, title, description, rule, visibility, password, start_time, duration, freeze, unfrozen, rated, create_time
+	from contest
+	where contest_id = ?`
+	err = db.Get(contest, sqlStr, contestID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query contest failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetUnfinishedContestsOfProblem 包含该题目且尚未结束(含尚未开始)的比赛
+func GetUnfinishedContestsOfProblem(problemID uint64, now time.Time) (contests []*models.Contest, err error) {
+	sqlStr := `select c.contest_id, c.author_id, c.title, c.description, c.rule, c.visibility, c.password,
+	c.start_time, c.duration, c.freeze, c.unfrozen, c.rated, c.create_time
+	from contest c
+	join contest_problem cp on cp.contest_id = c.contest_id
+	where cp.problem_id = ? and date_add(c.start_time, interval c.duration minute) > ?`
+	contests = make([]*models.Contest, 0, 1)
+	if err = db.Select(&contests, sqlStr, problemID, now); err != nil {
+		zap.L().Error("query unfinished contests of problem failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// UnfreezeContest 解除封榜
+func UnfreezeContest(contestID uint64) (err error) {
+	sqlStr := "update contest set unfrozen = 1 where contest_id = ?"
+	if _, err = db.Exec(sqlStr, contestID); err != nil {
+		zap.L().Error("unfreeze contest failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// GetContestList 分页查询比赛，按开始时间倒序
+func GetContestList(page, size int64) (contests []*models.Contest, err error) {
+	sqlStr := `select contest_id, author_id, title, description, rule, visibility, start_time, duration, freeze, unfrozen, rated, create_time
+	from contest
+	ORDER BY start_time
+	DESC
+	limit ?,?`
+	contests = make([]*models.Contest, 0, size)
+	if err = db.Select(&contests, sqlStr, (page-1)*size, size); err != nil {
+		zap.L().Error("query contest list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetContestProblems 查询比赛题目，按题号排序
+func GetContestProblems(contestID uint64) (problems []*models.ContestProblem, err error) {
+	sqlStr := `select cp.contest_id, cp.problem_id, cp.label, p.title
+	from contest_problem cp
+	join problem p on p.problem_id = cp.problem_id
+	where cp.contest_id = ?
+	ORDER BY cp.label`
+	problems = make([]*models.ContestProblem, 0, 8)
+	if err = db.Select(&problems, sqlStr, contestID); err != nil {
+		zap.L().Error("query contest problems failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// RegisterContest 报名比赛，重复报名不报错
+func RegisterContest(contestID, userID uint64) (err error) {
+	sqlStr := "insert ignore into contest_participant(contest_id, user_id) values(?,?)"
+	if _, err = db.Exec(sqlStr, contestID, userID); err != nil {
+		zap.L().Error("insert contest participant failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+// IsContestParticipant 用户是否报名了比赛
+func IsContestParticipant(contestID, userID uint64) (ok bool, err error) {
+	var count int
+	sqlStr := "select count(*) from contest_participant where contest_id = ? and user_id = ?"
+	if err = db.Get(&count, sqlStr, contestID, userID); err != nil {
+		zap.L().Error("query contest participant failed", zap.Error(err))
+		return false, ErrorQueryFailed
+	}
+	return count > 0, nil
+}
+
+// GetContestParticipants 查询比赛的全部报名者
+func GetContestParticipants(contestID uint64) (participants []*models.ContestParticipant, err error) {
+	sqlStr := `select cp.contest_id, cp.user_id, u.username, cp.create_time
+	from contest_participant cp
+	join user u on u.user_id = cp.user_id
+	where cp.contest_id = ?
+	ORDER BY cp.id`
+	participants = make([]*models.ContestParticipant, 0, 16)
+	if err = db.Select(&participants, sqlStr, contestID); err != nil {
+		zap.L().Error("query contest participants failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetContestSubmissions 查询比赛中的全部提交，按提交时间排序，不含源代码
+func GetContestSubmissions(contestID uint64) (submissions []*models.Submission, err error) {
+	sqlStr := `select submission_id, problem_id, user_id, contest_id, language, status, time_ms, memory_kb, score, create_time
+	from submission
+	where contest_id = ?
+	ORDER BY create_time, submission_id`
+	submissions = make([]*models.Submission, 0, 64)
+	if err = db.Select(&submissions, sqlStr, contestID); err != nil {
+		zap.L().Error("query contest submissions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetContestSubmissionSubtasks 查询比赛中全部提交的子任务得分
+func GetContestSubmissionSubtasks(contestID uint64) (subtasks []*models.SubmissionSubtask, err error) {
+	sqlStr := `select ss.submission_id, ss.subtask_index, ss.status, ss.score
+	from submission_subtask ss
+	join submission s on s.submission_id = ss.submission_id
+	where s.contest_id = ?`
+	subtasks = make([]*models.SubmissionSubtask, 0, 64)
+	if err = db.Select(&subtasks, sqlStr, contestID); err != nil {
+		zap.L().Error("query contest submission subtasks failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// CreateVirtualParticipation 保存虚拟参赛记录，每场比赛每人只能虚拟参赛一次
+func CreateVirtualParticipation(v *models.VirtualParticipation) (err error) {
+	sqlStr := "insert into contest_virtual(contest_id, user_id, start_time, end_time) values(?,?,?,?)"
+	if _, err = db.Exec(sqlStr, v.ContestID, v.UserID, v.StartTime, v.EndTime); err != nil {
+		zap.L().Error("insert contest virtual participation failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+// GetVirtualParticipation 查询虚拟参赛记录，没有时返回 nil
+func GetVirtualParticipation(contestID, userID uint64) (v *models.VirtualParticipation, err error) {
+	v = new(models.VirtualParticipation)
+	sqlStr := `select contest_id, user_id, start_time, end_time
+	from contest_virtual
+	where contest_id = ? and user_id = ?`
+	err = db.Get(v, sqlStr, contestID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("query contest virtual participation failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// CountUserContestSubmissions 统计用户在比赛结束前的提交数量
+func CountUserContestSubmissions(contestID, userID uint64, end time.Time) (count int64, err error) {
+	sqlStr := "select count(*) from submission where contest_id = ? and user_id = ? and create_time < ?"
+	if err = db.Get(&count, sqlStr, contestID, userID, end); err != nil {
+		zap.L().Error("count user contest submissions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+func RegisterContestTeam(contestID, teamID uint64) (err error) {
+	sqlStr := "insert ignore into contest_team(contest_id, team_id) values(?,?)"
+	if _, err = db.Exec(sqlStr, contestID, teamID); err != nil {
+		zap.L().Error("insert contest team failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+// GetContestTeams 查询以队伍报名的参赛者，不含队员
+func GetContestTeams(contestID uint64) (teams []*models.ContestTeam, err error) {
+	sqlStr := `select ct.contest_id, ct.team_id, t.name
+	from contest_team ct
+	join team t on t.team_id = ct.team_id
+	where ct.contest_id = ?
+	ORDER BY ct.id`
+	teams = make([]*models.ContestTeam, 0, 16)
+	if err = db.Select(&teams, sqlStr, contestID); err != nil {
+		zap.L().Error("query contest teams failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetContestTeamOfUser 查询用户在比赛中所属的队伍，不属于任何报名队伍时返回0
+func GetContestTeamOfUser(contestID, userID uint64) (teamID uint64, err error) {
+	sqlStr := `select ct.team_id
+	from contest_team ct
+	join team_member tm on tm.team_id = ct.team_id
+	where ct.contest_id = ? and tm.user_id = ?
+	limit 1`
+	err = db.Get(&teamID, sqlStr, contestID, userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		zap.L().Error("query contest team of user failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// unstartedProblemCond 题目属于对查看者隐藏的未开始比赛，%[1]s 为 problem 表的别名，
+// 参数依次为当前时间、查看者id
+const unstartedProblemCond = `exists (
+	select 1 from contest_problem cp
+	join contest c on c.contest_id = cp.contest_id
+	where cp.problem_id = %[1]s.problem_id and c.start_time > ?
+	and c.author_id != ? and %[1]s.author_id != ?)`
+
+// visibleProblemFilter 返回过滤掉未开始比赛中题目的查询条件及参数，可以看到全部题目时返回空
+func visibleProblemFilter(alias string, viewer *models.ProblemViewer) (cond string, args []interface{}) {
+	if viewer.SeesAll() {
+		return "", nil
+	}
+	return " and not " + fmt.Sprintf(unstartedProblemCond, alias),
+		[]interface{}{time.Now(), viewer.UserID, viewer.UserID}
+}
+
+// IsProblemHidden 题目是否属于查看者不能看到的未开始比赛
+func IsProblemHidden(problemID uint64, viewer *models.ProblemViewer) (hidden bool, err error) {
+	cond, args := visibleProblemFilter("p", viewer)
+	if cond == "" {
+		return false, nil
+	}
+	sqlStr := "select count(*) from problem p where p.problem_id = ?" + cond
+	var n int64
+	if err = db.Get(&n, sqlStr, append([]interface{}{problemID}, args...)...); err != nil {
+		zap.L().Error("query problem visibility failed", zap.Error(err))
+		return false, ErrorQueryFailed
+	}
+	return n == 0, nil
+}
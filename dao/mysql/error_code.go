@@ -11,4 +11,6 @@ var (
 	ErrorQueryFailed   = errors.New("查询数据失败")
 	ErrorInsertFailed  = errors.New("插入数据失败")
 	ErrorUpdateFailer  = errors.New("更新数据失败")
+	ErrorTeamExist     = errors.New("队名已存在")
+	ErrorTagExist      = errors.New("标签已存在")
 )
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"go.uber.org/zap"
+)
+
+// GetInteractor 查询题目的交互器，非交互题返回 nil
+func GetInteractor(problemID uint64) (interactor *models.Interactor, err error) {
+	interactor = new(models.Interactor)
+	sqlStr := `select problem_id, language, source, update_time
+	from interactor
+	where problem_id = ?`
+	err = db.Get(interactor, sqlStr, problemID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("query interactor failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// SaveInteractor 新增或覆盖题目的交互器
+func SaveInteractor(interactor *models.Interactor) (err error) {
+	sqlStr := `insert into interactor(problem_id, language, source)
+	values(?,?,?)
+	on duplicate key update
+	language = values(language), source = values(source)`
+	_, err = db.Exec(sqlStr, interactor.ProblemID, interactor.Language, interactor.Source)
+	if err != nil {
+		zap.L().Error("save interactor failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// DeleteInteractor 删除交互器，题目恢复为普通题目
+func DeleteInteractor(problemID uint64) (err error) {
+	result, err := db.Exec("delete from interactor where problem_id = ?", problemID)
+	if err != nil {
+		zap.L().Error("delete interactor failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return ErrorUpdateFailer
+	}
+	if n == 0 {
+		return ErrorInvalidID
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"go.uber.org/zap"
+)
+
+func CreatePlagiarismReport(report *models.PlagiarismReport) (err error) {
+	sqlStr := `insert into plagiarism_report(report_id, user_id, contest_id, problem_id, status)
+	values(?,?,?,?,?)`
+	_, err = db.Exec(sqlStr, report.ReportID, report.UserID, report.ContestID, report.ProblemID, report.Status)
+	if err != nil {
+		zap.L().Error("insert plagiarism report failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+// FinishPlagiarismReport 保存查重结果并更新任务状态
+func FinishPlagiarismReport(report *models.PlagiarismReport, pairs []*models.PlagiarismPair) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `insert into plagiarism_pair(
+	pair_id, report_id, problem_id, language, submission_a, user_a, submission_b, user_b, similarity, matches)
+	values(?,?,?,?,?,?,?,?,?,?)`
+	for _, p := range pairs {
+		_, err = tx.Exec(sqlStr, p.PairID, p.ReportID, p.ProblemID, p.Language,
+			p.SubmissionA, p.UserA, p.SubmissionB, p.UserB, p.Similarity, p.Matches)
+		if err != nil {
+			zap.L().Error("insert plagiarism pair failed", zap.Error(err))
+			return ErrorUpdateFailer
+		}
+	}
+	sqlStr = "update plagiarism_report set status = ?, compared = ?, message = ? where report_id = ?"
+	if _, err = tx.Exec(sqlStr, report.Status, report.Compared, report.Message, report.ReportID); err != nil {
+		zap.L().Error("update plagiarism report failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit plagiarism report failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+func GetPlagiarismReport(reportID uint64) (report *models.PlagiarismReport, err error) {
+	report = new(models.PlagiarismReport)
+	sqlStr := `select report_id, user_id, contest_id, problem_id, status, compared, message, create_time
+	from plagiarism_report
+	where report_id = ?`
+	err = db.Get(report, sqlStr, reportID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query plagiarism report failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetPlagiarismPairs 查询查重报告中的提交对，按相似度降序，不含相同片段
+func GetPlagiarismPairs(reportID uint64) (pairs []*models.PlagiarismPair, err error) {
+	sqlStr := `select pair_id, report_id, problem_id, language, submission_a, user_a, submission_b, user_b, similarity
+	from plagiarism_pair
+	where report_id = ?
+	ORDER BY similarity DESC, id`
+	pairs = make([]*models.PlagiarismPair, 0, 32)
+	if err = db.Select(&pairs, sqlStr, reportID); err != nil {
+		zap.L().Error("query plagiarism pairs failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+func GetPlagiarismPair(pairID uint64) (pair *models.PlagiarismPair, err error) {
+	pair = new(models.PlagiarismPair)
+	sqlStr := `select pair_id, report_id, problem_id, language, submission_a, user_a, submission_b, user_b,
+	similarity, matches
+	from plagiarism_pair
+	where pair_id = ?`
+	err = db.Get(pair, sqlStr, pairID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query plagiarism pair failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetPlagiarismSubmissions 查询参与查重的提交(含源代码)，按提交时间排序，不含编译错误的提交
+func GetPlagiarismSubmissions(contestID, problemID uint64) (submissions []*models.Submission, err error) {
+	sqlStr := `select submission_id, problem_id, user_id, contest_id, language, status, source, create_time
+	from submission
+	where status != ?`
+	args := []interface{}{models.StatusCompileError}
+	if contestID != 0 {
+		sqlStr += " and contest_id = ?"
+		args = append(args, contestID)
+	}
+	if problemID != 0 {
+		sqlStr += " and problem_id = ?"
+		args = append(args, problemID)
+	}
+	sqlStr += " ORDER BY create_time, submission_id"
+	submissions = make([]*models.Submission, 0, 64)
+	if err = db.Select(&submissions, sqlStr, args...); err != nil {
+		zap.L().Error("query plagiarism submissions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
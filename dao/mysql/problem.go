@@ -8,24 +8,40 @@ import (
 	"strings"
 )
 
-// CreateProblem 发布题目
+// CreateProblem 发布题目，同时记录第一个版本
 func CreateProblem(problem *models.Problem) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
 	sqlStr := `insert into problem(
-	problem_id, title, content, author_id, community_id)
-	values(?,?,?,?,?)`
-	_, err = db.Exec(sqlStr, problem.ProblemID, problem.Title,
-		problem.Content, problem.AuthorId, problem.CommunityID)
+	problem_id, title, content, statement, locale, author_id, community_id, time_limit, memory_limit, difficulty)
+	values(?,?,?,?,?,?,?,?,?,?)`
+	_, err = tx.Exec(sqlStr, problem.ProblemID, problem.Title, problem.Content, problem.Statement, problem.Locale, problem.AuthorId,
+		problem.CommunityID, problem.TimeLimit, problem.MemoryLimit, problem.Difficulty)
 	if err != nil {
 		zap.L().Error("insert problem failed", zap.Error(err))
-		err = ErrorInsertFailed
+		return ErrorInsertFailed
+	}
+	if err = insertProblemRevision(tx, problem, problem.AuthorId, "创建题目"); err != nil {
 		return
 	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit problem failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
 	return
 }
 
 func GetProblemByID(pid int64) (problem *models.Problem, err error) {
 	problem = new(models.Problem)
-	sqlStr := `select problem_id, title, content, author_id, community_id, create_time
+	sqlStr := `select problem_id, title, content, statement, locale, author_id, community_id, time_limit, memory_limit, difficulty, create_time
 	from problem
 	where problem_id = ?`
 	err = db.Get(problem, sqlStr, pid)
@@ -42,7 +58,7 @@ func GetProblemByID(pid int64) (problem *models.Problem, err error) {
 }
 
 func GetProblemListByIDs(ids []string) (problemList []*models.Problem, err error) {
-	sqlStr := `select problem_id, title, content, author_id, community_id, create_time
+	sqlStr := `select problem_id, title, content, statement, locale, author_id, community_id, time_limit, memory_limit, difficulty, create_time
 	from problem
 	where problem_id in (?)
 	order by FIND_IN_SET(problem_id, ?)`
@@ -57,57 +73,200 @@ func GetProblemListByIDs(ids []string) (problemList []*models.Problem, err error
 	return
 }
 
-func GetProblemList(page, size int64) (problems []*models.Problem, err error) {
-	sqlStr := `select problem_id, title, content, author_id, community_id, create_time
-	from problem
-	ORDER BY create_time
-	DESC 
-	limit ?,?
-	`
-	problems = make([]*models.Problem, 0, 10)
-	err = db.Select(&problems, sqlStr, (page-1)*size, size)
+// GetProblemList 按版块、标签和难度筛选题目，标签须全部包含，不含对查看者隐藏的未开始比赛中的题目。
+// 只统计当前页题目的提交，按热度或通过率排序时用相关子查询逐题计算，避免聚合整张提交表
+func GetProblemList(p *models.ParamProblemList, viewer *models.ProblemViewer) (problems []*models.ProblemListItem, err error) {
+	sqlStr := `select p.problem_id, p.title, p.content, p.statement, p.locale, p.author_id, p.community_id,
+	p.time_limit, p.memory_limit, p.difficulty, p.create_time
+	from problem p
+	where 1 = 1`
+	var args []interface{}
+	cond, condArgs := visibleProblemFilter("p", viewer)
+	sqlStr += cond
+	args = append(args, condArgs...)
+	if p.CommunityID != 0 {
+		sqlStr += " and p.community_id = ?"
+		args = append(args, p.CommunityID)
+	}
+	if p.DifficultyMin != 0 {
+		sqlStr += " and p.difficulty >= ?"
+		args = append(args, p.DifficultyMin)
+	}
+	if p.DifficultyMax != 0 {
+		sqlStr += " and p.difficulty <= ?"
+		args = append(args, p.DifficultyMax)
+	}
+	if names := p.TagNames(); len(names) > 0 {
+		sqlStr += ` and p.problem_id in (
+		select pt.problem_id from problem_tag pt
+		join tag t on t.tag_id = pt.tag_id
+		where t.name in (?)
+		group by pt.problem_id
+		having count(*) = ?)`
+		args = append(args, names, len(names))
+	}
+	const (
+		submitNum   = "(select count(*) from submission s where s.problem_id = p.problem_id)"
+		acceptedNum = "(select count(*) from submission s where s.problem_id = p.problem_id and s.status = ?)"
+		solvedNum   = "(select count(distinct s.user_id) from submission s where s.problem_id = p.problem_id and s.status = ?)"
+	)
+	switch p.Order {
+	case models.OrderScore:
+		sqlStr += " ORDER BY " + solvedNum + " DESC, p.create_time DESC"
+		args = append(args, models.StatusAccepted)
+	case models.OrderAcceptance:
+		// 没有提交的题目通过率为NULL，降序时排在最后
+		sqlStr += " ORDER BY " + acceptedNum + " / nullif(" + submitNum + ", 0) DESC, " + submitNum + " DESC"
+		args = append(args, models.StatusAccepted)
+	default:
+		sqlStr += " ORDER BY p.create_time DESC"
+	}
+	sqlStr += " limit ?,?"
+	args = append(args, (p.Page-1)*p.Size, p.Size)
+	query, args, err := sqlx.In(sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	problems = make([]*models.ProblemListItem, 0, p.Size)
+	if err = db.Select(&problems, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query problem list failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	if len(problems) == 0 {
+		return
+	}
+	ids := make([]uint64, 0, len(problems))
+	for _, item := range problems {
+		ids = append(ids, item.ProblemID)
+	}
+	stats, err := GetProblemStats(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range problems {
+		if stat, ok := stats[item.ProblemID]; ok {
+			item.ProblemStat = *stat
+		}
+	}
 	return
 }
 
-func UpdateProblem(newProblem, pastProblem *models.Problem) (problem *models.Problem, err error) {
-	if newProblem.Title != pastProblem.Title {
-		sqlStr := "update problem set title = ? where problem_id = ?"
-		_, err = db.Exec(sqlStr, newProblem.Title, newProblem.ProblemID)
-		if err != nil {
-			err = ErrorUpdateFailer
-		}
+// GetProblemStats 批量查询题目的提交统计，没有提交的题目不在结果中
+func GetProblemStats(ids []uint64) (stats map[uint64]*models.ProblemStat, err error) {
+	type problemStat struct {
+		ProblemID uint64 `db:"problem_id"`
+		models.ProblemStat
 	}
-	if newProblem.Content != pastProblem.Content {
-		sqlStr := "update problem set content = ? where problem_id = ?"
-		_, err = db.Exec(sqlStr, newProblem.Content, newProblem.ProblemID)
-		if err != nil {
-			err = ErrorUpdateFailer
-		}
+	sqlStr := `select problem_id, count(*) submit_num, sum(status = ?) accepted_num,
+	count(distinct case when status = ? then user_id end) solved_num
+	from submission
+	where problem_id in (?)
+	group by problem_id`
+	query, args, err := sqlx.In(sqlStr, models.StatusAccepted, models.StatusAccepted, ids)
+	if err != nil {
+		return nil, err
 	}
-	if newProblem.Input != pastProblem.Input {
-		sqlStr := "update problem set input = ? where problem_id = ?"
-		_, err = db.Exec(sqlStr, newProblem.Title, newProblem.ProblemID)
+	var rows []*problemStat
+	if err = db.Select(&rows, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query problem stats failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	stats = make(map[uint64]*models.ProblemStat, len(rows))
+	for _, r := range rows {
+		stats[r.ProblemID] = &r.ProblemStat
+	}
+	return
+}
+
+// GetProblemStat 查询单个题目的提交统计
+func GetProblemStat(problemID uint64) (stat *models.ProblemStat, err error) {
+	stat = new(models.ProblemStat)
+	sqlStr := `select count(*) submit_num, coalesce(sum(status = ?), 0) accepted_num,
+	count(distinct case when status = ? then user_id end) solved_num
+	from submission
+	where problem_id = ?`
+	err = db.Get(stat, sqlStr, models.StatusAccepted, models.StatusAccepted, problemID)
+	if err != nil {
+		zap.L().Error("query problem stat failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// UpdateProblem 用修改后的题目覆盖题面和限制，并记录一个新版本。
+// 题目还没有任何版本时(早于版本记录功能创建)，先把修改前的内容保存为第一个版本
+func UpdateProblem(problem, pastProblem *models.Problem, editorID uint64, note string) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
 		if err != nil {
-			err = ErrorUpdateFailer
+			_ = tx.Rollback()
 		}
+	}()
+	var count int
+	sqlStr := "select count(*) from problem_revision where problem_id = ?"
+	if err = tx.Get(&count, sqlStr, pastProblem.ProblemID); err != nil {
+		zap.L().Error("count problem revision failed", zap.Error(err))
+		return ErrorQueryFailed
 	}
-	if newProblem.Output != pastProblem.Output {
-		sqlStr := "update problem set output = ? where problem_id = ?"
-		_, err = db.Exec(sqlStr, newProblem.Title, newProblem.ProblemID)
-		if err != nil {
-			err = ErrorUpdateFailer
+	if count == 0 {
+		if err = insertProblemRevision(tx, pastProblem, pastProblem.AuthorId, "初始版本"); err != nil {
+			return
 		}
 	}
+	sqlStr = `update problem
+	set title = ?, content = ?, statement = ?, time_limit = ?, memory_limit = ?, difficulty = ?
+	where problem_id = ?`
+	_, err = tx.Exec(sqlStr, problem.Title, problem.Content, problem.Statement, problem.TimeLimit,
+		problem.MemoryLimit, problem.Difficulty, pastProblem.ProblemID)
+	if err != nil {
+		zap.L().Error("update problem failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if err = insertProblemRevision(tx, problem, editorID, note); err != nil {
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit problem failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
 	return
 }
 
+// DeleteProblem 删除题目及其历史版本和译文
 func DeleteProblem(problemID int64) (err error) {
-	sqlStr := "delete from problem WHERE problem_id = ?"
-	_, err = db.Exec(sqlStr, problemID)
+	tx, err := db.Beginx()
 	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec("delete from problem WHERE problem_id = ?", problemID); err != nil {
 		zap.L().Error("delete problem failed", zap.Error(err))
-		err = ErrorInsertFailed
-		return
+		return ErrorInsertFailed
+	}
+	if _, err = tx.Exec("delete from problem_revision WHERE problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete problem revision failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	if _, err = tx.Exec("delete from problem_translation WHERE problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete problem translation failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	if _, err = tx.Exec("delete from problem_tag WHERE problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete problem tag failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit delete problem failed", zap.Error(err))
+		return ErrorInsertFailed
 	}
 	return
 }
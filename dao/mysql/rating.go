@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// GetUserRatings 查询多个用户的当前等级分
+func GetUserRatings(userIDs []uint64) (ratings map[uint64]int, err error) {
+	ratings = make(map[uint64]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return
+	}
+	query, args, err := sqlx.In("select user_id, rating from user where user_id in (?)", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]struct {
+		UserID uint64 `db:"user_id"`
+		Rating int    `db:"rating"`
+	}, 0, len(userIDs))
+	if err = db.Select(&rows, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query user ratings failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	for _, r := range rows {
+		ratings[r.UserID] = r.Rating
+	}
+	return
+}
+
+func GetUserRating(userID uint64) (rating int, err error) {
+	err = db.Get(&rating, "select rating from user where user_id = ?", userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrorUserNotExit
+	}
+	if err != nil {
+		zap.L().Error("query user rating failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// CountRatingChanges 统计比赛已保存的等级分变化数量，大于0说明已经计算过
+func CountRatingChanges(contestID uint64) (count int64, err error) {
+	if err = db.Get(&count, "select count(*) from rating_change where contest_id = ?", contestID); err != nil {
+		zap.L().Error("count rating changes failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// SaveRatingChanges 保存一场比赛的等级分变化并更新用户当前等级分
+func SaveRatingChanges(contestID uint64, changes []*models.RatingChange) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	insertStr := `insert into rating_change(contest_id, user_id, contest_rank, old_rating, new_rating)
+	values(?,?,?,?,?)`
+	updateStr := "update user set rating = ? where user_id = ?"
+	for _, c := range changes {
+		if _, err = tx.Exec(insertStr, contestID, c.UserID, c.Rank, c.OldRating, c.NewRating); err != nil {
+			zap.L().Error("insert rating change failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+		if _, err = tx.Exec(updateStr, c.NewRating, c.UserID); err != nil {
+			zap.L().Error("update user rating failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit rating changes failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	return
+}
+
+// GetUserRatingHistory 查询用户的等级分变化历史，按时间先后排序
+func GetUserRatingHistory(userID uint64) (history []*models.RatingChange, err error) {
+	sqlStr := `select rc.contest_id, c.title as contest_title, rc.user_id, rc.contest_rank, rc.old_rating, rc.new_rating, rc.create_time
+	from rating_change rc
+	join contest c on c.contest_id = rc.contest_id
+	where rc.user_id = ?
+	ORDER BY rc.id`
+	history = make([]*models.RatingChange, 0, 16)
+	if err = db.Select(&history, sqlStr, userID); err != nil {
+		zap.L().Error("query rating history failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
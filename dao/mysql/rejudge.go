@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// rejudgeBatchSize 每条语句处理的提交数量，避免 in 列表过长
+const rejudgeBatchSize = 500
+
+// GetRejudgeSubmissionIDs 查询符合条件的提交id
+func GetRejudgeSubmissionIDs(p *models.ParamRejudge, submissionIDs []uint64) (ids []uint64, err error) {
+	conditions := make([]string, 0, 5)
+	args := make([]interface{}, 0, 5)
+	if p.ProblemID != 0 {
+		conditions = append(conditions, "problem_id = ?")
+		args = append(args, p.ProblemID)
+	}
+	if p.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, p.UserID)
+	}
+	if len(submissionIDs) > 0 {
+		conditions = append(conditions, "submission_id in (?)")
+		args = append(args, submissionIDs)
+	}
+	if !p.StartTime.IsZero() {
+		conditions = append(conditions, "create_time >= ?")
+		args = append(args, p.StartTime)
+	}
+	if !p.EndTime.IsZero() {
+		conditions = append(conditions, "create_time < ?")
+		args = append(args, p.EndTime)
+	}
+	sqlStr := "select submission_id from submission where " + strings.Join(conditions, " and ") +
+		" ORDER BY submission_id"
+	sqlStr, args, err = sqlx.In(sqlStr, args...)
+	if err != nil {
+		zap.L().Error("build rejudge query failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	ids = make([]uint64, 0, 16)
+	if err = db.Select(&ids, db.Rebind(sqlStr), args...); err != nil {
+		zap.L().Error("query rejudge submissions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// CreateRejudge 保存重新评测记录，把提交原有的结果存入历史表并重置为等待评测
+func CreateRejudge(rejudge *models.Rejudge, ids []uint64) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec("insert into rejudge(rejudge_id, user_id, total) values(?,?,?)",
+		rejudge.RejudgeID, rejudge.UserID, rejudge.Total)
+	if err != nil {
+		zap.L().Error("insert rejudge failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	statements := []string{
+		`insert into submission_history(submission_id, rejudge_id, status, score, time_ms, memory_kb, message)
+		select submission_id, ?, status, score, time_ms, memory_kb, message
+		from submission
+		where submission_id in (?)`,
+		`update submission
+		set status = ?, score = 0, time_ms = 0, memory_kb = 0, message = ''
+		where submission_id in (?)`,
+		"delete from submission_case where submission_id in (?)",
+		"delete from submission_subtask where submission_id in (?)",
+	}
+	for start := 0; start < len(ids); start += rejudgeBatchSize {
+		end := start + rejudgeBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+		for i, statement := range statements {
+			var args []interface{}
+			switch i {
+			case 0:
+				args = []interface{}{rejudge.RejudgeID, batch}
+			case 1:
+				args = []interface{}{models.StatusPending, batch}
+			default:
+				args = []interface{}{batch}
+			}
+			query, args, err := sqlx.In(statement, args...)
+			if err != nil {
+				zap.L().Error("build rejudge statement failed", zap.Error(err))
+				return ErrorUpdateFailer
+			}
+			if _, err = tx.Exec(tx.Rebind(query), args...); err != nil {
+				zap.L().Error("reset submission for rejudge failed", zap.Error(err))
+				return ErrorUpdateFailer
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit rejudge failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+// GetRejudgeByID 查询重新评测记录及进度
+func GetRejudgeByID(rejudgeID uint64) (rejudge *models.Rejudge, err error) {
+	rejudge = new(models.Rejudge)
+	sqlStr := `select rejudge_id, user_id, total, create_time
+	from rejudge
+	where rejudge_id = ?`
+	err = db.Get(rejudge, sqlStr, rejudgeID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query rejudge failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	// 仍处于等待或评测中的提交即未完成
+	sqlStr = `select count(*)
+	from submission_history h
+	join submission s on s.submission_id = h.submission_id
+	where h.rejudge_id = ? and s.status in (?, ?)`
+	var unfinished int64
+	if err = db.Get(&unfinished, sqlStr, rejudgeID, models.StatusPending, models.StatusJudging); err != nil {
+		zap.L().Error("query rejudge progress failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	rejudge.Finished = rejudge.Total - unfinished
+	return
+}
+
+// GetSubmissionHistory 查询提交历次重新评测前的结果
+func GetSubmissionHistory(submissionID uint64) (history []*models.SubmissionHistory, err error) {
+	sqlStr := `select submission_id, rejudge_id, status, score, time_ms, memory_kb, message, create_time
+	from submission_history
+	where submission_id = ?
+	ORDER BY id DESC`
+	history = make([]*models.SubmissionHistory, 0, 2)
+	if err = db.Select(&history, sqlStr, submissionID); err != nil {
+		zap.L().Error("query submission history failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
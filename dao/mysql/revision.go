@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// insertProblemRevision 在事务中记录题目的新版本，版本号为已有最大版本号加一
+func insertProblemRevision(tx *sqlx.Tx, problem *models.Problem, editorID uint64, note string) (err error) {
+	var version int
+	sqlStr := "select coalesce(max(version), 0) from problem_revision where problem_id = ? for update"
+	if err = tx.Get(&version, sqlStr, problem.ProblemID); err != nil {
+		zap.L().Error("query problem revision failed", zap.Error(err))
+		return ErrorQueryFailed
+	}
+	sqlStr = `insert into problem_revision(
+	problem_id, version, editor_id, title, content, statement, time_limit, memory_limit, difficulty, note)
+	values(?,?,?,?,?,?,?,?,?,?)`
+	_, err = tx.Exec(sqlStr, problem.ProblemID, version+1, editorID, problem.Title, problem.Content, problem.Statement,
+		problem.TimeLimit, problem.MemoryLimit, problem.Difficulty, note)
+	if err != nil {
+		zap.L().Error("insert problem revision failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	return
+}
+
+// GetProblemRevisions 题目的版本列表，按版本号降序，不含题面内容
+func GetProblemRevisions(problemID uint64) (revisions []*models.ProblemRevision, err error) {
+	sqlStr := `select r.problem_id, r.version, r.editor_id, coalesce(u.username, '') username,
+	r.title, r.time_limit, r.memory_limit, r.difficulty, r.note, r.create_time
+	from problem_revision r
+	left join user u on u.user_id = r.editor_id
+	where r.problem_id = ?
+	ORDER BY r.version DESC`
+	revisions = make([]*models.ProblemRevision, 0, 8)
+	if err = db.Select(&revisions, sqlStr, problemID); err != nil {
+		zap.L().Error("query problem revisions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+func GetProblemRevision(problemID uint64, version int) (revision *models.ProblemRevision, err error) {
+	revision = new(models.ProblemRevision)
+	sqlStr := `select r.problem_id, r.version, r.editor_id, coalesce(u.username, '') username,
+	r.title, r.content, r.statement, r.time_limit, r.memory_limit, r.difficulty, r.note, r.create_time
+	from problem_revision r
+	left join user u on u.user_id = r.editor_id
+	where r.problem_id = ? and r.version = ?`
+	err = db.Get(revision, sqlStr, problemID, version)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query problem revision failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"go.uber.org/zap"
+)
+
+// SearchProblems 按标题和内容全文检索题目，按相关度降序，不含对查看者隐藏的未开始比赛中的题目
+func SearchProblems(q string, page, size int64, viewer *models.ProblemViewer) (hits []*models.SearchHit, total int64, err error) {
+	cond, args := visibleProblemFilter("problem", viewer)
+	sqlStr := "select count(*) from problem where MATCH(title, content) AGAINST(?)" + cond
+	if err = db.Get(&total, sqlStr, append([]interface{}{q}, args...)...); err != nil {
+		zap.L().Error("count problem search failed", zap.Error(err))
+		return nil, 0, ErrorQueryFailed
+	}
+	sqlStr = `select problem_id id, problem_id, title, content,
+	MATCH(title, content) AGAINST(?) score
+	from problem
+	where MATCH(title, content) AGAINST(?)` + cond + `
+	ORDER BY score DESC
+	limit ?,?`
+	args = append(append([]interface{}{q, q}, args...), (page-1)*size, size)
+	hits = make([]*models.SearchHit, 0, size)
+	if err = db.Select(&hits, sqlStr, args...); err != nil {
+		zap.L().Error("search problem failed", zap.Error(err))
+		return nil, 0, ErrorQueryFailed
+	}
+	return
+}
+
+// SearchAnswers 全文检索题解内容，附带所属题目的标题，不含对查看者隐藏的题目的题解
+func SearchAnswers(q string, page, size int64, viewer *models.ProblemViewer) (hits []*models.SearchHit, total int64, err error) {
+	cond, args := visibleProblemFilter("p", viewer)
+	sqlStr := `select count(*) from answer a
+	left join problem p on p.problem_id = a.problem_id
+	where MATCH(a.content) AGAINST(?)` + cond
+	if err = db.Get(&total, sqlStr, append([]interface{}{q}, args...)...); err != nil {
+		zap.L().Error("count answer search failed", zap.Error(err))
+		return nil, 0, ErrorQueryFailed
+	}
+	sqlStr = `select a.answer_id id, a.problem_id, coalesce(p.title, '') title, a.content,
+	MATCH(a.content) AGAINST(?) score
+	from answer a
+	left join problem p on p.problem_id = a.problem_id
+	where MATCH(a.content) AGAINST(?)` + cond + `
+	ORDER BY score DESC
+	limit ?,?`
+	args = append(append([]interface{}{q, q}, args...), (page-1)*size, size)
+	hits = make([]*models.SearchHit, 0, size)
+	if err = db.Select(&hits, sqlStr, args...); err != nil {
+		zap.L().Error("search answer failed", zap.Error(err))
+		return nil, 0, ErrorQueryFailed
+	}
+	return
+}
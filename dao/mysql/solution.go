@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"go.uber.org/zap"
+)
+
+// ReplaceProblemSolutions 用新的一组参考程序替换题目原有的参考程序
+func ReplaceProblemSolutions(problemID uint64, solutions []*models.ProblemSolution) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec("delete from problem_solution where problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete problem solution failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr := "insert into problem_solution(problem_id, tag, language, source) values(?,?,?,?)"
+	for _, s := range solutions {
+		if _, err = tx.Exec(sqlStr, problemID, s.Tag, s.Language, s.Source); err != nil {
+			zap.L().Error("insert problem solution failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit problem solution failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+func GetProblemSolutions(problemID uint64) (solutions []*models.ProblemSolution, err error) {
+	sqlStr := `select problem_id, tag, language, source, create_time
+	from problem_solution
+	where problem_id = ?
+	ORDER BY id`
+	solutions = make([]*models.ProblemSolution, 0, 2)
+	if err = db.Select(&solutions, sqlStr, problemID); err != nil {
+		zap.L().Error("query problem solutions failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
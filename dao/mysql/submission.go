@@ -0,0 +1,198 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// CreateSubmission 保存提交记录
+func CreateSubmission(submission *models.Submission) (err error) {
+	sqlStr := `insert into submission(
+	submission_id, problem_id, user_id, contest_id, language, source, status, message)
+	values(?,?,?,?,?,?,?,?)`
+	_, err = db.Exec(sqlStr, submission.SubmissionID, submission.ProblemID, submission.UserID, submission.ContestID,
+		submission.Language, submission.Source, submission.Status, submission.Message)
+	if err != nil {
+		zap.L().Error("insert submission failed", zap.Error(err))
+		err = ErrorInsertFailed
+		return
+	}
+	return
+}
+
+func GetSubmissionByID(sid int64) (submission *models.Submission, err error) {
+	submission = new(models.Submission)
+	sqlStr := `select submission_id, problem_id, user_id, contest_id, language, source, status, time_ms, memory_kb, score, message, create_time
+	from submission
+	where submission_id = ?`
+	err = db.Get(submission, sqlStr, sid)
+	if err == sql.ErrNoRows {
+		err = ErrorInvalidID
+		return
+	}
+	if err != nil {
+		zap.L().Error("query submission failed", zap.String("sql", sqlStr), zap.Error(err))
+		err = ErrorQueryFailed
+		return
+	}
+	return
+}
+
+// GetSubmissionList 按条件分页查询提交记录，列表中不返回源代码
+func GetSubmissionList(p *models.ParamSubmissionList) (submissions []*models.Submission, err error) {
+	conditions := make([]string, 0, 3)
+	args := make([]interface{}, 0, 5)
+	if p.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, p.UserID)
+	}
+	if p.ProblemID != 0 {
+		conditions = append(conditions, "problem_id = ?")
+		args = append(args, p.ProblemID)
+	}
+	if p.ContestID != 0 {
+		conditions = append(conditions, "contest_id = ?")
+		args = append(args, p.ContestID)
+	}
+	if p.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, p.Status)
+	}
+	sqlStr := `select submission_id, problem_id, user_id, contest_id, language, status, time_ms, memory_kb, score, create_time
+	from submission`
+	if len(conditions) > 0 {
+		sqlStr += " where " + strings.Join(conditions, " and ")
+	}
+	sqlStr += `
+	ORDER BY submission_id
+	DESC
+	limit ?,?`
+	args = append(args, (p.Page-1)*p.Size, p.Size)
+	submissions = make([]*models.Submission, 0, p.Size)
+	err = db.Select(&submissions, sqlStr, args...)
+	if err != nil {
+		zap.L().Error("query submission list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// UpdateSubmissionStatus 只更新评测状态，用于 Pending -> Judging 等中间状态
+func UpdateSubmissionStatus(submissionID uint64, status string) (err error) {
+	sqlStr := "update submission set status = ? where submission_id = ?"
+	_, err = db.Exec(sqlStr, status, submissionID)
+	if err != nil {
+		zap.L().Error("update submission status failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// UpdateSubmissionsStatus 批量修改提交的状态
+func UpdateSubmissionsStatus(ids []uint64, status string) (err error) {
+	for start := 0; start < len(ids); start += rejudgeBatchSize {
+		end := start + rejudgeBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query, args, err := sqlx.In("update submission set status = ? where submission_id in (?)", status, ids[start:end])
+		if err != nil {
+			zap.L().Error("build update submission status failed", zap.Error(err))
+			return ErrorUpdateFailer
+		}
+		if _, err = db.Exec(db.Rebind(query), args...); err != nil {
+			zap.L().Error("update submissions status failed", zap.Error(err))
+			return ErrorUpdateFailer
+		}
+	}
+	return
+}
+
+// UpdateSubmissionResult 保存最终评测结果及各测试点、子任务的结果，重新评测时覆盖原有结果
+func UpdateSubmissionResult(submission *models.Submission, cases []*models.SubmissionCase,
+	subtasks []*models.SubmissionSubtask) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := `update submission
+	set status = ?, time_ms = ?, memory_kb = ?, score = ?, message = ?
+	where submission_id = ?`
+	_, err = tx.Exec(sqlStr, submission.Status, submission.TimeMs, submission.MemoryKb,
+		submission.Score, submission.Message, submission.SubmissionID)
+	if err != nil {
+		zap.L().Error("update submission result failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if _, err = tx.Exec("delete from submission_case where submission_id = ?", submission.SubmissionID); err != nil {
+		zap.L().Error("delete submission case failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr = `insert into submission_case(
+	submission_id, case_index, status, score, time_ms, memory_kb, message)
+	values(?,?,?,?,?,?,?)`
+	for _, sc := range cases {
+		_, err = tx.Exec(sqlStr, submission.SubmissionID, sc.Index, sc.Status, sc.Score, sc.TimeMs, sc.MemoryKb, sc.Message)
+		if err != nil {
+			zap.L().Error("insert submission case failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if _, err = tx.Exec("delete from submission_subtask where submission_id = ?", submission.SubmissionID); err != nil {
+		zap.L().Error("delete submission subtask failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr = `insert into submission_subtask(submission_id, subtask_index, status, score)
+	values(?,?,?,?)`
+	for _, st := range subtasks {
+		_, err = tx.Exec(sqlStr, submission.SubmissionID, st.Index, st.Status, st.Score)
+		if err != nil {
+			zap.L().Error("insert submission subtask failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit submission result failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+// GetSubmissionCases 查询提交在各测试点上的结果
+func GetSubmissionCases(submissionID uint64) (cases []*models.SubmissionCase, err error) {
+	sqlStr := `select submission_id, case_index, status, score, time_ms, memory_kb, message
+	from submission_case
+	where submission_id = ?
+	ORDER BY case_index`
+	cases = make([]*models.SubmissionCase, 0, 10)
+	err = db.Select(&cases, sqlStr, submissionID)
+	if err != nil {
+		zap.L().Error("query submission case failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetSubmissionSubtasks 查询提交在各子任务上的得分
+func GetSubmissionSubtasks(submissionID uint64) (subtasks []*models.SubmissionSubtask, err error) {
+	sqlStr := `select submission_id, subtask_index, status, score
+	from submission_subtask
+	where submission_id = ?
+	ORDER BY subtask_index`
+	subtasks = make([]*models.SubmissionSubtask, 0, 5)
+	err = db.Select(&subtasks, sqlStr, submissionID)
+	if err != nil {
+		zap.L().Error("query submission subtask failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"go.uber.org/zap"
+)
+
+// ReplaceSubtasks 用新的一组子任务替换题目原有的全部子任务
+func ReplaceSubtasks(problemID uint64, subtasks []*models.Subtask) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec("delete from subtask where problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete subtask failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr := `insert into subtask(problem_id, subtask_index, score, type, cases, dependencies)
+	values(?,?,?,?,?,?)`
+	for _, st := range subtasks {
+		_, err = tx.Exec(sqlStr, problemID, st.Index, st.Score, st.Type, st.Cases, st.Dependencies)
+		if err != nil {
+			zap.L().Error("insert subtask failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit subtask failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+func GetSubtaskList(problemID uint64) (subtasks []*models.Subtask, err error) {
+	sqlStr := `select problem_id, subtask_index, score, type, cases, dependencies
+	from subtask
+	where problem_id = ?
+	ORDER BY subtask_index`
+	subtasks = make([]*models.Subtask, 0, 5)
+	err = db.Select(&subtasks, sqlStr, problemID)
+	if err != nil {
+		zap.L().Error("query subtask list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// CreateTag 创建标签，标签名不能重复
+func CreateTag(tag *models.Tag) (err error) {
+	if err = checkTagName(tag.Name, 0); err != nil {
+		return
+	}
+	sqlStr := "insert into tag(tag_id, name) values(?,?)"
+	if _, err = db.Exec(sqlStr, tag.TagID, tag.Name); err != nil {
+		zap.L().Error("insert tag failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	return
+}
+
+// UpdateTag 修改标签名
+func UpdateTag(tagID uint64, name string) (err error) {
+	if err = checkTagName(name, tagID); err != nil {
+		return
+	}
+	result, err := db.Exec("update tag set name = ? where tag_id = ?", name, tagID)
+	if err != nil {
+		zap.L().Error("update tag failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// 名字未变时也不会影响任何行，需要确认标签是否存在
+		if _, err = GetTagByID(tagID); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// checkTagName 检查标签名是否已被其他标签使用
+func checkTagName(name string, tagID uint64) (err error) {
+	var count int
+	sqlStr := "select count(*) from tag where name = ? and tag_id != ?"
+	if err = db.Get(&count, sqlStr, name, tagID); err != nil {
+		zap.L().Error("query tag name failed", zap.Error(err))
+		return ErrorQueryFailed
+	}
+	if count > 0 {
+		return ErrorTagExist
+	}
+	return
+}
+
+// DeleteTag 删除标签及其与题目的关联
+func DeleteTag(tagID uint64) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	result, err := tx.Exec("delete from tag where tag_id = ?", tagID)
+	if err != nil {
+		zap.L().Error("delete tag failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrorInvalidID
+	}
+	if _, err = tx.Exec("delete from problem_tag where tag_id = ?", tagID); err != nil {
+		zap.L().Error("delete problem tag failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit delete tag failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+func GetTagByID(tagID uint64) (tag *models.Tag, err error) {
+	tag = new(models.Tag)
+	sqlStr := "select tag_id, name, create_time from tag where tag_id = ?"
+	err = db.Get(tag, sqlStr, tagID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query tag failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetTagList 所有标签及各自的题目数，按名称排序
+func GetTagList() (tags []*models.Tag, err error) {
+	sqlStr := `select t.tag_id, t.name, t.create_time, count(pt.problem_id) problem_num
+	from tag t
+	left join problem_tag pt on pt.tag_id = t.tag_id
+	group by t.tag_id, t.name, t.create_time
+	ORDER BY t.name`
+	tags = make([]*models.Tag, 0, 16)
+	if err = db.Select(&tags, sqlStr); err != nil {
+		zap.L().Error("query tag list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetTagsByNames 按名称查询标签，不存在的名称会被忽略
+func GetTagsByNames(names []string) (tags []*models.Tag, err error) {
+	tags = make([]*models.Tag, 0, len(names))
+	if len(names) == 0 {
+		return
+	}
+	query, args, err := sqlx.In("select tag_id, name, create_time from tag where name in (?)", names)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Select(&tags, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query tags by names failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetProblemTags 查询多个题目的标签
+func GetProblemTags(problemIDs ...uint64) (tags []*models.ProblemTag, err error) {
+	tags = make([]*models.ProblemTag, 0, len(problemIDs)*2)
+	if len(problemIDs) == 0 {
+		return
+	}
+	sqlStr := `select pt.problem_id, t.tag_id, t.name
+	from problem_tag pt
+	join tag t on t.tag_id = pt.tag_id
+	where pt.problem_id in (?)
+	ORDER BY t.name`
+	query, args, err := sqlx.In(sqlStr, problemIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Select(&tags, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query problem tags failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// SetProblemTags 用给定的标签替换题目原有的标签
+func SetProblemTags(problemID uint64, tagIDs []uint64) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec("delete from problem_tag where problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete problem tags failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	for _, tagID := range tagIDs {
+		sqlStr := "insert into problem_tag(problem_id, tag_id) values(?,?)"
+		if _, err = tx.Exec(sqlStr, problemID, tagID); err != nil {
+			zap.L().Error("insert problem tag failed", zap.Error(err))
+			return ErrorUpdateFailer
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit problem tags failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
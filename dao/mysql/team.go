@@ -0,0 +1,205 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"time"
+)
+
+// CreateTeam 创建队伍，队长同时成为第一个队员
+func CreateTeam(team *models.Team) (err error) {
+	var count int
+	if err = db.Get(&count, "select count(*) from team where name = ?", team.Name); err != nil {
+		zap.L().Error("query team name failed", zap.Error(err))
+		return ErrorQueryFailed
+	}
+	if count > 0 {
+		return ErrorTeamExist
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := "insert into team(team_id, name, captain_id) values(?,?,?)"
+	if _, err = tx.Exec(sqlStr, team.TeamID, team.Name, team.CaptainID); err != nil {
+		zap.L().Error("insert team failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	sqlStr = "insert into team_member(team_id, user_id) values(?,?)"
+	if _, err = tx.Exec(sqlStr, team.TeamID, team.CaptainID); err != nil {
+		zap.L().Error("insert team member failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit team failed", zap.Error(err))
+		return ErrorInsertFailed
+	}
+	return
+}
+
+func GetTeamByID(teamID uint64) (team *models.Team, err error) {
+	team = new(models.Team)
+	sqlStr := "select team_id, name, captain_id, create_time from team where team_id = ?"
+	err = db.Get(team, sqlStr, teamID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query team failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetUserTeams 查询用户所在的队伍
+func GetUserTeams(userID uint64) (teams []*models.Team, err error) {
+	sqlStr := `select t.team_id, t.name, t.captain_id, t.create_time
+	from team t
+	join team_member tm on tm.team_id = t.team_id
+	where tm.user_id = ?
+	ORDER BY t.create_time DESC`
+	teams = make([]*models.Team, 0, 4)
+	if err = db.Select(&teams, sqlStr, userID); err != nil {
+		zap.L().Error("query user teams failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// GetTeamMembers 查询多个队伍的队员，按加入顺序排序
+func GetTeamMembers(teamIDs ...uint64) (members []*models.TeamMember, err error) {
+	members = make([]*models.TeamMember, 0, len(teamIDs)*models.TeamMaxMembers)
+	if len(teamIDs) == 0 {
+		return
+	}
+	sqlStr := `select tm.team_id, tm.user_id, u.username, tm.create_time
+	from team_member tm
+	join user u on u.user_id = tm.user_id
+	where tm.team_id in (?)
+	ORDER BY tm.id`
+	query, args, err := sqlx.In(sqlStr, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Select(&members, db.Rebind(query), args...); err != nil {
+		zap.L().Error("query team members failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+func CreateTeamInvitation(inv *models.TeamInvitation) (err error) {
+	sqlStr := `insert into team_invitation(invitation_id, team_id, user_id, inviter_id, status)
+	values(?,?,?,?,?)`
+	_, err = db.Exec(sqlStr, inv.InvitationID, inv.TeamID, inv.UserID, inv.InviterID, inv.Status)
+	if err != nil {
+		zap.L().Error("insert team invitation failed", zap.Error(err))
+		err = ErrorInsertFailed
+	}
+	return
+}
+
+const invitationColumns = `i.invitation_id, i.team_id, t.name as team_name, i.user_id, i.inviter_id, i.status, i.create_time`
+
+func GetTeamInvitationByID(invitationID uint64) (inv *models.TeamInvitation, err error) {
+	inv = new(models.TeamInvitation)
+	sqlStr := `select ` + invitationColumns + `
+	from team_invitation i
+	join team t on t.team_id = i.team_id
+	where i.invitation_id = ?`
+	err = db.Get(inv, sqlStr, invitationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		zap.L().Error("query team invitation failed", zap.Error(err))
+		return nil, ErrorQueryFailed
+	}
+	return
+}
+
+// GetPendingInvitations 查询用户待回复的邀请
+func GetPendingInvitations(userID uint64) (invs []*models.TeamInvitation, err error) {
+	sqlStr := `select ` + invitationColumns + `
+	from team_invitation i
+	join team t on t.team_id = i.team_id
+	where i.user_id = ? and i.status = ?
+	ORDER BY i.create_time DESC`
+	invs = make([]*models.TeamInvitation, 0, 4)
+	if err = db.Select(&invs, sqlStr, userID, models.InvitationPending); err != nil {
+		zap.L().Error("query pending invitations failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+// HasPendingInvitation 队伍是否已向该用户发出待回复的邀请
+func HasPendingInvitation(teamID, userID uint64) (ok bool, err error) {
+	var count int
+	sqlStr := "select count(*) from team_invitation where team_id = ? and user_id = ? and status = ?"
+	if err = db.Get(&count, sqlStr, teamID, userID, models.InvitationPending); err != nil {
+		zap.L().Error("query pending invitation failed", zap.Error(err))
+		return false, ErrorQueryFailed
+	}
+	return count > 0, nil
+}
+
+// AcceptTeamInvitation 接受邀请并加入队伍
+func AcceptTeamInvitation(inv *models.TeamInvitation) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	sqlStr := "update team_invitation set status = ? where invitation_id = ?"
+	if _, err = tx.Exec(sqlStr, models.InvitationAccepted, inv.InvitationID); err != nil {
+		zap.L().Error("update team invitation failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr = "insert ignore into team_member(team_id, user_id) values(?,?)"
+	if _, err = tx.Exec(sqlStr, inv.TeamID, inv.UserID); err != nil {
+		zap.L().Error("insert team member failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit team invitation failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+func DeclineTeamInvitation(invitationID uint64) (err error) {
+	sqlStr := "update team_invitation set status = ? where invitation_id = ?"
+	if _, err = db.Exec(sqlStr, models.InvitationDeclined, invitationID); err != nil {
+		zap.L().Error("update team invitation failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+// IsTeamInActiveContest 队伍是否报名了尚未结束的比赛
+func IsTeamInActiveContest(teamID uint64, now time.Time) (ok bool, err error) {
+	var count int
+	sqlStr := `select count(*)
+	from contest_team ct
+	join contest c on c.contest_id = ct.contest_id
+	where ct.team_id = ? and DATE_ADD(c.start_time, INTERVAL c.duration MINUTE) > ?`
+	if err = db.Get(&count, sqlStr, teamID, now); err != nil {
+		zap.L().Error("query team active contest failed", zap.Error(err))
+		return false, ErrorQueryFailed
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"go.uber.org/zap"
+)
+
+// ReplaceTestCases 用新的一组测试数据替换题目原有的全部测试数据
+func ReplaceTestCases(problemID uint64, cases []*models.TestCase) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		zap.L().Error("begin transaction failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec("delete from testcase where problem_id = ?", problemID); err != nil {
+		zap.L().Error("delete testcase failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	sqlStr := `insert into testcase(
+	problem_id, case_index, input_size, output_size, input_md5, output_md5)
+	values(?,?,?,?,?,?)`
+	for _, tc := range cases {
+		_, err = tx.Exec(sqlStr, problemID, tc.Index, tc.InputSize, tc.OutputSize, tc.InputMD5, tc.OutputMD5)
+		if err != nil {
+			zap.L().Error("insert testcase failed", zap.Error(err))
+			return ErrorInsertFailed
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		zap.L().Error("commit testcase failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	return
+}
+
+// SaveTestCase 新增或覆盖单个测试点
+func SaveTestCase(tc *models.TestCase) (err error) {
+	sqlStr := `insert into testcase(
+	problem_id, case_index, input_size, output_size, input_md5, output_md5)
+	values(?,?,?,?,?,?)
+	on duplicate key update
+	input_size = values(input_size), output_size = values(output_size),
+	input_md5 = values(input_md5), output_md5 = values(output_md5)`
+	_, err = db.Exec(sqlStr, tc.ProblemID, tc.Index, tc.InputSize, tc.OutputSize, tc.InputMD5, tc.OutputMD5)
+	if err != nil {
+		zap.L().Error("save testcase failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+func GetTestCaseList(problemID uint64) (cases []*models.TestCase, err error) {
+	sqlStr := `select problem_id, case_index, input_size, output_size, input_md5, output_md5, create_time
+	from testcase
+	where problem_id = ?
+	ORDER BY case_index`
+	cases = make([]*models.TestCase, 0, 10)
+	err = db.Select(&cases, sqlStr, problemID)
+	if err != nil {
+		zap.L().Error("query testcase list failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
+
+func DeleteTestCase(problemID uint64, index int) (err error) {
+	sqlStr := "delete from testcase where problem_id = ? and case_index = ?"
+	result, err := db.Exec(sqlStr, problemID, index)
+	if err != nil {
+		zap.L().Error("delete testcase failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return ErrorUpdateFailer
+	}
+	if n == 0 {
+		return ErrorInvalidID
+	}
+	return
+}
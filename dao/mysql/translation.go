@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"LanShan/models"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// SaveProblemTranslation 新增或覆盖题目某种语言的译文
+func SaveProblemTranslation(t *models.ProblemTranslation) (err error) {
+	sqlStr := `insert into problem_translation(
+	problem_id, locale, title, content, statement, editor_id)
+	values(?,?,?,?,?,?)
+	on duplicate key update
+	title = values(title), content = values(content),
+	statement = values(statement), editor_id = values(editor_id)`
+	_, err = db.Exec(sqlStr, t.ProblemID, t.Locale, t.Title, t.Content, t.Statement, t.EditorID)
+	if err != nil {
+		zap.L().Error("save problem translation failed", zap.Error(err))
+		err = ErrorUpdateFailer
+	}
+	return
+}
+
+func DeleteProblemTranslation(problemID uint64, locale string) (err error) {
+	sqlStr := "delete from problem_translation where problem_id = ? and locale = ?"
+	result, err := db.Exec(sqlStr, problemID, locale)
+	if err != nil {
+		zap.L().Error("delete problem translation failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return ErrorUpdateFailer
+	}
+	if n == 0 {
+		return ErrorInvalidID
+	}
+	return
+}
+
+// GetProblemTranslations 查询一组题目的译文，locales 为空时返回全部语言
+func GetProblemTranslations(problemIDs []uint64, locales []string) (translations []*models.ProblemTranslation, err error) {
+	translations = make([]*models.ProblemTranslation, 0, len(problemIDs))
+	if len(problemIDs) == 0 {
+		return
+	}
+	sqlStr := `select problem_id, locale, title, content, statement, editor_id, update_time
+	from problem_translation
+	where problem_id in (?)`
+	args := []interface{}{problemIDs}
+	if len(locales) != 0 {
+		sqlStr += " and locale in (?)"
+		args = append(args, locales)
+	}
+	sqlStr += " ORDER BY problem_id, locale"
+	query, args, err := sqlx.In(sqlStr, args...)
+	if err != nil {
+		return
+	}
+	query = db.Rebind(query)
+	if err = db.Select(&translations, query, args...); err != nil {
+		zap.L().Error("query problem translations failed", zap.Error(err))
+		err = ErrorQueryFailed
+	}
+	return
+}
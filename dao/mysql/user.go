@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"database/sql"
 	"encoding/hex"
+	"go.uber.org/zap"
 )
 
 const secret = "Yuqin.vip"
@@ -33,14 +34,14 @@ func InsertUser(user *models.User) (error error) {
 	// 对密码进行加密
 	user.Password = encryptPassword([]byte(user.Password))
 	// 执行SQL语句入库
-	sqlstr := `insert into user(user_id,username,password) values(?,?,?)`
-	_, err := db.Exec(sqlstr, user.UserID, user.UserName, user.Password)
+	sqlstr := `insert into user(user_id,username,password,role) values(?,?,?,?)`
+	_, err := db.Exec(sqlstr, user.UserID, user.UserName, user.Password, user.Role)
 	return err
 }
 
 func Login(user *models.User) (err error) {
 	originPassword := user.Password // 记录一下原始密码(用户登录的密码)
-	sqlStr := "select user_id, username, password from user where username = ?"
+	sqlStr := "select user_id, username, password, role from user where username = ?"
 	err = db.Get(user, sqlStr, user.UserName)
 	if err != nil && err != sql.ErrNoRows {
 		// 查询数据库出错
@@ -60,7 +61,30 @@ func Login(user *models.User) (err error) {
 
 func GetUserByID(id uint64) (user *models.User, err error) {
 	user = new(models.User)
-	sqlStr := `select user_id, username from user where user_id = ?`
+	sqlStr := `select user_id, username, role from user where user_id = ?`
 	err = db.Get(user, sqlStr, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrorUserNotExit
+	}
 	return
 }
+
+// UpdateUserRole 修改用户角色
+func UpdateUserRole(id uint64, role string) (err error) {
+	result, err := db.Exec("update user set role = ? where user_id = ?", role, id)
+	if err != nil {
+		zap.L().Error("update user role failed", zap.Error(err))
+		return ErrorUpdateFailer
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return ErrorUpdateFailer
+	}
+	if n == 0 {
+		// 用户不存在，或者角色没有变化
+		if _, err = GetUserByID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
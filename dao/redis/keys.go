@@ -0,0 +1,17 @@
+package redis
+
+// redis key 注意使用命名空间的方式，方便查询和拆分
+const (
+	KeyPrefix                   = "onlineJudge:"
+	KeyJudgeQueue               = "judge:queue"           // list 待评测的提交id
+	KeyJudgeLowQueue            = "judge:queue:low"       // list 低优先级的待评测提交id，如重新评测
+	KeyJudgeWorkers             = "judge:workers"         // set 已注册的评测机
+	KeyJudgeProcessingPrefix    = "judge:processing:"     // list 评测机正在处理的提交id，参数是评测机名称
+	KeyJudgeLowProcessingPrefix = "judge:processing-low:" // list 评测机正在处理的低优先级提交id，参数是评测机名称
+	KeyJudgeHeartbeatPrefix     = "judge:heartbeat:"      // string 评测机心跳，过期即视为失联
+)
+
+// getRedisKey 给key加上前缀
+func getRedisKey(key string) string {
+	return KeyPrefix + key
+}
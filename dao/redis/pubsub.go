@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"LanShan/pubsub"
+	"github.com/go-redis/redis"
+	"sync"
+)
+
+// Broker 基于Redis pub/sub的发布订阅，多个API实例共享
+type Broker struct{}
+
+func NewBroker() *Broker {
+	return &Broker{}
+}
+
+func (Broker) Publish(channel string, message []byte) error {
+	return client.Publish(getRedisKey(channel), message).Err()
+}
+
+func (Broker) Subscribe(channel string) (pubsub.Subscription, error) {
+	ps := client.Subscribe(getRedisKey(channel))
+	// 等待订阅确认，保证返回后发布的消息都能收到
+	if _, err := ps.Receive(); err != nil {
+		_ = ps.Close()
+		return nil, err
+	}
+	sub := &subscription{ps: ps, ch: make(chan []byte), done: make(chan struct{})}
+	go sub.run()
+	return sub, nil
+}
+
+type subscription struct {
+	ps   *redis.PubSub
+	ch   chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *subscription) run() {
+	defer close(s.ch)
+	for msg := range s.ps.Channel() {
+		select {
+		case s.ch <- []byte(msg.Payload):
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *subscription) Messages() <-chan []byte {
+	return s.ch
+}
+
+func (s *subscription) Close() (err error) {
+	s.once.Do(func() {
+		close(s.done)
+		err = s.ps.Close()
+	})
+	return
+}
@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"github.com/go-redis/redis"
+	"strconv"
+	"time"
+)
+
+// lowPriorityPoll 阻塞等待普通队列的最长时间，超时后再检查低优先级队列
+const lowPriorityPoll = time.Second
+
+// judgeQueues 按优先级从高到低排列的待评测队列及对应的处理列表前缀
+var judgeQueues = []struct {
+	queue      string
+	processing string
+}{
+	{KeyJudgeQueue, KeyJudgeProcessingPrefix},
+	{KeyJudgeLowQueue, KeyJudgeLowProcessingPrefix},
+}
+
+// JudgeQueue 基于Redis list的评测队列，多个API实例和评测机共享同一个队列
+type JudgeQueue struct{}
+
+func NewJudgeQueue() *JudgeQueue {
+	return &JudgeQueue{}
+}
+
+func (JudgeQueue) Push(submissionID uint64) error {
+	return client.LPush(getRedisKey(KeyJudgeQueue), submissionID).Err()
+}
+
+func (JudgeQueue) PushLow(submissionID uint64) error {
+	return client.LPush(getRedisKey(KeyJudgeLowQueue), submissionID).Err()
+}
+
+func (JudgeQueue) Pop(worker string, timeout time.Duration) (submissionID uint64, ok bool, err error) {
+	if err = client.SAdd(getRedisKey(KeyJudgeWorkers), worker).Err(); err != nil {
+		return
+	}
+	// 原子地从待评测队列移入评测机的处理列表，评测机崩溃时任务不会丢失。
+	// 两种优先级的任务放在不同的处理列表中，放回时才能回到原来的队列。
+	// BRPOPLPUSH 只能等待一个队列，先依次尝试普通队列和低优先级队列，都为空时再短暂阻塞等待普通队列
+	var val string
+	for _, q := range judgeQueues {
+		val, err = client.RPopLPush(getRedisKey(q.queue), getRedisKey(q.processing+worker)).Result()
+		if err != redis.Nil {
+			break
+		}
+	}
+	if err == redis.Nil {
+		if timeout > lowPriorityPoll {
+			timeout = lowPriorityPoll
+		}
+		val, err = client.BRPopLPush(getRedisKey(KeyJudgeQueue), getRedisKey(KeyJudgeProcessingPrefix+worker), timeout).Result()
+	}
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	submissionID, err = strconv.ParseUint(val, 10, 64)
+	return submissionID, err == nil, err
+}
+
+func (JudgeQueue) Ack(worker string, submissionID uint64) error {
+	pipeline := client.TxPipeline()
+	for _, q := range judgeQueues {
+		pipeline.LRem(getRedisKey(q.processing+worker), 1, submissionID)
+	}
+	_, err := pipeline.Exec()
+	return err
+}
+
+func (JudgeQueue) Heartbeat(worker string, ttl time.Duration) error {
+	pipeline := client.TxPipeline()
+	pipeline.SAdd(getRedisKey(KeyJudgeWorkers), worker)
+	pipeline.Set(getRedisKey(KeyJudgeHeartbeatPrefix+worker), time.Now().Unix(), ttl)
+	_, err := pipeline.Exec()
+	return err
+}
+
+func (JudgeQueue) Requeue() (n int, err error) {
+	workers, err := client.SMembers(getRedisKey(KeyJudgeWorkers)).Result()
+	if err != nil {
+		return
+	}
+	for _, worker := range workers {
+		alive, err := client.Exists(getRedisKey(KeyJudgeHeartbeatPrefix + worker)).Result()
+		if err != nil {
+			return n, err
+		}
+		if alive > 0 {
+			continue
+		}
+		// 心跳已过期，把处理列表中的任务逐个放回原来的队列等待重新评测，
+		// 低优先级的重新评测任务不会因此排到正常提交前面
+		for _, q := range judgeQueues {
+			for {
+				err = client.RPopLPush(getRedisKey(q.processing+worker), getRedisKey(q.queue)).Err()
+				if err == redis.Nil {
+					break
+				}
+				if err != nil {
+					return n, err
+				}
+				n++
+			}
+		}
+		if err = client.SRem(getRedisKey(KeyJudgeWorkers), worker).Err(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
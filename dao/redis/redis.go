@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"LanShan/settings"
+	"fmt"
+	"github.com/go-redis/redis"
+)
+
+var client *redis.Client
+
+// Init 初始化Redis连接
+func Init(cfg *settings.RedisConfig) (err error) {
+	client = redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+	})
+	_, err = client.Ping().Result()
+	return
+}
+
+// Close 关闭Redis连接
+func Close() {
+	_ = client.Close()
+}
@@ -0,0 +1,246 @@
+package judge
+
+import (
+	"LanShan/models"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	checkerTimeout      = 10 * time.Second
+	checkerMemory       = 1 << 30
+	checkerMessageLimit = 1 << 10 // 每个测试点最多保留1KB的checker信息
+)
+
+// testlib 约定的checker退出码
+const (
+	exitOK      = 0
+	exitWA      = 1
+	exitPE      = 2
+	exitFail    = 3
+	exitPartial = 7
+)
+
+// Checker 输出比较方式。Type 为 custom 时使用题目作者上传并编译好的程序，
+// 以 `checker input output answer` 的方式运行并按 testlib 退出码判定
+type Checker struct {
+	Type    string
+	Epsilon float64 // float 比较允许的绝对/相对误差
+	Program *Program
+}
+
+// check 比较选手输出与标准答案，返回状态、得分比例和说明信息。
+// output 为评测服务打开的输出文件，不按路径重新打开，避免读到被替换的文件
+func (ck *Checker) check(input string, output *os.File, answer string) (status string, score float64, msg string, err error) {
+	typ := models.CheckerLine
+	if ck != nil {
+		typ = ck.Type
+	}
+	if typ == models.CheckerCustom {
+		return ck.runCustom(input, output, answer)
+	}
+
+	out, err := readOutput(output)
+	if err != nil {
+		return "", 0, "", err
+	}
+	ans, err := os.ReadFile(answer)
+	if err != nil {
+		return "", 0, "", err
+	}
+	switch typ {
+	case models.CheckerExact:
+		msg = compareExact(out, ans)
+	case models.CheckerToken:
+		msg = compareTokens(out, ans, -1)
+	case models.CheckerFloat:
+		msg = compareTokens(out, ans, ck.Epsilon)
+	default:
+		msg = compareLines(out, ans)
+	}
+	if msg != "" {
+		return models.StatusWrongAnswer, 0, msg, nil
+	}
+	return models.StatusAccepted, 1, "ok", nil
+}
+
+// outputFD 传给 checker、交互器的输出文件的描述符，通过 /dev/fd 访问
+const outputFD = 3
+
+// runCustom 运行自定义checker，按 testlib 退出码判定结果，输出文件以描述符的形式传入
+func (ck *Checker) runCustom(input string, output *os.File, answer string) (status string, score float64, msg string, err error) {
+	var buf limitedBuffer
+	buf.limit = checkerMessageLimit
+	lim := &limit{
+		CPUTime:  checkerTimeout,
+		WallTime: checkerTimeout,
+		Memory:   checkerMemory,
+		Output:   defaultOutputLimit,
+		Env:      append([]string{"HOME=" + ck.Program.Dir}, runEnv...),
+	}
+	// checker 在自己的目录下运行，文件路径需要转为绝对路径
+	files := []string{input, fmt.Sprintf("/dev/fd/%d", outputFD), answer}
+	for _, i := range []int{0, 2} {
+		if files[i], err = filepath.Abs(files[i]); err != nil {
+			return "", 0, "", err
+		}
+	}
+	if _, err = output.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", err
+	}
+	args := append(append([]string{}, ck.Program.Run...), files...)
+	cmd := newCommand(ck.Program.Dir, args, lim)
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	cmd.ExtraFiles = []*os.File{output}
+	wait, err := start(cmd, lim)
+	if err != nil {
+		return "", 0, "", err
+	}
+	u, err := wait()
+	if err != nil {
+		return "", 0, "", err
+	}
+	msg = strings.TrimSpace(buf.String())
+	if u.Signal != 0 || u.TimedOut || u.MemoryExceeded {
+		return models.StatusSystemError, 0, "checker crashed: " + msg, nil
+	}
+	switch u.ExitCode {
+	case exitOK:
+		return models.StatusAccepted, 1, msg, nil
+	case exitWA:
+		return models.StatusWrongAnswer, 0, msg, nil
+	case exitPE:
+		return models.StatusPresentationError, 0, msg, nil
+	case exitPartial:
+		score = parsePoints(msg)
+		if score >= 1 {
+			return models.StatusAccepted, 1, msg, nil
+		}
+		return models.StatusPartialCorrect, score, msg, nil
+	case exitFail:
+		return models.StatusSystemError, 0, "checker failed: " + msg, nil
+	}
+	return models.StatusSystemError, 0, fmt.Sprintf("checker exited with code %d: %s", u.ExitCode, msg), nil
+}
+
+// parsePoints 从 testlib quitp 输出中解析得分比例，格式如 "points 0.5 message"
+func parsePoints(msg string) float64 {
+	for _, field := range strings.Fields(msg) {
+		if strings.EqualFold(field, "points") {
+			continue
+		}
+		points, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0
+		}
+		return math.Max(0, math.Min(1, points))
+	}
+	return 0
+}
+
+// compareExact 要求输出与答案逐字节一致
+func compareExact(out, answer []byte) string {
+	if bytes.Equal(out, answer) {
+		return ""
+	}
+	return "output differs from answer"
+}
+
+// compareLines 逐行比较，忽略行末空白和文末空行
+func compareLines(out, answer []byte) string {
+	outLines := splitLines(out)
+	answerLines := splitLines(answer)
+	for i := 0; i < len(outLines) && i < len(answerLines); i++ {
+		if !bytes.Equal(outLines[i], answerLines[i]) {
+			return fmt.Sprintf("line %d differs - expected: '%s', found: '%s'",
+				i+1, abbreviate(answerLines[i]), abbreviate(outLines[i]))
+		}
+	}
+	if len(outLines) != len(answerLines) {
+		return fmt.Sprintf("expected %d lines, found %d lines", len(answerLines), len(outLines))
+	}
+	return ""
+}
+
+// compareTokens 按空白切分后逐个比较，epsilon 不小于0时数字按误差比较
+func compareTokens(out, answer []byte, epsilon float64) string {
+	outTokens := bytes.Fields(out)
+	answerTokens := bytes.Fields(answer)
+	for i := 0; i < len(outTokens) && i < len(answerTokens); i++ {
+		if bytes.Equal(outTokens[i], answerTokens[i]) {
+			continue
+		}
+		if epsilon >= 0 && floatEqual(outTokens[i], answerTokens[i], epsilon) {
+			continue
+		}
+		return fmt.Sprintf("token %d differs - expected: '%s', found: '%s'",
+			i+1, abbreviate(answerTokens[i]), abbreviate(outTokens[i]))
+	}
+	if len(outTokens) != len(answerTokens) {
+		return fmt.Sprintf("expected %d tokens, found %d tokens", len(answerTokens), len(outTokens))
+	}
+	return ""
+}
+
+// floatEqual 绝对误差或相对误差不超过 epsilon 即认为相等
+func floatEqual(out, answer []byte, epsilon float64) bool {
+	x, err := strconv.ParseFloat(string(out), 64)
+	if err != nil {
+		return false
+	}
+	y, err := strconv.ParseFloat(string(answer), 64)
+	if err != nil {
+		return false
+	}
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return false
+	}
+	diff := math.Abs(x - y)
+	return diff <= epsilon || diff <= epsilon*math.Abs(y)
+}
+
+// splitLines 按行切分并去掉行末空白，同时去掉末尾的空行
+func splitLines(data []byte) [][]byte {
+	lines := bytes.Split(data, []byte("\n"))
+	for i := range lines {
+		lines[i] = bytes.TrimRight(lines[i], " \t\r")
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// abbreviate 截断过长的内容，避免信息过大
+func abbreviate(data []byte) string {
+	const max = 64
+	if len(data) > max {
+		return string(data[:max]) + "..."
+	}
+	return string(data)
+}
+
+// limitedBuffer 只保留前 limit 字节的输出，超出部分直接丢弃
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,144 @@
+package judge
+
+import (
+	"LanShan/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// interactorMemory 交互器的内存上限
+const interactorMemory = 1 << 30
+
+// runInteractive 运行交互题的一个测试点。选手程序的标准输入输出与交互器交叉相连，
+// 交互器以 `interactor input output answer` 的方式运行并按 testlib 退出码判定，
+// 双方互相等待造成的死锁由墙上时间限制兜底。交互器的输出文件建在选手程序无法写入的 outDir 中，
+// 以描述符的形式传给交互器和checker
+func runInteractive(dir, outDir string, lang *Language, lim *limit, task *Task, tc *TestCase) (cr *CaseResult, err error) {
+	out, err := createOutput(outDir, "interactor.out")
+	if err != nil {
+		return nil, err
+	}
+	defer removeOutput(out)
+	files := []string{tc.Input, fmt.Sprintf("/dev/fd/%d", outputFD), tc.Answer}
+	for _, i := range []int{0, 2} {
+		if files[i], err = filepath.Abs(files[i]); err != nil {
+			return nil, err
+		}
+	}
+	// 选手 -> 交互器
+	toInteractor, fromUser, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer toInteractor.Close()
+	defer fromUser.Close()
+	// 交互器 -> 选手
+	toUser, fromInteractor, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer toUser.Close()
+	defer fromInteractor.Close()
+
+	var buf limitedBuffer
+	buf.limit = checkerMessageLimit
+	// 交互器的墙上时间多留一秒，死锁时先由选手程序超时
+	ilim := &limit{
+		CPUTime:  lim.WallTime,
+		WallTime: lim.WallTime + time.Second,
+		Memory:   interactorMemory,
+		Output:   defaultOutputLimit,
+		Env:      append([]string{"HOME=" + task.Interactor.Dir}, runEnv...),
+	}
+	icmd := newCommand(task.Interactor.Dir, append(append([]string{}, task.Interactor.Run...), files...), ilim)
+	icmd.Stdin = toInteractor
+	icmd.Stdout = fromInteractor
+	icmd.Stderr = &buf
+	icmd.ExtraFiles = []*os.File{out}
+	iwait, err := start(icmd, ilim)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := newCommand(dir, lang.Run, lim)
+	cmd.Stdin = toUser
+	cmd.Stdout = fromUser
+	wait, err := start(cmd, lim)
+	if err != nil {
+		// 选手程序没能启动，关闭管道让交互器读到EOF后退出
+		toInteractor.Close()
+		fromInteractor.Close()
+		_, _ = iwait()
+		return nil, err
+	}
+	// 关闭本进程持有的管道端，任何一方退出后另一方都能读到EOF或收到SIGPIPE
+	for _, f := range []*os.File{toInteractor, fromUser, toUser, fromInteractor} {
+		f.Close()
+	}
+
+	iu, ierr := iwait()
+	u, err := wait()
+	if err != nil {
+		return nil, err
+	}
+	if ierr != nil {
+		return nil, ierr
+	}
+
+	cr = &CaseResult{
+		Status:   verdict(u, lim),
+		TimeMs:   u.TimeMs,
+		MemoryKb: u.MemoryKb,
+	}
+	msg := strings.TrimSpace(buf.String())
+	// 选手超时、超内存时以选手的结果为准，双方死锁时同样判为超时
+	if cr.Status == models.StatusTimeLimitExceeded || cr.Status == models.StatusMemoryLimitExceeded {
+		return cr, nil
+	}
+	if iu.TimedOut {
+		cr.Status = models.StatusTimeLimitExceeded
+		return cr, nil
+	}
+	if iu.Signal != 0 || iu.MemoryExceeded {
+		cr.Status, cr.Message = models.StatusSystemError, "interactor crashed: "+msg
+		return cr, nil
+	}
+	switch iu.ExitCode {
+	case exitOK, exitPartial:
+	case exitWA:
+		// 交互器判错后提前退出，选手程序可能因 SIGPIPE 运行错误，以交互器的结果为准
+		cr.Status, cr.Message = models.StatusWrongAnswer, msg
+		return cr, nil
+	case exitPE:
+		cr.Status, cr.Message = models.StatusPresentationError, msg
+		return cr, nil
+	case exitFail:
+		cr.Status, cr.Message = models.StatusSystemError, "interactor failed: "+msg
+		return cr, nil
+	default:
+		cr.Status, cr.Message = models.StatusSystemError, fmt.Sprintf("interactor exited with code %d: %s", iu.ExitCode, msg)
+		return cr, nil
+	}
+	// 交互器认可后，选手程序本身的运行错误仍然有效
+	if cr.Status != models.StatusAccepted {
+		return cr, nil
+	}
+	if task.Checker != nil && task.Checker.Type == models.CheckerCustom {
+		// 交互器只记录交互过程，由checker判定交互器输出的结果
+		cr.Status, cr.Score, cr.Message, err = task.Checker.check(tc.Input, out, tc.Answer)
+		if err != nil {
+			return nil, err
+		}
+		return cr, nil
+	}
+	cr.Status, cr.Score, cr.Message = models.StatusAccepted, 1, msg
+	if iu.ExitCode == exitPartial {
+		if cr.Score = parsePoints(msg); cr.Score < 1 {
+			cr.Status = models.StatusPartialCorrect
+		}
+	}
+	return cr, nil
+}
@@ -0,0 +1,278 @@
+package judge
+
+import (
+	"LanShan/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	compileTimeout      = 30 * time.Second
+	compileMessageLimit = 8 << 10  // 编译信息最多保留8KB
+	defaultOutputLimit  = 64 << 20 // 默认输出上限64MB
+	defaultProcessLimit = 128
+	// 墙上时间为CPU时间限制的倍数，防止sleep、等待输入等方式挂起
+	wallTimeFactor = 2
+)
+
+var (
+	ErrorUnknownLanguage = errors.New("不支持的语言")
+
+	// WorkDir 评测临时目录的父目录
+	WorkDir = os.TempDir()
+	// RunUser 编译和运行选手程序的用户，为空时以评测服务自身的用户运行。
+	// 该用户不能有权读取测试数据目录，否则选手程序可以直接读取答案
+	RunUser *Credential
+	// runEnv 运行选手程序时使用的环境变量
+	runEnv = []string{"PATH=/usr/local/bin:/usr/bin:/bin", "LANG=C.UTF-8"}
+)
+
+// TestCase 一组测试数据，均为文件路径
+type TestCase struct {
+	Index  int // 测试点编号，为0时按顺序从1开始编号
+	Input  string
+	Answer string
+}
+
+// Task 一次评测任务
+type Task struct {
+	Language    string
+	Source      string
+	TimeLimit   int64    // 毫秒
+	MemoryLimit int64    // MB
+	OutputLimit int64    // 字节，0使用默认值
+	Checker     *Checker // 为空时按行比较
+	Interactor  *Program // 交互题的交互器，为空表示普通题目
+	TestCases   []TestCase
+	// OnCase 每个测试点评测完成后调用，用于推送评测进度
+	OnCase func(cr *CaseResult)
+}
+
+// CaseResult 单个测试点的评测结果
+type CaseResult struct {
+	Index    int     `json:"index"`
+	Status   string  `json:"status"`
+	TimeMs   int64   `json:"time_ms"`
+	MemoryKb int64   `json:"memory_kb"`
+	Score    float64 `json:"score"`             // 得分比例，0~1
+	Message  string  `json:"message,omitempty"` // checker 输出的信息
+}
+
+// Result 评测结果，Status为第一个未通过测试点的状态，时间和内存取各测试点最大值
+type Result struct {
+	Status         string        `json:"status"`
+	TimeMs         int64         `json:"time_ms"`
+	MemoryKb       int64         `json:"memory_kb"`
+	CompileMessage string        `json:"compile_message,omitempty"`
+	Cases          []*CaseResult `json:"cases"`
+}
+
+// Run 在临时目录中编译并逐个测试点运行提交的代码。
+// 临时目录属于评测服务，选手程序只能写入其中的 work 子目录，输出文件放在 work 之外，
+// 防止选手程序把输出文件替换为指向测试数据的符号链接
+func Run(task *Task) (result *Result, err error) {
+	lang, ok := GetLanguage(task.Language)
+	if !ok {
+		return nil, ErrorUnknownLanguage
+	}
+	outDir, err := os.MkdirTemp(WorkDir, "judge-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(outDir)
+	dir := filepath.Join(outDir, "work")
+	if err = os.Mkdir(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(filepath.Join(dir, lang.SourceFile), []byte(task.Source), 0644); err != nil {
+		return nil, err
+	}
+	// 编译产物由运行用户写入 work 目录，运行用户只能进入而不能修改外层目录
+	if RunUser != nil {
+		if err = os.Chmod(outDir, 0711); err != nil {
+			return nil, err
+		}
+		if err = os.Chown(dir, int(RunUser.UID), int(RunUser.GID)); err != nil {
+			return nil, err
+		}
+	}
+
+	result = &Result{Status: models.StatusAccepted}
+	// 1、编译
+	if len(lang.Compile) > 0 {
+		msg, ok, err := compile(dir, lang.Compile, RunUser)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			result.Status = models.StatusCompileError
+			result.CompileMessage = msg
+			return result, nil
+		}
+	}
+
+	// 2、逐个测试点运行
+	lim := runLimit(dir, lang, task)
+	for i, tc := range task.TestCases {
+		cr, err := runCase(dir, outDir, lang, lim, task, &tc)
+		if err != nil {
+			return nil, err
+		}
+		cr.Index = tc.Index
+		if cr.Index == 0 {
+			cr.Index = i + 1
+		}
+		result.Cases = append(result.Cases, cr)
+		if task.OnCase != nil {
+			task.OnCase(cr)
+		}
+		if cr.TimeMs > result.TimeMs {
+			result.TimeMs = cr.TimeMs
+		}
+		if cr.MemoryKb > result.MemoryKb {
+			result.MemoryKb = cr.MemoryKb
+		}
+		if result.Status == models.StatusAccepted && cr.Status != models.StatusAccepted {
+			result.Status = cr.Status
+		}
+	}
+	return
+}
+
+// compile 以 user 的身份编译源代码，返回编译信息以及是否编译成功。
+// 选手代码也要以运行用户编译，防止通过 #include 等方式在编译期读取测试数据
+func compile(dir string, args []string, user *Credential) (msg string, ok bool, err error) {
+	var out bytes.Buffer
+	lim := &limit{
+		WallTime: compileTimeout,
+		Output:   defaultOutputLimit,
+		Env:      append(os.Environ(), "HOME="+dir),
+		User:     user,
+	}
+	cmd := newCommand(dir, args, lim)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	wait, err := start(cmd, lim)
+	if err != nil {
+		return "", false, err
+	}
+	u, err := wait()
+	if err != nil {
+		return "", false, err
+	}
+	msg = out.String()
+	if len(msg) > compileMessageLimit {
+		msg = msg[:compileMessageLimit]
+	}
+	if u.TimedOut {
+		msg += "\ncompile time limit exceeded"
+	}
+	return msg, !u.TimedOut && u.Signal == 0 && u.ExitCode == 0, nil
+}
+
+// runLimit 根据语言倍率计算运行限制
+func runLimit(dir string, lang *Language, task *Task) *limit {
+	cpu := time.Duration(float64(task.TimeLimit)*lang.TimeFactor) * time.Millisecond
+	memory := int64(float64(task.MemoryLimit)*lang.MemoryFactor) << 20
+	lim := &limit{
+		CPUTime:   cpu,
+		WallTime:  cpu*wallTimeFactor + time.Second,
+		Memory:    memory,
+		Output:    task.OutputLimit,
+		Processes: defaultProcessLimit,
+		Env:       append([]string{"HOME=" + dir}, runEnv...),
+		User:      RunUser,
+	}
+	if lim.Output <= 0 {
+		lim.Output = defaultOutputLimit
+	}
+	if lang.LimitAddressSpace {
+		// 地址空间限制只是兜底，留出余量让超限的程序由内存监控判为MLE，而不是分配失败变成RE
+		lim.AddressSpace = memory*2 + 16<<20
+	}
+	return lim
+}
+
+// removeOutput 关闭并删除 createOutput 创建的文件
+func removeOutput(f *os.File) {
+	f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// readOutput 从头读取已打开的输出文件
+func readOutput(f *os.File) ([]byte, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(f)
+}
+
+// runCase 运行一个测试点并比较输出，选手程序的输出写入 outDir
+func runCase(dir, outDir string, lang *Language, lim *limit, task *Task, tc *TestCase) (cr *CaseResult, err error) {
+	if task.Interactor != nil {
+		return runInteractive(dir, outDir, lang, lim, task, tc)
+	}
+	in, err := os.Open(tc.Input)
+	if err != nil {
+		return nil, fmt.Errorf("open input %s: %w", tc.Input, err)
+	}
+	defer in.Close()
+	out, err := createOutput(outDir, "user.out")
+	if err != nil {
+		return nil, err
+	}
+	defer removeOutput(out)
+
+	cmd := newCommand(dir, lang.Run, lim)
+	cmd.Stdin = in
+	cmd.Stdout = out
+	wait, err := start(cmd, lim)
+	if err != nil {
+		return nil, err
+	}
+	u, err := wait()
+	if err != nil {
+		return nil, err
+	}
+	// CPython 等运行时忽略 SIGXFSZ，写满后以写入错误退出，需按输出文件大小补充判定
+	if fi, serr := out.Stat(); serr == nil && fi.Size() >= lim.Output {
+		u.OutputExceeded = true
+	}
+
+	cr = &CaseResult{
+		Status:   verdict(u, lim),
+		TimeMs:   u.TimeMs,
+		MemoryKb: u.MemoryKb,
+	}
+	if cr.Status != models.StatusAccepted {
+		return cr, nil
+	}
+	// 比较输出
+	cr.Status, cr.Score, cr.Message, err = task.Checker.check(tc.Input, out, tc.Answer)
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// verdict 根据资源占用和退出状态判定结果，运行正常时返回AC，由调用方继续比较输出
+func verdict(u *usage, lim *limit) string {
+	switch {
+	case u.MemoryExceeded:
+		return models.StatusMemoryLimitExceeded
+	case u.TimedOut || u.CPUExceeded || time.Duration(u.TimeMs)*time.Millisecond > lim.CPUTime:
+		return models.StatusTimeLimitExceeded
+	case lim.Memory > 0 && u.MemoryKb > lim.Memory>>10:
+		return models.StatusMemoryLimitExceeded
+	case u.OutputExceeded:
+		return models.StatusOutputLimitExceeded
+	case u.Signal != 0 || u.ExitCode != 0:
+		return models.StatusRuntimeError
+	}
+	return models.StatusAccepted
+}
@@ -0,0 +1,285 @@
+//go:build linux
+
+package judge
+
+import (
+	"LanShan/models"
+	"LanShan/settings"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func init() {
+	SetLanguages([]*settings.LanguageConfig{
+		{Name: "c", SourceFile: "main.c", Compile: "gcc -O2 -std=c11 -o main main.c -lm", Run: "./main",
+			LimitAddressSpace: true, Enabled: true},
+		{Name: "go", SourceFile: "main.go", Compile: "go build -o main main.go", Run: "./main", Enabled: true},
+		{Name: "python3", SourceFile: "main.py", Run: "python3 main.py", LimitAddressSpace: true, Enabled: true},
+	})
+}
+
+// requireTool 缺少编译器或解释器时跳过测试
+func requireTool(t *testing.T, name string) {
+	t.Helper()
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("%s not found", name)
+	}
+}
+
+// writeCase 在 dir 下写入一组测试数据
+func writeCase(t *testing.T, dir, input, answer string) TestCase {
+	t.Helper()
+	tc := TestCase{Input: filepath.Join(dir, "1.in"), Answer: filepath.Join(dir, "1.out")}
+	if err := os.WriteFile(tc.Input, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tc.Answer, []byte(answer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return tc
+}
+
+func TestRunVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		language string
+		source   string
+		output   int64 // 输出上限(字节)
+		want     string
+	}{
+		{"c accepted", "gcc", "c", `#include <stdio.h>
+int main(void) { int a, b; scanf("%d %d", &a, &b); printf("%d\n", a + b); return 0; }`, 0, models.StatusAccepted},
+		{"c wrong answer", "gcc", "c", `#include <stdio.h>
+int main(void) { puts("0"); return 0; }`, 0, models.StatusWrongAnswer},
+		{"c compile error", "gcc", "c", `int main(void) { return }`, 0, models.StatusCompileError},
+		{"c time limit", "gcc", "c", `int main(void) { volatile unsigned long i = 0; for (;;) i++; }`, 0,
+			models.StatusTimeLimitExceeded},
+		{"c sleep", "gcc", "c", `#include <unistd.h>
+int main(void) { sleep(10); return 0; }`, 0, models.StatusTimeLimitExceeded},
+		{"c memory limit", "gcc", "c", `#include <stdlib.h>
+#include <string.h>
+int main(void) { for (;;) { char *p = malloc(1 << 20); if (p) memset(p, 1, 1 << 20); } }`, 0,
+			models.StatusMemoryLimitExceeded},
+		{"c runtime error", "gcc", "c", `int main(void) { volatile int *p = 0; return *p; }`, 0,
+			models.StatusRuntimeError},
+		{"c exit code", "gcc", "c", `int main(void) { return 3; }`, 0, models.StatusRuntimeError},
+		{"c output limit", "gcc", "c", `#include <stdio.h>
+int main(void) { for (;;) fputs("0123456789abcdef", stdout); }`, 1 << 20, models.StatusOutputLimitExceeded},
+		{"go accepted", "go", "go", `package main
+
+import "fmt"
+
+func main() { var a, b int; fmt.Scan(&a, &b); fmt.Println(a + b) }`, 0, models.StatusAccepted},
+		{"go memory limit", "go", "go", `package main
+
+func main() {
+	var keep [][]byte
+	for {
+		b := make([]byte, 1<<20)
+		for i := range b {
+			b[i] = 1
+		}
+		keep = append(keep, b)
+	}
+}`, 0, models.StatusMemoryLimitExceeded},
+		{"go panic", "go", "go", `package main
+
+func main() { var m map[string]int; m["a"] = 1 }`, 0, models.StatusRuntimeError},
+		{"python accepted", "python3", "python3", "a, b = map(int, input().split())\nprint(a + b)\n", 0,
+			models.StatusAccepted},
+		{"python time limit", "python3", "python3", "while True:\n    pass\n", 0, models.StatusTimeLimitExceeded},
+		{"python runtime error", "python3", "python3", "raise ValueError('boom')\n", 0, models.StatusRuntimeError},
+		{"python output limit", "python3", "python3", "while True:\n    print('0123456789abcdef')\n", 1 << 20,
+			models.StatusOutputLimitExceeded},
+	}
+	dataDir := t.TempDir()
+	tc := writeCase(t, dataDir, "1 2\n", "3\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requireTool(t, tt.tool)
+			result, err := Run(&Task{
+				Language:    tt.language,
+				Source:      tt.source,
+				TimeLimit:   1000,
+				MemoryLimit: 64,
+				OutputLimit: tt.output,
+				TestCases:   []TestCase{tc},
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("Run() status = %s, want %s (compile message: %s)",
+					result.Status, tt.want, result.CompileMessage)
+			}
+		})
+	}
+}
+
+// TestRunUserCannotReadData 以独立用户运行时，选手程序无法读取数据目录中的答案
+func TestRunUserCannotReadData(t *testing.T) {
+	requireTool(t, "gcc")
+	if os.Geteuid() != 0 {
+		t.Skip("switching user requires root")
+	}
+	RunUser = &Credential{UID: 65534, GID: 65534}
+	defer func() { RunUser = nil }()
+
+	dataDir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	tc := writeCase(t, dataDir, "", "denied\n")
+	source := `#include <stdio.h>
+int main(void) {
+	FILE *f = fopen("` + tc.Answer + `", "r");
+	puts(f ? "leaked" : "denied");
+	return 0;
+}`
+	result, err := Run(&Task{Language: "c", Source: source, TimeLimit: 1000, MemoryLimit: 64,
+		TestCases: []TestCase{tc}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != models.StatusAccepted {
+		t.Errorf("Run() status = %s, want %s (compile message: %s)",
+			result.Status, models.StatusAccepted, result.CompileMessage)
+	}
+
+	// 编译期也不能通过 #include 读取答案
+	source = `#include "` + tc.Answer + `"
+int main(void) { return 0; }`
+	result, err = Run(&Task{Language: "c", Source: source, TimeLimit: 1000, MemoryLimit: 64,
+		TestCases: []TestCase{tc}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != models.StatusCompileError || !strings.Contains(result.CompileMessage, "Permission denied") {
+		t.Errorf("Run() status = %s, compile message = %q, want permission denied",
+			result.Status, result.CompileMessage)
+	}
+}
+
+// TestRunOutputSymlink 选手程序不能把输出文件替换为指向答案的符号链接
+func TestRunOutputSymlink(t *testing.T) {
+	requireTool(t, "gcc")
+	if os.Geteuid() != 0 {
+		t.Skip("switching user requires root")
+	}
+	RunUser = &Credential{UID: 65534, GID: 65534}
+	defer func() { RunUser = nil }()
+
+	dataDir := t.TempDir()
+	tc := writeCase(t, dataDir, "", "secret\n")
+	other := filepath.Join(dataDir, "2.out")
+	if err := os.WriteFile(other, []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 尝试把当前目录和上层目录中的输出文件都换成指向答案的链接
+	source := `#include <stdio.h>
+#include <unistd.h>
+int main(void) {
+	const char *names[] = {"user.out", "../user.out", "interactor.out", "../interactor.out"};
+	for (int i = 0; i < 4; i++) {
+		unlink(names[i]);
+		symlink("` + tc.Answer + `", names[i]);
+	}
+	puts("wrong");
+	return 0;
+}`
+	tests := []TestCase{tc, {Input: tc.Input, Answer: other}}
+	result, err := Run(&Task{Language: "c", Source: source, TimeLimit: 1000, MemoryLimit: 64, TestCases: tests})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Status != models.StatusWrongAnswer {
+		t.Errorf("Run() status = %s, want %s", result.Status, models.StatusWrongAnswer)
+	}
+	for _, name := range []string{tc.Answer, other} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) == "wrong\n" {
+			t.Errorf("answer %s was overwritten", name)
+		}
+	}
+}
+
+// TestRunCustomCheckerAndInteractor 自定义checker和交互器通过描述符读写输出文件
+func TestRunCustomCheckerAndInteractor(t *testing.T) {
+	requireTool(t, "gcc")
+	progDir := t.TempDir()
+	// checker 要求输出文件的第一行与答案相同
+	checker, err := PrepareProgram(filepath.Join(progDir, "checker"), "c", `#include <stdio.h>
+#include <string.h>
+int main(int argc, char **argv) {
+	char out[64] = "", ans[64] = "";
+	FILE *o = fopen(argv[2], "r"), *a = fopen(argv[3], "r");
+	if (!o || !a) return 3;
+	fgets(out, sizeof out, o);
+	fgets(ans, sizeof ans, a);
+	return strcmp(out, ans) == 0 ? 0 : 1;
+}`)
+	if err != nil {
+		t.Fatalf("PrepareProgram() error = %v", err)
+	}
+	// 交互器把选手的回答写入输出文件
+	interactor, err := PrepareProgram(filepath.Join(progDir, "interactor"), "c", `#include <stdio.h>
+int main(int argc, char **argv) {
+	int x;
+	FILE *o = fopen(argv[2], "w");
+	if (!o) return 3;
+	printf("1 2\n");
+	fflush(stdout);
+	if (scanf("%d", &x) != 1) return 1;
+	fprintf(o, "%d\n", x);
+	return 0;
+}`)
+	if err != nil {
+		t.Fatalf("PrepareProgram() error = %v", err)
+	}
+
+	dataDir := t.TempDir()
+	tc := writeCase(t, dataDir, "1 2\n", "3\n")
+	tests := []struct {
+		name       string
+		source     string
+		interactor *Program
+		want       string
+	}{
+		{"checker accepted", `#include <stdio.h>
+int main(void) { puts("3"); return 0; }`, nil, models.StatusAccepted},
+		{"checker wrong answer", `#include <stdio.h>
+int main(void) { puts("4"); return 0; }`, nil, models.StatusWrongAnswer},
+		{"interactive accepted", `#include <stdio.h>
+int main(void) { int a, b; scanf("%d %d", &a, &b); printf("%d\n", a + b); return 0; }`,
+			interactor, models.StatusAccepted},
+		{"interactive wrong answer", `#include <stdio.h>
+int main(void) { int a, b; scanf("%d %d", &a, &b); printf("%d\n", a - b); return 0; }`,
+			interactor, models.StatusWrongAnswer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(&Task{
+				Language:    "c",
+				Source:      tt.source,
+				TimeLimit:   1000,
+				MemoryLimit: 64,
+				Checker:     &Checker{Type: models.CheckerCustom, Program: checker},
+				Interactor:  tt.interactor,
+				TestCases:   []TestCase{tc},
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("Run() status = %s, want %s (message: %s)", result.Status, tt.want, result.Cases[0].Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package judge
+
+import (
+	"LanShan/settings"
+	"strings"
+	"sync"
+)
+
+// Language 描述一种语言如何编译与运行，命令均在评测临时目录下执行
+type Language struct {
+	Name        string   `json:"name"` // 语言标识，如 c、cpp、go、python3
+	DisplayName string   `json:"display_name"`
+	SourceFile  string   `json:"source_file"` // 源代码文件名
+	Compile     []string `json:"compile"`     // 编译命令，为空表示无需编译
+	Run         []string `json:"run"`         // 运行命令
+	// 时间、内存限制倍率，用于照顾解释型语言
+	TimeFactor   float64 `json:"time_factor"`
+	MemoryFactor float64 `json:"memory_factor"`
+	// 是否用 RLIMIT_AS 限制地址空间。Go、Java 等运行时启动时会预留大量虚拟内存，
+	// 只能依靠实际占用内存(RSS)判定是否超限
+	LimitAddressSpace bool `json:"-"`
+}
+
+var (
+	languageMu   sync.RWMutex
+	languages    = make(map[string]*Language)
+	languageList []*Language // 保持配置文件中的顺序
+)
+
+// SetLanguages 根据配置重建语言表，只保留启用的语言，配置热加载时再次调用即可生效
+func SetLanguages(cfgs []*settings.LanguageConfig) {
+	m := make(map[string]*Language, len(cfgs))
+	list := make([]*Language, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg == nil || !cfg.Enabled || cfg.Name == "" {
+			continue
+		}
+		if _, ok := m[cfg.Name]; ok {
+			continue // 重名时以先出现的为准
+		}
+		lang := &Language{
+			Name:              cfg.Name,
+			DisplayName:       cfg.DisplayName,
+			SourceFile:        cfg.SourceFile,
+			Compile:           strings.Fields(cfg.Compile),
+			Run:               strings.Fields(cfg.Run),
+			TimeFactor:        cfg.TimeFactor,
+			MemoryFactor:      cfg.MemoryFactor,
+			LimitAddressSpace: cfg.LimitAddressSpace,
+		}
+		if lang.DisplayName == "" {
+			lang.DisplayName = lang.Name
+		}
+		if lang.TimeFactor <= 0 {
+			lang.TimeFactor = 1
+		}
+		if lang.MemoryFactor <= 0 {
+			lang.MemoryFactor = 1
+		}
+		list = append(list, lang)
+		m[lang.Name] = lang
+	}
+	languageMu.Lock()
+	languages = m
+	languageList = list
+	languageMu.Unlock()
+}
+
+// GetLanguage 根据名称查找已启用的语言
+func GetLanguage(name string) (lang *Language, ok bool) {
+	languageMu.RLock()
+	defer languageMu.RUnlock()
+	lang, ok = languages[name]
+	return
+}
+
+// Languages 返回所有已启用的语言
+func Languages() []*Language {
+	languageMu.RLock()
+	defer languageMu.RUnlock()
+	return languageList
+}
@@ -0,0 +1,87 @@
+package judge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 编译成功后写入的标记文件
+const programReadyFile = ".ready"
+
+var ErrorProgramCompile = errors.New("辅助程序编译失败")
+
+// Program 编译好的辅助程序，如自定义checker、交互器，Run 在 Dir 下执行
+type Program struct {
+	Dir string
+	Run []string
+}
+
+// CompileError 辅助程序的编译错误，Message 为编译器输出
+type CompileError struct {
+	Message string
+}
+
+func (e *CompileError) Error() string {
+	return ErrorProgramCompile.Error() + ": " + e.Message
+}
+
+func (e *CompileError) Unwrap() error {
+	return ErrorProgramCompile
+}
+
+// programMu 防止同一进程内多个评测机同时编译同一个程序
+var programMu sync.Mutex
+
+// PrepareProgram 在 dir 中编译辅助程序，已经编译过则直接复用。
+// dir 应随源代码变化(如按源码哈希命名)，这样修改后会重新编译
+func PrepareProgram(dir, language, source string) (prog *Program, err error) {
+	lang, ok := GetLanguage(language)
+	if !ok {
+		return nil, ErrorUnknownLanguage
+	}
+	prog = &Program{Dir: dir, Run: lang.Run}
+	if _, err = os.Stat(filepath.Join(dir, programReadyFile)); err == nil {
+		return prog, nil
+	}
+
+	programMu.Lock()
+	defer programMu.Unlock()
+	if _, err = os.Stat(filepath.Join(dir, programReadyFile)); err == nil {
+		return prog, nil
+	}
+	// 先在临时目录编译，成功后改名，多个实例共享数据目录时也不会看到编译了一半的程序
+	if err = os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".compile-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err = os.WriteFile(filepath.Join(tmp, lang.SourceFile), []byte(source), 0644); err != nil {
+		return nil, err
+	}
+	if len(lang.Compile) > 0 {
+		// 辅助程序由出题人提供，需要读取测试数据，以评测服务自身的用户编译运行
+		msg, ok, err := compile(tmp, lang.Compile, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &CompileError{Message: msg}
+		}
+	}
+	if err = os.WriteFile(filepath.Join(tmp, programReadyFile), nil, 0644); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		// 其它实例已经编译好了
+		if _, serr := os.Stat(filepath.Join(dir, programReadyFile)); serr == nil {
+			return prog, nil
+		}
+		return nil, err
+	}
+	return prog, nil
+}
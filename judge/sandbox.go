@@ -0,0 +1,36 @@
+package judge
+
+import (
+	"syscall"
+	"time"
+)
+
+// limit 单次运行的资源限制，字段为0表示不限制
+type limit struct {
+	CPUTime      time.Duration
+	WallTime     time.Duration
+	Memory       int64 // 物理内存(字节)，超出后立即杀死进程
+	AddressSpace int64 // 地址空间(字节)
+	Output       int64 // 单个文件最大写入量(字节)
+	Processes    int
+	Env          []string
+	User         *Credential // 以该用户身份运行，为空时沿用评测服务的用户
+}
+
+// Credential 运行不可信程序的低权限用户
+type Credential struct {
+	UID uint32
+	GID uint32
+}
+
+// usage 单次运行的资源占用及退出情况
+type usage struct {
+	TimeMs         int64 // CPU时间(毫秒)
+	MemoryKb       int64 // 峰值内存(KB)
+	ExitCode       int
+	Signal         syscall.Signal
+	TimedOut       bool // 超过墙上时间被杀
+	MemoryExceeded bool // 超过内存限制被杀
+	CPUExceeded    bool // 收到SIGXCPU
+	OutputExceeded bool // 收到SIGXFSZ
+}
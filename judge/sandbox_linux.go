@@ -0,0 +1,156 @@
+//go:build linux
+
+package judge
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 内存监控的采样间隔，同时用于统计峰值内存
+const monitorInterval = 5 * time.Millisecond
+
+// newCommand 构造一个受 rlimit 限制的命令。Go 无法直接为子进程设置 rlimit，
+// 这里借助 sh 的 ulimit 设置好限制后再 exec 到目标程序，进程号保持不变
+func newCommand(dir string, args []string, lim *limit) *exec.Cmd {
+	ulimits := make([]string, 0, 4)
+	if lim.CPUTime > 0 {
+		// RLIMIT_CPU 以秒为单位，向上取整并多留一秒，精确的超时由实际用时判定
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", int64(lim.CPUTime/time.Second)+1))
+	}
+	if lim.AddressSpace > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", lim.AddressSpace>>10))
+	}
+	if lim.Output > 0 {
+		// ulimit -f 以 512 字节为单位
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -f %d", (lim.Output+511)/512))
+	}
+	if lim.Processes > 0 {
+		// RLIMIT_NPROC 按用户计数，配合独立的运行用户才能准确限制
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -u %d", lim.Processes))
+	}
+	script := strings.Join(append(ulimits, `exec "$@"`), "; ")
+	cmd := exec.Command("/bin/sh", append([]string{"-c", script, "judge"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = lim.Env
+	// 独立进程组，超时时连同子进程一起杀掉
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if lim.User != nil {
+		// 切换用户需要评测服务以root运行(或具有CAP_SETUID、CAP_SETGID)，附加组一并清空
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:    lim.User.UID,
+			Gid:    lim.User.GID,
+			Groups: []uint32{},
+		}
+	}
+	return cmd
+}
+
+// start 启动进程并开始监控墙上时间和内存，调用方随后需调用返回的 wait
+func start(cmd *exec.Cmd, lim *limit) (wait func() (*usage, error), err error) {
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	pid := cmd.Process.Pid
+	u := new(usage)
+	done := make(chan struct{})
+	killed := make(chan struct{})
+
+	go func() {
+		defer close(killed)
+		var timeout <-chan time.Time
+		if lim.WallTime > 0 {
+			timer := time.NewTimer(lim.WallTime)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		ticker := time.NewTicker(monitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timeout:
+				u.TimedOut = true
+				_ = syscall.Kill(-pid, syscall.SIGKILL)
+				return
+			case <-ticker.C:
+				hwm := readStatusKb(fmt.Sprintf("/proc/%d/status", pid), "VmHWM:")
+				if hwm > u.MemoryKb {
+					u.MemoryKb = hwm
+				}
+				if lim.Memory > 0 && hwm > lim.Memory>>10 {
+					u.MemoryExceeded = true
+					_ = syscall.Kill(-pid, syscall.SIGKILL)
+					return
+				}
+			}
+		}
+	}()
+
+	wait = func() (*usage, error) {
+		werr := cmd.Wait()
+		close(done)
+		<-killed
+		// 目标进程退出后清理它可能留下的子进程
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+		state := cmd.ProcessState
+		if state == nil {
+			return nil, werr
+		}
+		if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+			u.TimeMs = (ru.Utime.Nano() + ru.Stime.Nano()) / int64(time.Millisecond)
+			// exec 会保留旧地址空间的峰值，而子进程 exec 前与本进程共享地址空间，
+			// 所以 Maxrss 不会低于本进程的峰值内存，只有超过时才是选手程序的真实峰值
+			if ru.Maxrss > readStatusKb("/proc/self/status", "VmHWM:") && ru.Maxrss > u.MemoryKb {
+				u.MemoryKb = ru.Maxrss
+			}
+		}
+		if ws, ok := state.Sys().(syscall.WaitStatus); ok {
+			if ws.Signaled() {
+				u.Signal = ws.Signal()
+				u.CPUExceeded = u.Signal == syscall.SIGXCPU
+				u.OutputExceeded = u.Signal == syscall.SIGXFSZ
+			}
+			u.ExitCode = ws.ExitStatus()
+		}
+		return u, nil
+	}
+	return wait, nil
+}
+
+// createOutput 在 outDir 中新建输出文件，文件已存在或是符号链接时失败。
+// 调用方使用完毕后需用 removeOutput 关闭并删除
+func createOutput(outDir, name string) (*os.File, error) {
+	return os.OpenFile(filepath.Join(outDir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0600)
+}
+
+// readStatusKb 从 /proc/<pid>/status 中读取以KB为单位的字段，读取失败返回0
+func readStatusKb(path, key string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, key) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		kb, _ := strconv.ParseInt(fields[1], 10, 64)
+		return kb
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package judge
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+var errUnsupported = errors.New("评测沙箱仅支持Linux")
+
+func newCommand(dir string, args []string, lim *limit) *exec.Cmd {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	return cmd
+}
+
+func start(cmd *exec.Cmd, lim *limit) (wait func() (*usage, error), err error) {
+	return nil, errUnsupported
+}
+
+func createOutput(outDir, name string) (*os.File, error) {
+	return os.OpenFile(filepath.Join(outDir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"LanShan/api"
 	"LanShan/dao/mysql"
+	"LanShan/dao/redis"
+	"LanShan/judge"
 	"LanShan/logger"
 	"LanShan/router"
+	"LanShan/service"
 	"LanShan/settings"
 	"LanShan/utils/snowflake"
 	"fmt"
@@ -25,6 +28,11 @@ func main() {
 		return
 	}
 	defer mysql.Close() // 程序退出关闭数据库连接
+	if err := redis.Init(settings.Conf.RedisConfig); err != nil {
+		fmt.Printf("init redis failed, err:%v\n", err)
+		return
+	}
+	defer redis.Close()
 	// 雪花算法生成分布式ID
 	if err := snowflake.Init(1); err != nil {
 		fmt.Printf("init snowflake failed, err:%v\n", err)
@@ -34,6 +42,18 @@ func main() {
 		fmt.Printf("init validator Trans failed,err:%v\n", err)
 		return
 	}
+	// 评测语言，配置文件修改后热加载
+	judge.SetLanguages(settings.Conf.Languages)
+	settings.OnChange(func(conf *settings.AppConfig) {
+		judge.SetLanguages(conf.Languages)
+	})
+	// 评测队列及评测机，评测进度通过Redis发布订阅推送给所有实例
+	service.InitJudgeQueue(redis.NewJudgeQueue())
+	service.InitBroker(redis.NewBroker())
+	if err := service.StartJudgeWorkers(settings.Conf.JudgeConfig); err != nil {
+		fmt.Printf("start judge workers failed, err:%v\n", err)
+		return
+	}
 	// 注册路由
 	r := router.SetupRouter(settings.Conf.Mode)
 	err := r.Run(fmt.Sprintf(":%d", settings.Conf.Port))
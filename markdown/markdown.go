@@ -0,0 +1,167 @@
+// Package markdown 把题面 Markdown 渲染为安全的 HTML。
+// 数学公式($...$、$$...$$)不经过 Markdown 解析，原样转义后输出，由前端的 KaTeX/MathJax 排版
+package markdown
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		// 允许题面中的原始 HTML(如 FPS 导入的题目)，最终统一由 policy 过滤
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 保留代码块的语言标记，便于前端高亮
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	// 公式的外层标签，替换占位符后会再过滤一次
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math (inline|display)$`)).OnElements("span")
+	return p
+}
+
+// Render 渲染 Markdown，返回过滤后的 HTML。行内公式输出为 \(...\)，行间公式输出为 \[...\]
+func Render(src string) string {
+	// 占位符带有每次渲染随机生成的标记，题面中无法预先写出，避免普通文本被替换为公式
+	var nonce [8]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return html.EscapeString(src)
+	}
+	prefix := "MATH" + hex.EncodeToString(nonce[:]) + "X"
+	text, formulas := extractMath(src, prefix)
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(text), &buf); err != nil {
+		// goldmark 只会在写入失败时返回错误，写入 bytes.Buffer 不会失败
+		return html.EscapeString(src)
+	}
+	placeholder := regexp.MustCompile(prefix + `(\d+)X`)
+	out := placeholder.ReplaceAllStringFunc(policy.Sanitize(buf.String()), func(s string) string {
+		var i int
+		fmt.Sscanf(placeholder.FindStringSubmatch(s)[1], "%d", &i)
+		if i >= len(formulas) {
+			return s
+		}
+		return formulas[i]
+	})
+	// 占位符可能落在属性值等位置，替换后再过滤一次，保证输出仍是安全的 HTML
+	return policy.Sanitize(out)
+}
+
+// extractMath 把公式替换为以 prefix 开头的占位符，返回替换后的文本和转义后的公式。
+// 代码块、行内代码以及 \$ 中的美元符号不视为公式
+func extractMath(src, prefix string) (string, []string) {
+	var (
+		out      strings.Builder
+		formulas []string
+		inFence  bool
+		fence    string
+	)
+	add := func(tex string, display bool) {
+		tex = html.EscapeString(tex)
+		if display {
+			formulas = append(formulas, `<span class="math display">\[`+tex+`\]</span>`)
+		} else {
+			formulas = append(formulas, `<span class="math inline">\(`+tex+`\)</span>`)
+		}
+		fmt.Fprintf(&out, "%s%dX", prefix, len(formulas)-1)
+	}
+	for i := 0; i < len(src); {
+		// 行首检查围栏代码块
+		if i == 0 || src[i-1] == '\n' {
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i + 1
+			}
+			line := strings.TrimLeft(src[i:end], " ")
+			if inFence || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+				if !inFence {
+					inFence, fence = true, line[:3]
+				} else if strings.HasPrefix(line, fence) {
+					inFence = false
+				}
+				out.WriteString(src[i:end])
+				i = end
+				continue
+			}
+		}
+		switch {
+		case src[i] == '\\' && i+1 < len(src):
+			out.WriteString(src[i : i+2])
+			i += 2
+		case src[i] == '`':
+			n := 1
+			for i+n < len(src) && src[i+n] == '`' {
+				n++
+			}
+			run := src[i : i+n]
+			end := strings.Index(src[i+n:], run)
+			if end < 0 {
+				out.WriteString(run)
+				i += n
+				break
+			}
+			end += i + 2*n
+			out.WriteString(src[i:end])
+			i = end
+		case strings.HasPrefix(src[i:], "$$"):
+			end := strings.Index(src[i+2:], "$$")
+			if end < 0 {
+				out.WriteString("$$")
+				i += 2
+				break
+			}
+			add(strings.TrimSpace(src[i+2:i+2+end]), true)
+			i += end + 4
+		case src[i] == '$':
+			end := inlineMathEnd(src, i+1)
+			if end < 0 {
+				out.WriteByte('$')
+				i++
+				break
+			}
+			add(src[i+1:end], false)
+			i = end + 1
+		default:
+			out.WriteByte(src[i])
+			i++
+		}
+	}
+	return out.String(), formulas
+}
+
+// inlineMathEnd 查找行内公式的结束位置，公式不能跨行，首尾不能是空白
+func inlineMathEnd(src string, start int) int {
+	if start >= len(src) || src[start] == ' ' || src[start] == '\n' {
+		return -1
+	}
+	for j := start; j < len(src); j++ {
+		switch src[j] {
+		case '\n':
+			return -1
+		case '\\':
+			j++
+		case '$':
+			if src[j-1] == ' ' {
+				return -1
+			}
+			return j
+		}
+	}
+	return -1
+}
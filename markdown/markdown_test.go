@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"inline math", "求 $a+b$ 的值", `<p>求 <span class="math inline">\(a+b\)</span> 的值</p>`},
+		{"display math", "$$\\sum_{i=1}^n i$$", `<p><span class="math display">\[\sum_{i=1}^n i\]</span></p>`},
+		{"escaped formula", "$a<b$", `<p><span class="math inline">\(a&lt;b\)</span></p>`},
+		{"escaped dollar", `\$5`, `<p>$5</p>`},
+		{"inline code", "`$a$`", `<p><code>$a$</code></p>`},
+		{"script removed", "<script>alert(1)</script>ok", `ok`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.TrimSpace(Render(tt.src)); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderPlaceholderInjection 题面中写出的旧式占位符不会被替换为公式
+func TestRenderPlaceholderInjection(t *testing.T) {
+	tests := []string{
+		"$x$ MATHPLACEHOLDER0X",
+		`$x$ <a href="https://example.com/MATHPLACEHOLDER0X">link</a>`,
+		`$x$ <img src="MATHPLACEHOLDER0X" alt="MATHPLACEHOLDER0X">`,
+	}
+	for _, src := range tests {
+		got := Render(src)
+		if n := strings.Count(got, `class="math inline"`); n != 1 {
+			t.Errorf("Render(%q) = %q, want exactly one formula", src, got)
+		}
+		if !strings.Contains(got, "MATHPLACEHOLDER0X") {
+			t.Errorf("Render(%q) = %q, want literal placeholder kept", src, got)
+		}
+	}
+}
+
+// TestRenderMathInAttribute 落在属性值中的公式不能破坏标签结构
+func TestRenderMathInAttribute(t *testing.T) {
+	got := Render(`[link](https://example.com/$x" onclick="alert(1)$)`)
+	if strings.Contains(got, `onclick="`) {
+		t.Errorf("Render() = %q, want no event handler attribute", got)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// 输出比较方式
+const (
+	CheckerExact  = "exact"  // 逐字节一致
+	CheckerLine   = "line"   // 逐行比较，忽略行末空白和文末空行(默认)
+	CheckerToken  = "token"  // 按空白切分逐个比较
+	CheckerFloat  = "float"  // 按空白切分，数字允许绝对/相对误差
+	CheckerCustom = "custom" // 题目作者上传的checker程序
+)
+
+// DefaultCheckerEpsilon float 比较未指定误差时使用的默认值
+const DefaultCheckerEpsilon = 1e-6
+
+// Checker 题目的输出比较方式，未设置时按 line 比较
+type Checker struct {
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Type       string    `json:"type" db:"type"`
+	Epsilon    float64   `json:"epsilon" db:"epsilon"`
+	Language   string    `json:"language,omitempty" db:"language"` // 自定义checker的语言
+	Source     string    `json:"source,omitempty" db:"source"`     // 自定义checker的源代码
+	UpdateTime time.Time `json:"update_time" db:"update_time"`
+}
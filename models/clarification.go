@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Clarification 比赛中的答疑，ProblemLabel 为空表示针对整场比赛。
+// Public 为 true 时所有参赛者可见，否则只有提问者可见
+type Clarification struct {
+	ClarificationID uint64     `json:"clarification_id,string" db:"clarification_id"`
+	ContestID       uint64     `json:"contest_id,string" db:"contest_id"`
+	UserID          uint64     `json:"user_id,string" db:"user_id"` // 他人的公开答疑不展示提问者
+	Username        string     `json:"username,omitempty" db:"username"`
+	ProblemLabel    string     `json:"problem_label" db:"problem_label"`
+	Question        string     `json:"question" db:"question"`
+	Answer          string     `json:"answer" db:"answer"`
+	Public          bool       `json:"public" db:"public"`
+	AnswererID      uint64     `json:"answerer_id,string" db:"answerer_id"`
+	AnswerTime      *time.Time `json:"answer_time" db:"answer_time"` // 未回复时为 null
+	CreateTime      time.Time  `json:"create_time" db:"create_time"`
+}
+
+// ApiClarificationUnread 未读答疑数量
+type ApiClarificationUnread struct {
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// 比赛赛制
+const (
+	ContestRuleACM = "acm" // ACM-ICPC，按通过题数和罚时排名
+	ContestRuleOI  = "oi"  // OI，每题以最后一次提交的得分为准，比赛结束前不公布结果
+	ContestRuleIOI = "ioi" // IOI，每个子任务取历次提交的最高分，比赛结束前不公布结果
+)
+
+// 比赛可见性
+const (
+	ContestPublic  = "public"  // 公开，任何人都可以报名
+	ContestPrivate = "private" // 私有，报名需要密码
+)
+
+type Contest struct {
+	ContestID   uint64    `json:"contest_id,string" db:"contest_id"`
+	AuthorID    uint64    `json:"author_id,string" db:"author_id"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	Rule        string    `json:"rule" db:"rule"`
+	Visibility  string    `json:"visibility" db:"visibility"`
+	Password    string    `json:"-" db:"password"`
+	StartTime   time.Time `json:"start_time" db:"start_time"`
+	Duration    int64     `json:"duration" db:"duration"` // 比赛时长(分钟)
+	Freeze      int64     `json:"freeze" db:"freeze"`     // 比赛结束前多少分钟封榜，0表示不封榜
+	Unfrozen    bool      `json:"unfrozen" db:"unfrozen"` // 是否已解除封榜
+	Rated       bool      `json:"rated" db:"rated"`       // 是否计算等级分
+	CreateTime  time.Time `json:"create_time" db:"create_time"`
+}
+
+// EndTime 比赛结束时间
+func (c *Contest) EndTime() time.Time {
+	return c.StartTime.Add(time.Duration(c.Duration) * time.Minute)
+}
+
+// IsOIRule 是否为比赛结束前隐藏评测结果的赛制
+func (c *Contest) IsOIRule() bool {
+	return c.Rule == ContestRuleOI || c.Rule == ContestRuleIOI
+}
+
+// HidesResults 比赛结束前是否对参赛者隐藏评测结果
+func (c *Contest) HidesResults(now time.Time) bool {
+	return c.IsOIRule() && now.Before(c.EndTime())
+}
+
+// FreezeTime 封榜时间
+func (c *Contest) FreezeTime() time.Time {
+	return c.EndTime().Add(-time.Duration(c.Freeze) * time.Minute)
+}
+
+// Frozen 排行榜是否处于封榜状态，比赛结束后仍保持封榜，直到管理员解除
+func (c *Contest) Frozen(now time.Time) bool {
+	return c.Freeze > 0 && !c.Unfrozen && !now.Before(c.FreezeTime())
+}
+
+// Running 比赛是否正在进行
+func (c *Contest) Running(now time.Time) bool {
+	return !now.Before(c.StartTime) && now.Before(c.EndTime())
+}
+
+// ContestProblem 比赛中的题目，Label 为 A、B、C 等题号
+type ContestProblem struct {
+	ContestID uint64 `json:"-" db:"contest_id"`
+	ProblemID uint64 `json:"problem_id,string" db:"problem_id"`
+	Label     string `json:"label" db:"label"`
+	Title     string `json:"title" db:"title"`
+}
+
+// ContestParticipant 比赛报名记录
+type ContestParticipant struct {
+	ContestID  uint64    `json:"contest_id,string" db:"contest_id"`
+	UserID     uint64    `json:"user_id,string" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// VirtualParticipation 比赛结束后的虚拟参赛，从 StartTime 开始计时，时长与原比赛相同
+type VirtualParticipation struct {
+	ContestID uint64    `json:"contest_id,string" db:"contest_id"`
+	UserID    uint64    `json:"user_id,string" db:"user_id"`
+	StartTime time.Time `json:"start_time" db:"start_time"`
+	EndTime   time.Time `json:"end_time" db:"end_time"`
+}
+
+// Running 虚拟参赛是否正在进行
+func (v *VirtualParticipation) Running(now time.Time) bool {
+	return !now.Before(v.StartTime) && now.Before(v.EndTime)
+}
+
+// ContestTeam 以队伍报名的比赛参赛者
+type ContestTeam struct {
+	ContestID uint64        `json:"contest_id,string" db:"contest_id"`
+	TeamID    uint64        `json:"team_id,string" db:"team_id"`
+	Name      string        `json:"name" db:"name"`
+	Members   []*TeamMember `json:"members" db:"-"`
+}
+
+// ApiContestDetail 比赛详情，比赛开始前不展示题目
+type ApiContestDetail struct {
+	*Contest
+	EndTime  time.Time         `json:"end_time"`
+	Problems []*ContestProblem `json:"problems"`
+}
@@ -6,6 +6,8 @@ CREATE TABLE `user` (
     `password` varchar(64) COLLATE utf8mb4_general_ci NOT NULL,
     `email` varchar(64) COLLATE utf8mb4_general_ci,
     `gender` tinyint(4) NOT NULL DEFAULT '0',
+    `role` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'user' COMMENT '角色 user/setter/moderator/admin',
+    `rating` int(11) NOT NULL DEFAULT '1500' COMMENT '等级分',
     `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
     `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
@@ -36,16 +38,45 @@ CREATE TABLE `problem` (
     `id` bigint(20) NOT NULL AUTO_INCREMENT,
     `problem_id` bigint(20) NOT NULL COMMENT '问题id',
     `title` varchar(128) COLLATE utf8mb4_general_ci NOT NULL COMMENT '标题',
-    `content` varchar(8192) COLLATE utf8mb4_general_ci NOT NULL COMMENT '内容',
+    `content` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '由结构化题面拼接的完整内容',
+    `statement` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '结构化题面(JSON)',
+    `locale` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'zh' COMMENT '题面的原始语言',
     `author_id` bigint(20) NOT NULL COMMENT '作者的用户id',
     `community_id` bigint(20) NOT NULL COMMENT '所属社区',
+    `time_limit` int(11) NOT NULL DEFAULT '1000' COMMENT '时间限制(毫秒)',
+    `memory_limit` int(11) NOT NULL DEFAULT '256' COMMENT '内存限制(MB)',
+    `difficulty` int(11) NOT NULL DEFAULT '0' COMMENT '难度，0表示未评定',
     `status` tinyint(4) NOT NULL DEFAULT '1' COMMENT '帖子状态',
     `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY `idx_post_id` (`problem_id`),
     KEY `idx_author_id` (`author_id`),
-    KEY `idx_community_id` (`community_id`)
+    KEY `idx_community_id` (`community_id`),
+    KEY `idx_difficulty` (`difficulty`),
+    FULLTEXT KEY `ft_title_content` (`title`, `content`) WITH PARSER ngram
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `tag`;
+CREATE TABLE `tag` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `tag_id` bigint(20) unsigned NOT NULL COMMENT '标签id',
+    `name` varchar(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '标签名',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_tag_id` (`tag_id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `problem_tag`;
+CREATE TABLE `problem_tag` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `tag_id` bigint(20) unsigned NOT NULL COMMENT '标签id',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_tag` (`problem_id`, `tag_id`),
+    KEY `idx_tag_id` (`tag_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 
 
@@ -78,5 +109,362 @@ CREATE TABLE `answer` (
     `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
     UNIQUE KEY `idx_answer_id` (`answer_id`),
-    KEY `idx_author_Id` (`author_id`)
+    KEY `idx_author_Id` (`author_id`),
+    FULLTEXT KEY `ft_content` (`content`) WITH PARSER ngram
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `submission`;
+CREATE TABLE `submission` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `submission_id` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `user_id` bigint(20) NOT NULL COMMENT '提交者的用户id',
+    `contest_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '比赛id，不属于比赛时为0',
+    `language` varchar(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '语言',
+    `source` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '源代码',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'Pending' COMMENT '评测状态',
+    `time_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT '运行时间(毫秒)',
+    `memory_kb` bigint(20) NOT NULL DEFAULT '0' COMMENT '占用内存(KB)',
+    `score` double NOT NULL DEFAULT '0' COMMENT '得分',
+    `message` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '评测信息，如编译错误',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '提交时间',
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_submission_id` (`submission_id`),
+    KEY `idx_problem_id` (`problem_id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_contest_id` (`contest_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `testcase`;
+CREATE TABLE `testcase` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `case_index` int(11) NOT NULL COMMENT '测试点编号',
+    `input_size` bigint(20) NOT NULL DEFAULT '0' COMMENT '输入文件大小(字节)',
+    `output_size` bigint(20) NOT NULL DEFAULT '0' COMMENT '输出文件大小(字节)',
+    `input_md5` char(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '输入文件md5',
+    `output_md5` char(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '输出文件md5',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_case` (`problem_id`, `case_index`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `checker`;
+CREATE TABLE `checker` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `type` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'line' COMMENT '比较方式 exact/line/token/float/custom',
+    `epsilon` double NOT NULL DEFAULT '0' COMMENT 'float比较允许的误差',
+    `language` varchar(32) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '自定义checker的语言',
+    `source` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '自定义checker的源代码',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_id` (`problem_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `submission_case`;
+CREATE TABLE `submission_case` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `submission_id` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `case_index` int(11) NOT NULL COMMENT '测试点编号',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL COMMENT '评测状态',
+    `score` double NOT NULL DEFAULT '0' COMMENT '得分比例',
+    `time_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT '运行时间(毫秒)',
+    `memory_kb` bigint(20) NOT NULL DEFAULT '0' COMMENT '占用内存(KB)',
+    `message` text COLLATE utf8mb4_general_ci NOT NULL COMMENT 'checker输出的信息',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_submission_case` (`submission_id`, `case_index`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `interactor`;
+CREATE TABLE `interactor` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `language` varchar(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '交互器的语言',
+    `source` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '交互器的源代码',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_id` (`problem_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `subtask`;
+CREATE TABLE `subtask` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `subtask_index` int(11) NOT NULL COMMENT '子任务编号',
+    `score` double NOT NULL DEFAULT '0' COMMENT '子任务分值',
+    `type` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'min' COMMENT '得分方式 min/sum',
+    `cases` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '测试点编号，逗号分隔',
+    `dependencies` varchar(256) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '依赖的子任务编号，逗号分隔',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_subtask` (`problem_id`, `subtask_index`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `submission_subtask`;
+CREATE TABLE `submission_subtask` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `submission_id` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `subtask_index` int(11) NOT NULL COMMENT '子任务编号',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL COMMENT '评测状态',
+    `score` double NOT NULL DEFAULT '0' COMMENT '得分',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_submission_subtask` (`submission_id`, `subtask_index`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `rejudge`;
+CREATE TABLE `rejudge` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `rejudge_id` bigint(20) unsigned NOT NULL COMMENT '重新评测id',
+    `user_id` bigint(20) NOT NULL COMMENT '发起者的用户id',
+    `total` int(11) NOT NULL DEFAULT '0' COMMENT '提交数量',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_rejudge_id` (`rejudge_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `submission_history`;
+CREATE TABLE `submission_history` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `submission_id` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `rejudge_id` bigint(20) unsigned NOT NULL COMMENT '重新评测id',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL COMMENT '原评测状态',
+    `score` double NOT NULL DEFAULT '0' COMMENT '原得分',
+    `time_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT '原运行时间(毫秒)',
+    `memory_kb` bigint(20) NOT NULL DEFAULT '0' COMMENT '原占用内存(KB)',
+    `message` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '原评测信息',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '重新评测的时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_submission_id` (`submission_id`),
+    KEY `idx_rejudge_id` (`rejudge_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `contest`;
+CREATE TABLE `contest` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `author_id` bigint(20) NOT NULL COMMENT '创建者的用户id',
+    `title` varchar(128) COLLATE utf8mb4_general_ci NOT NULL COMMENT '标题',
+    `description` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '说明',
+    `rule` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'acm' COMMENT '赛制：acm、oi、ioi',
+    `visibility` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'public' COMMENT '可见性 public/private',
+    `password` varchar(64) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '私有比赛报名密码的bcrypt哈希',
+    `start_time` timestamp NOT NULL COMMENT '开始时间',
+    `duration` int(11) NOT NULL COMMENT '时长(分钟)',
+    `freeze` int(11) NOT NULL DEFAULT '0' COMMENT '结束前多少分钟封榜，0表示不封榜',
+    `unfrozen` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否已解除封榜',
+    `rated` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否计算等级分',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_id` (`contest_id`),
+    KEY `idx_start_time` (`start_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `contest_problem`;
+CREATE TABLE `contest_problem` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `label` varchar(8) COLLATE utf8mb4_general_ci NOT NULL COMMENT '题号，如A、B、C',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_label` (`contest_id`, `label`),
+    UNIQUE KEY `idx_contest_problem` (`contest_id`, `problem_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `contest_participant`;
+CREATE TABLE `contest_participant` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `user_id` bigint(20) NOT NULL COMMENT '用户id',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '报名时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_user` (`contest_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `clarification`;
+CREATE TABLE `clarification` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `clarification_id` bigint(20) unsigned NOT NULL COMMENT '答疑id',
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `user_id` bigint(20) NOT NULL COMMENT '提问者的用户id',
+    `problem_label` varchar(8) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '题号，为空表示针对整场比赛',
+    `question` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '问题',
+    `answer` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '回复',
+    `public` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否对所有参赛者可见',
+    `answerer_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '回复者的用户id',
+    `answer_time` timestamp(6) NULL DEFAULT NULL COMMENT '回复时间，精确到微秒，用于判断是否已读',
+    `create_time` timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '提问时间，精确到微秒，用于判断是否已读',
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_clarification_id` (`clarification_id`),
+    KEY `idx_contest_user` (`contest_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `clarification_read`;
+CREATE TABLE `clarification_read` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `user_id` bigint(20) NOT NULL COMMENT '用户id',
+    `read_time` timestamp(6) NOT NULL COMMENT '最后一次查看答疑的时间，精确到微秒',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_user` (`contest_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `contest_virtual`;
+CREATE TABLE `contest_virtual` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `user_id` bigint(20) NOT NULL COMMENT '用户id',
+    `start_time` timestamp NOT NULL COMMENT '虚拟参赛开始时间',
+    `end_time` timestamp NOT NULL COMMENT '虚拟参赛结束时间',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_user` (`contest_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `team`;
+CREATE TABLE `team` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `team_id` bigint(20) unsigned NOT NULL COMMENT '队伍id',
+    `name` varchar(64) COLLATE utf8mb4_general_ci NOT NULL COMMENT '队名',
+    `captain_id` bigint(20) NOT NULL COMMENT '队长的用户id',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_team_id` (`team_id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `team_member`;
+CREATE TABLE `team_member` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `team_id` bigint(20) unsigned NOT NULL COMMENT '队伍id',
+    `user_id` bigint(20) NOT NULL COMMENT '队员的用户id',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_team_user` (`team_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `team_invitation`;
+CREATE TABLE `team_invitation` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `invitation_id` bigint(20) unsigned NOT NULL COMMENT '邀请id',
+    `team_id` bigint(20) unsigned NOT NULL COMMENT '队伍id',
+    `user_id` bigint(20) NOT NULL COMMENT '被邀请者的用户id',
+    `inviter_id` bigint(20) NOT NULL COMMENT '邀请者的用户id',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'pending' COMMENT '状态 pending/accepted/declined',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_invitation_id` (`invitation_id`),
+    KEY `idx_user_status` (`user_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `contest_team`;
+CREATE TABLE `contest_team` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `team_id` bigint(20) unsigned NOT NULL COMMENT '队伍id',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '报名时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_team` (`contest_id`, `team_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `rating_change`;
+CREATE TABLE `rating_change` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `contest_id` bigint(20) unsigned NOT NULL COMMENT '比赛id',
+    `user_id` bigint(20) NOT NULL COMMENT '用户id',
+    `contest_rank` int(11) NOT NULL COMMENT '计分参赛者中的名次',
+    `old_rating` int(11) NOT NULL COMMENT '赛前等级分',
+    `new_rating` int(11) NOT NULL COMMENT '赛后等级分',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_contest_user` (`contest_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `plagiarism_report`;
+CREATE TABLE `plagiarism_report` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `report_id` bigint(20) unsigned NOT NULL COMMENT '查重任务id',
+    `user_id` bigint(20) NOT NULL COMMENT '发起查重的管理员',
+    `contest_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '比赛id，0表示不限',
+    `problem_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '题目id，0表示不限',
+    `status` varchar(16) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'running' COMMENT '状态 running/finished/failed',
+    `compared` int(11) NOT NULL DEFAULT '0' COMMENT '比较过的代码对数',
+    `message` varchar(256) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '失败原因',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_report_id` (`report_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `plagiarism_pair`;
+CREATE TABLE `plagiarism_pair` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `pair_id` bigint(20) unsigned NOT NULL COMMENT '提交对id',
+    `report_id` bigint(20) unsigned NOT NULL COMMENT '查重任务id',
+    `problem_id` bigint(20) unsigned NOT NULL COMMENT '题目id',
+    `language` varchar(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '语言',
+    `submission_a` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `user_a` bigint(20) NOT NULL COMMENT '提交者',
+    `submission_b` bigint(20) unsigned NOT NULL COMMENT '提交id',
+    `user_b` bigint(20) NOT NULL COMMENT '提交者',
+    `similarity` double NOT NULL COMMENT '相似度 0~1',
+    `matches` text COLLATE utf8mb4_general_ci NOT NULL COMMENT '相同片段的行号范围(JSON)',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_pair_id` (`pair_id`),
+    KEY `idx_report_similarity` (`report_id`, `similarity`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `problem_solution`;
+CREATE TABLE `problem_solution` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `tag` varchar(32) COLLATE utf8mb4_general_ci NOT NULL DEFAULT 'main' COMMENT '标记，如 main/accepted/wrong-answer',
+    `language` varchar(32) COLLATE utf8mb4_general_ci NOT NULL COMMENT '语言',
+    `source` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '源代码',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_problem_id` (`problem_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `problem_revision`;
+CREATE TABLE `problem_revision` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `version` int(11) NOT NULL COMMENT '版本号，从1开始递增',
+    `editor_id` bigint(20) NOT NULL COMMENT '修改者的用户id',
+    `title` varchar(128) COLLATE utf8mb4_general_ci NOT NULL COMMENT '标题',
+    `content` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '内容',
+    `statement` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '结构化题面(JSON)',
+    `time_limit` int(11) NOT NULL COMMENT '时间限制(毫秒)',
+    `memory_limit` int(11) NOT NULL COMMENT '内存限制(MB)',
+    `difficulty` int(11) NOT NULL DEFAULT '0' COMMENT '难度',
+    `note` varchar(256) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '说明，如回滚来源',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_version` (`problem_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `problem_translation`;
+CREATE TABLE `problem_translation` (
+    `id` bigint(20) NOT NULL AUTO_INCREMENT,
+    `problem_id` bigint(20) NOT NULL COMMENT '题目id',
+    `locale` varchar(16) COLLATE utf8mb4_general_ci NOT NULL COMMENT '译文语言',
+    `title` varchar(128) COLLATE utf8mb4_general_ci NOT NULL COMMENT '标题',
+    `content` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '由译文题面拼接的完整内容',
+    `statement` mediumtext COLLATE utf8mb4_general_ci NOT NULL COMMENT '结构化题面(JSON)',
+    `editor_id` bigint(20) NOT NULL COMMENT '最后修改者的用户id',
+    `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_problem_locale` (`problem_id`, `locale`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
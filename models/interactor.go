@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Interactor 交互题的交互器，设置后题目按交互方式评测
+type Interactor struct {
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Language   string    `json:"language" db:"language"`
+	Source     string    `json:"source" db:"source"`
+	UpdateTime time.Time `json:"update_time" db:"update_time"`
+}
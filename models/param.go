@@ -1,13 +1,198 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 const (
-	OrderTime  = "time"
-	OrderScore = "score"
+	OrderTime       = "time"       // 按发布时间
+	OrderScore      = "score"      // 按通过人数
+	OrderAcceptance = "acceptance" // 按通过率
 )
 
 type ParamProblemList struct {
-	CommunityID uint64 `json:"community_id" form:"community_id"`   // 可以为空
-	Page        int64  `json:"page" form:"page"`                   // 页码
-	Size        int64  `json:"size" form:"size"`                   // 每页数量
-	Order       string `json:"order" form:"order" example:"score"` // 排序依据
+	CommunityID   uint64 `json:"community_id" form:"community_id"`                                                   // 可以为空
+	Page          int64  `json:"page" form:"page"`                                                                   // 页码
+	Size          int64  `json:"size" form:"size"`                                                                   // 每页数量
+	Order         string `json:"order" form:"order" binding:"omitempty,oneof=time score acceptance" example:"score"` // 排序依据
+	Tags          string `json:"tags" form:"tags" example:"dp,graph"`                                                // 逗号分隔的标签名，须全部包含
+	DifficultyMin int64  `json:"difficulty_min" form:"difficulty_min" binding:"min=0"`                               // 难度下限，0表示不限
+	DifficultyMax int64  `json:"difficulty_max" form:"difficulty_max" binding:"min=0"`                               // 难度上限，0表示不限
+}
+
+// TagNames 解析逗号分隔的标签名
+func (p *ParamProblemList) TagNames() []string {
+	return UniqueTagNames(strings.Split(p.Tags, ","))
+}
+
+// UniqueTagNames 去除标签名的空白和重复项
+func UniqueTagNames(tags []string) []string {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, name := range tags {
+		name = strings.TrimSpace(name)
+		// 标签名比较不区分大小写，与数据库排序规则一致
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// 搜索类型
+const (
+	SearchProblem = "problem"
+	SearchAnswer  = "answer"
+)
+
+// ParamSearch 关键词搜索参数，type 为空时搜索题目
+type ParamSearch struct {
+	Q    string `json:"q" form:"q" binding:"required,max=100"`
+	Type string `json:"type" form:"type" binding:"omitempty,oneof=problem answer"`
+	Page int64  `json:"page" form:"page"` // 页码
+	Size int64  `json:"size" form:"size"` // 每页数量
+}
+
+// ParamProblemImport 导入题目包参数，文件通过表单字段 file 上传
+type ParamProblemImport struct {
+	CommunityID uint64 `form:"community_id" binding:"required"`
+}
+
+// ParamRevisionDiff 比较题目的两个版本
+type ParamRevisionDiff struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+// ParamProblemRollback 回滚题目到指定版本
+type ParamProblemRollback struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// ParamProblemTranslation 新增或修改题面译文
+type ParamProblemTranslation struct {
+	Title     string     `json:"title" binding:"required,max=128"`
+	Statement *Statement `json:"statement" binding:"required"`
+}
+
+// ParamTag 创建或修改标签参数
+type ParamTag struct {
+	Name string `json:"name" binding:"required,max=32"`
+}
+
+// ParamSubmit 提交代码参数
+type ParamSubmit struct {
+	ProblemID uint64 `json:"problem_id,string" binding:"required"`
+	ContestID uint64 `json:"contest_id,string"` // 在比赛中提交时指定
+	Language  string `json:"language" binding:"required"`
+	Source    string `json:"source" binding:"required"`
+}
+
+// ParamSubmissionList 提交记录列表查询参数
+type ParamSubmissionList struct {
+	UserID    uint64 `json:"user_id" form:"user_id"`       // 可以为空
+	ProblemID uint64 `json:"problem_id" form:"problem_id"` // 可以为空
+	ContestID uint64 `json:"contest_id" form:"contest_id"` // 可以为空
+	Status    string `json:"status" form:"status"`         // 可以为空
+	Page      int64  `json:"page" form:"page"`             // 页码
+	Size      int64  `json:"size" form:"size"`             // 每页数量
+}
+
+// ParamChecker 设置题目checker参数，type 为 custom 时需要提供 language 和 source
+type ParamChecker struct {
+	Type     string  `json:"type" binding:"required,oneof=exact line token float custom"`
+	Epsilon  float64 `json:"epsilon" binding:"gte=0"`
+	Language string  `json:"language"`
+	Source   string  `json:"source"`
+}
+
+// ParamInteractor 设置题目交互器参数
+type ParamInteractor struct {
+	Language string `json:"language" binding:"required"`
+	Source   string `json:"source" binding:"required"`
+}
+
+// ParamSubtask 子任务参数，编号按数组顺序从1开始
+type ParamSubtask struct {
+	Score        float64 `json:"score" binding:"gte=0"`
+	Type         string  `json:"type" binding:"required,oneof=min sum"`
+	Cases        []int   `json:"cases" binding:"required,min=1"`
+	Dependencies []int   `json:"dependencies"`
+}
+
+// ParamSubtasks 替换题目的全部子任务，为空表示取消子任务
+type ParamSubtasks struct {
+	Subtasks []*ParamSubtask `json:"subtasks" binding:"dive"`
+}
+
+// ParamRejudge 重新评测参数，各条件同时生效，至少指定一个
+type ParamRejudge struct {
+	ProblemID     uint64    `json:"problem_id,string"`
+	UserID        uint64    `json:"user_id,string"`
+	SubmissionIDs []string  `json:"submission_ids"` // 提交id超出js整数精度，使用字符串
+	StartTime     time.Time `json:"start_time"`     // 提交时间范围 [start_time, end_time)
+	EndTime       time.Time `json:"end_time"`
+}
+
+// ParamUserRole 设置用户角色参数
+type ParamUserRole struct {
+	Role string `json:"role" binding:"required,oneof=user setter moderator admin"`
+}
+
+// ParamContestProblem 比赛题目参数
+type ParamContestProblem struct {
+	ProblemID uint64 `json:"problem_id,string" binding:"required"`
+	Label     string `json:"label" binding:"required,max=8"`
+}
+
+// ParamContest 创建比赛参数
+type ParamContest struct {
+	Title       string                 `json:"title" binding:"required"`
+	Description string                 `json:"description"`
+	Rule        string                 `json:"rule" binding:"required,oneof=acm oi ioi"`
+	Visibility  string                 `json:"visibility" binding:"required,oneof=public private"`
+	Password    string                 `json:"password" binding:"required_if=Visibility private,max=72"`
+	StartTime   time.Time              `json:"start_time" binding:"required"`
+	Duration    int64                  `json:"duration" binding:"required,gt=0"`         // 分钟
+	Freeze      int64                  `json:"freeze" binding:"min=0,ltefield=Duration"` // 结束前多少分钟封榜
+	Rated       bool                   `json:"rated"`                                    // 是否计算等级分
+	Problems    []*ParamContestProblem `json:"problems" binding:"required,min=1,dive"`
+}
+
+// ParamContestRegister 报名参数，私有比赛需要密码，指定 team_id 时由队长为整支队伍报名
+type ParamContestRegister struct {
+	Password string `json:"password"`
+	TeamID   uint64 `json:"team_id,string"`
+}
+
+// ParamClarification 参赛者提问，problem_label 为空表示针对整场比赛
+type ParamClarification struct {
+	ProblemLabel string `json:"problem_label"`
+	Question     string `json:"question" binding:"required,max=2000"`
+}
+
+// ParamClarificationAnswer 回复答疑，public 为 true 时广播给所有参赛者
+type ParamClarificationAnswer struct {
+	Answer string `json:"answer" binding:"required,max=2000"`
+	Public bool   `json:"public"`
+}
+
+// ParamTeam 创建队伍参数
+type ParamTeam struct {
+	Name string `json:"name" binding:"required,max=32"`
+}
+
+// ParamTeamInvite 邀请用户加入队伍
+type ParamTeamInvite struct {
+	UserID uint64 `json:"user_id,string" binding:"required"`
+}
+
+// ParamPlagiarism 发起查重，比赛和题目至少指定一个
+type ParamPlagiarism struct {
+	ContestID uint64 `json:"contest_id,string"`
+	ProblemID uint64 `json:"problem_id,string"`
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 查重任务状态
+const (
+	PlagiarismRunning  = "running"  // 正在比较
+	PlagiarismFinished = "finished" // 已完成
+	PlagiarismFailed   = "failed"   // 失败，原因见 Message
+)
+
+// PlagiarismReport 一次查重任务，范围为一场比赛或一道题目，两者都指定时取交集
+type PlagiarismReport struct {
+	ReportID   uint64    `json:"report_id,string" db:"report_id"`
+	UserID     uint64    `json:"user_id,string" db:"user_id"` // 发起查重的管理员
+	ContestID  uint64    `json:"contest_id,string" db:"contest_id"`
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Status     string    `json:"status" db:"status"`
+	Compared   int64     `json:"compared" db:"compared"` // 比较过的代码对数
+	Message    string    `json:"message,omitempty" db:"message"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// PlagiarismMatch 两份代码中相同的片段，行号从1开始，包含两端
+type PlagiarismMatch struct {
+	AStartLine int `json:"a_start_line"`
+	AEndLine   int `json:"a_end_line"`
+	BStartLine int `json:"b_start_line"`
+	BEndLine   int `json:"b_end_line"`
+}
+
+// PlagiarismMatches 以 JSON 保存在数据库中
+type PlagiarismMatches []PlagiarismMatch
+
+// Value 实现 driver.Valuer 接口
+func (m PlagiarismMatches) Value() (driver.Value, error) {
+	if m == nil {
+		m = PlagiarismMatches{}
+	}
+	data, err := json.Marshal(m)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *PlagiarismMatches) Scan(src interface{}) error {
+	*m = PlagiarismMatches{}
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into PlagiarismMatches", src)
+	}
+}
+
+// PlagiarismPair 相似度较高的一对提交，来自同一道题、同一种语言的不同用户
+type PlagiarismPair struct {
+	PairID      uint64            `json:"pair_id,string" db:"pair_id"`
+	ReportID    uint64            `json:"report_id,string" db:"report_id"`
+	ProblemID   uint64            `json:"problem_id,string" db:"problem_id"`
+	Language    string            `json:"language" db:"language"`
+	SubmissionA uint64            `json:"submission_a,string" db:"submission_a"`
+	UserA       uint64            `json:"user_a,string" db:"user_a"`
+	SubmissionB uint64            `json:"submission_b,string" db:"submission_b"`
+	UserB       uint64            `json:"user_b,string" db:"user_b"`
+	Similarity  float64           `json:"similarity" db:"similarity"` // 0~1
+	Matches     PlagiarismMatches `json:"matches,omitempty" db:"matches"`
+}
+
+// ApiPlagiarismReport 查重报告，按相似度从高到低列出可疑的提交对
+type ApiPlagiarismReport struct {
+	*PlagiarismReport
+	Pairs []*PlagiarismPair `json:"pairs"`
+}
+
+// ApiPlagiarismPair 可疑提交对的对比，包含两份源代码及相同片段，供并排高亮展示
+type ApiPlagiarismPair struct {
+	*PlagiarismPair
+	SourceA string `json:"source_a"`
+	SourceB string `json:"source_b"`
+}
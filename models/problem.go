@@ -4,57 +4,138 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+	"unicode/utf8"
 )
 
+// 未指定时使用的默认资源限制
+const (
+	DefaultTimeLimit   = 1000 // 毫秒
+	DefaultMemoryLimit = 256  // MB
+)
+
+// MaxContentLength 拼接后的题面最大长度(字符数)
+const MaxContentLength = 65536
+
+// MaxDifficulty 难度上限，与等级分同一量级，0表示未评定
+const MaxDifficulty = 4000
+
 // 内存对齐概念 字段类型相同的对齐 缩小变量所占内存大小
 type Problem struct {
 	ProblemID   uint64    `json:"problem_id,string" db:"problem_id"`
 	AuthorId    uint64    `json:"author_id" db:"author_id"`
 	CommunityID uint64    `json:"community_id" db:"community_id" binding:"required"`
+	TimeLimit   int64     `json:"time_limit" db:"time_limit"`     // 时间限制(毫秒)
+	MemoryLimit int64     `json:"memory_limit" db:"memory_limit"` // 内存限制(MB)
+	Difficulty  int64     `json:"difficulty" db:"difficulty"`     // 难度，0表示未评定
 	Status      int32     `json:"status" db:"status"`
 	Title       string    `json:"title" db:"title" binding:"required"`
-	Content     string    `json:"content" db:"content" binding:"required"`
-	Input       string    `json:"input" db:"input" binding:"required"`
-	Output      string    `json:"output" db:"output" binding:"required"`
+	Content     string    `json:"content" db:"content" binding:"required"` // 由结构化题面拼接的完整 Markdown
+	Statement   Statement `json:"statement" db:"statement"`
+	Locale      string    `json:"locale" db:"locale"` // 题面语言，译文生效时为译文的语言
+	Tags        []string  `json:"tags" db:"-"`        // 标签名，创建和修改时可指定
 	CreateTime  time.Time `json:"-" db:"create_time"`
 }
 
+// ProblemListItem 题目列表中的一项，附带提交统计
+type ProblemListItem struct {
+	Problem
+	ProblemStat
+}
+
+// ProblemStat 题目的提交统计
+type ProblemStat struct {
+	SubmitNum   int64 `json:"submit_num" db:"submit_num"`
+	AcceptedNum int64 `json:"accepted_num" db:"accepted_num"`
+	SolvedNum   int64 `json:"solved_num" db:"solved_num"` // 通过的用户数
+}
+
 // UnmarshalJSON 为Post类型实现自定义的UnmarshalJSON方法
 func (p *Problem) UnmarshalJSON(data []byte) (err error) {
 	required := struct {
-		Title       string `json:"title" db:"title"`
-		Content     string `json:"content" db:"content"`
-		CommunityID int64  `json:"community_id" db:"community_id"`
-		InPut       string `json:"input" db:"input"`
-		Output      string `json:"output" db:"output"`
+		Title       string     `json:"title" db:"title"`
+		Content     string     `json:"content" db:"content"`
+		CommunityID int64      `json:"community_id" db:"community_id"`
+		TimeLimit   int64      `json:"time_limit" db:"time_limit"`
+		MemoryLimit int64      `json:"memory_limit" db:"memory_limit"`
+		Difficulty  int64      `json:"difficulty"`
+		Tags        []string   `json:"tags"`
+		Statement   *Statement `json:"statement"`
+		Locale      string     `json:"locale"`
 	}{}
 	err = json.Unmarshal(data, &required)
 	if err != nil {
 		return
 	} else if len(required.Title) == 0 {
 		err = errors.New("标题不能为空")
-	} else if len(required.Content) == 0 {
+	} else if required.Statement == nil && len(required.Content) == 0 {
 		err = errors.New("内容不能为空")
+	} else if required.Statement != nil && required.Statement.Validate() != nil {
+		err = required.Statement.Validate()
+	} else if required.Locale != "" && !IsSupportedLocale(required.Locale) {
+		err = errors.New("不支持的题面语言")
 	} else if required.CommunityID == 0 {
 		err = errors.New("未指定版块")
-	} else if required.CommunityID == 0 {
-		err = errors.New("未指定输入")
-	} else if required.CommunityID == 0 {
-		err = errors.New("未指定输出")
+	} else if required.TimeLimit < 0 || required.MemoryLimit < 0 {
+		err = errors.New("资源限制不能为负数")
+	} else if required.Difficulty < 0 || required.Difficulty > MaxDifficulty {
+		err = errors.New("难度超出范围")
 	} else {
 		p.Title = required.Title
-		p.Content = required.Content
+		// 只提交 content 时作为题目描述，兼容非结构化的题面
+		if required.Statement != nil {
+			p.Statement = *required.Statement
+		} else {
+			p.Statement = Statement{Description: required.Content}
+		}
+		if p.Statement.Samples == nil {
+			p.Statement.Samples = []*Sample{}
+		}
+		p.Content = p.Statement.Markdown()
 		p.CommunityID = uint64(required.CommunityID)
-		p.Input = required.InPut
-		p.Output = required.Output
+		p.TimeLimit = required.TimeLimit
+		p.MemoryLimit = required.MemoryLimit
+		p.Difficulty = required.Difficulty
+		p.Tags = required.Tags
+		p.Locale = required.Locale
+		if p.Locale == "" {
+			p.Locale = DefaultLocale
+		}
+	}
+	if err == nil && utf8.RuneCountInString(p.Content) > MaxContentLength {
+		err = errors.New("题面过长")
 	}
 	return
 }
 
+// EnsureStatement 早于结构化题面创建的题目，把原有内容作为题目描述，未记录语言的视为默认语言
+func (p *Problem) EnsureStatement() {
+	if p.Statement.IsEmpty() {
+		p.Statement.Description = p.Content
+	}
+	if p.Locale == "" {
+		p.Locale = DefaultLocale
+	}
+}
+
 type ApiProblemDetail struct {
 	*Problem                            // 嵌入问题结构体
 	*CommunityDetail `json:"community"` // 嵌入社区信息
 	AuthorName       string             `json:"author_name"`
 	VoteNum          int64              `json:"vote_num"`
+	ProblemStat                         // 提交统计
+	Rendered         *RenderedStatement `json:"rendered,omitempty"` // 渲染后的题面，只在详情中返回
+	OriginalLocale   string             `json:"original_locale,omitempty"`
+	Locales          []string           `json:"locales,omitempty"` // 可选的题面语言，原始语言在前
 	//CommunityName string `json:"community_name"`
 }
+
+// ProblemViewer 查看题目的用户。尚未开始的比赛中的题目只有题目作者、比赛创建者和管理者可见
+type ProblemViewer struct {
+	UserID uint64 // 游客为0
+	Role   string
+}
+
+// SeesAll 管理员和版主可以看到所有题目
+func (v *ProblemViewer) SeesAll() bool {
+	return IsManagerRole(v.Role)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RatingChange 用户在一场计分比赛后的等级分变化
+type RatingChange struct {
+	ContestID    uint64    `json:"contest_id,string" db:"contest_id"`
+	ContestTitle string    `json:"contest_title" db:"contest_title"`
+	UserID       uint64    `json:"user_id,string" db:"user_id"`
+	Rank         int       `json:"rank" db:"contest_rank"`
+	OldRating    int       `json:"old_rating" db:"old_rating"`
+	NewRating    int       `json:"new_rating" db:"new_rating"`
+	Delta        int       `json:"delta" db:"-"`
+	CreateTime   time.Time `json:"create_time" db:"create_time"`
+}
+
+// ApiUserRating 用户当前等级分、称号及历史变化
+type ApiUserRating struct {
+	UserID    uint64          `json:"user_id,string"`
+	Rating    int             `json:"rating"`
+	MaxRating int             `json:"max_rating"`
+	Title     string          `json:"title"`
+	History   []*RatingChange `json:"history"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Rejudge 一次重新评测
+type Rejudge struct {
+	RejudgeID  uint64    `json:"rejudge_id,string" db:"rejudge_id"`
+	UserID     uint64    `json:"user_id,string" db:"user_id"` // 发起重新评测的管理员
+	Total      int64     `json:"total" db:"total"`
+	Finished   int64     `json:"finished" db:"finished"` // 已评测完成的数量
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// SubmissionHistory 重新评测前的评测结果
+type SubmissionHistory struct {
+	SubmissionID uint64    `json:"submission_id,string" db:"submission_id"`
+	RejudgeID    uint64    `json:"rejudge_id,string" db:"rejudge_id"`
+	Status       string    `json:"status" db:"status"`
+	Score        float64   `json:"score" db:"score"`
+	TimeMs       int64     `json:"time_ms" db:"time_ms"`
+	MemoryKb     int64     `json:"memory_kb" db:"memory_kb"`
+	Message      string    `json:"message,omitempty" db:"message"`
+	CreateTime   time.Time `json:"create_time" db:"create_time"` // 重新评测的时间
+}
@@ -0,0 +1,79 @@
+package models
+
+// 滚榜事件类型，导出格式参考 CLICS Contest API 的 event feed，可直接交给 ICPC Tools Resolver 回放
+const (
+	FeedContests       = "contests"
+	FeedJudgementTypes = "judgement-types"
+	FeedProblems       = "problems"
+	FeedTeams          = "teams"
+	FeedSubmissions    = "submissions"
+	FeedJudgements     = "judgements"
+	FeedState          = "state"
+)
+
+// FeedEvent event feed 中的一条事件，每行一个 JSON
+type FeedEvent struct {
+	Type string      `json:"type"`
+	ID   string      `json:"id,omitempty"`
+	Op   string      `json:"op"`
+	Data interface{} `json:"data"`
+}
+
+// 时间均为 CLICS 格式：绝对时间为 RFC3339(毫秒)，相对时间为 h:mm:ss.sss
+
+type FeedContest struct {
+	ID                       string `json:"id"`
+	Name                     string `json:"name"`
+	FormalName               string `json:"formal_name"`
+	StartTime                string `json:"start_time"`
+	Duration                 string `json:"duration"`
+	ScoreboardFreezeDuration string `json:"scoreboard_freeze_duration,omitempty"`
+	PenaltyTime              int64  `json:"penalty_time"`
+}
+
+type FeedJudgementType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Penalty bool   `json:"penalty"`
+	Solved  bool   `json:"solved"`
+}
+
+type FeedProblem struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Name    string `json:"name"`
+	Ordinal int    `json:"ordinal"`
+}
+
+type FeedTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type FeedSubmission struct {
+	ID          string `json:"id"`
+	LanguageID  string `json:"language_id"`
+	ProblemID   string `json:"problem_id"`
+	TeamID      string `json:"team_id"`
+	Time        string `json:"time"`
+	ContestTime string `json:"contest_time"`
+}
+
+type FeedJudgement struct {
+	ID               string `json:"id"`
+	SubmissionID     string `json:"submission_id"`
+	JudgementTypeID  string `json:"judgement_type_id"`
+	StartTime        string `json:"start_time"`
+	StartContestTime string `json:"start_contest_time"`
+	EndTime          string `json:"end_time"`
+	EndContestTime   string `json:"end_contest_time"`
+}
+
+// FeedStateData 比赛状态，尚未发生的为 null
+type FeedStateData struct {
+	Started   *string `json:"started"`
+	Frozen    *string `json:"frozen"`
+	Ended     *string `json:"ended"`
+	Thawed    *string `json:"thawed"`
+	Finalized *string `json:"finalized"`
+}
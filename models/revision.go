@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProblemRevision 题目的一个历史版本，保存修改后的完整题面和限制
+type ProblemRevision struct {
+	ProblemID   uint64     `json:"problem_id,string" db:"problem_id"`
+	Version     int        `json:"version" db:"version"`
+	EditorID    uint64     `json:"editor_id,string" db:"editor_id"`
+	EditorName  string     `json:"editor_name" db:"username"`
+	Title       string     `json:"title" db:"title"`
+	Content     string     `json:"content,omitempty" db:"content"` // 列表中不返回
+	Statement   *Statement `json:"statement,omitempty" db:"statement"`
+	TimeLimit   int64      `json:"time_limit" db:"time_limit"`
+	MemoryLimit int64      `json:"memory_limit" db:"memory_limit"`
+	Difficulty  int64      `json:"difficulty" db:"difficulty"`
+	Note        string     `json:"note" db:"note"`
+	CreateTime  time.Time  `json:"create_time" db:"create_time"`
+}
+
+// Text 把版本内容展开为文本，用于比较两个版本
+func (r *ProblemRevision) Text() string {
+	return fmt.Sprintf("标题: %s\n时间限制: %d ms\n内存限制: %d MB\n难度: %d\n\n%s\n",
+		r.Title, r.TimeLimit, r.MemoryLimit, r.Difficulty, r.Content)
+}
+
+// ApiRevisionDiff 两个版本之间的 unified diff
+type ApiRevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"` // 没有差异时为空
+}
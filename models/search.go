@@ -0,0 +1,26 @@
+package models
+
+// SearchHit 全文检索命中的一条记录，Score 为MySQL计算的相关度
+type SearchHit struct {
+	ID        uint64  `db:"id"`
+	ProblemID uint64  `db:"problem_id"`
+	Title     string  `db:"title"`
+	Content   string  `db:"content"`
+	Score     float64 `db:"score"`
+}
+
+// ApiSearchResult 搜索结果，标题和摘要中的关键词用 <em> 标出
+type ApiSearchResult struct {
+	Type      string  `json:"type"`
+	ID        uint64  `json:"id,string"` // 题目id或题解id
+	ProblemID uint64  `json:"problem_id,string"`
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"`
+	Score     float64 `json:"score"`
+}
+
+// ApiSearchPage 一页搜索结果
+type ApiSearchPage struct {
+	Total int64              `json:"total"`
+	List  []*ApiSearchResult `json:"list"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ProblemSolution 题目的参考程序，随题目包导入导出
+type ProblemSolution struct {
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Tag        string    `json:"tag" db:"tag"` // 沿用 Polygon 的标记，如 main、accepted、wrong-answer
+	Language   string    `json:"language" db:"language"`
+	Source     string    `json:"source" db:"source"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// ApiProblemImport 导入的一道题目
+type ApiProblemImport struct {
+	ProblemID uint64   `json:"problem_id,string"`
+	Title     string   `json:"title"`
+	TestNum   int      `json:"test_num"`
+	Warnings  []string `json:"warnings"` // 未能导入的内容
+}
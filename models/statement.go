@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Statement 结构化的题面，各部分均为 Markdown，公式使用 $...$ 或 $$...$$
+type Statement struct {
+	Background   string    `json:"background"`
+	Description  string    `json:"description"`
+	InputFormat  string    `json:"input_format"`
+	OutputFormat string    `json:"output_format"`
+	Samples      []*Sample `json:"samples"`
+	Notes        string    `json:"notes"`
+}
+
+// Sample 一组样例，原样展示不做 Markdown 渲染
+type Sample struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// RenderedStatement 渲染并过滤后的题面 HTML
+type RenderedStatement struct {
+	Background   string `json:"background"`
+	Description  string `json:"description"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Notes        string `json:"notes"`
+}
+
+// IsEmpty 是否没有任何内容，早于结构化题面创建的题目为空
+func (s *Statement) IsEmpty() bool {
+	return s.Background == "" && s.Description == "" && s.InputFormat == "" &&
+		s.OutputFormat == "" && len(s.Samples) == 0 && s.Notes == ""
+}
+
+// Validate 检查题面是否完整：题目描述不能为空，样例不能为 null
+func (s *Statement) Validate() error {
+	if s.Description == "" {
+		return errors.New("题目描述不能为空")
+	}
+	for _, sample := range s.Samples {
+		if sample == nil {
+			return errors.New("样例格式错误")
+		}
+	}
+	return nil
+}
+
+// Markdown 把题面拼接为一篇完整的 Markdown，保存在 content 中用于搜索、版本比较和导出。
+// 只有题目描述时不加标题，与非结构化的题目内容一致
+func (s *Statement) Markdown() string {
+	if s.Background == "" && s.InputFormat == "" && s.OutputFormat == "" &&
+		len(s.Samples) == 0 && s.Notes == "" {
+		return strings.TrimSpace(s.Description)
+	}
+	var b strings.Builder
+	section := func(title, text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			fmt.Fprintf(&b, "## %s\n\n%s\n\n", title, text)
+		}
+	}
+	section("题目背景", s.Background)
+	section("题目描述", s.Description)
+	section("输入格式", s.InputFormat)
+	section("输出格式", s.OutputFormat)
+	for i, sample := range s.Samples {
+		fmt.Fprintf(&b, "## 样例 %d\n\n输入：\n\n```\n%s\n```\n\n输出：\n\n```\n%s\n```\n\n",
+			i+1, strings.TrimRight(sample.Input, "\r\n"), strings.TrimRight(sample.Output, "\r\n"))
+	}
+	section("提示", s.Notes)
+	return strings.TrimSpace(b.String())
+}
+
+// Value 实现 driver.Valuer 接口，以JSON保存
+func (s Statement) Value() (driver.Value, error) {
+	if s.Samples == nil {
+		s.Samples = []*Sample{}
+	}
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口，空串表示没有结构化题面
+func (s *Statement) Scan(src interface{}) error {
+	*s = Statement{Samples: []*Sample{}}
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Statement", src)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, s)
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// 提交状态
+const (
+	StatusPending             = "Pending"   // 等待评测
+	StatusJudging             = "Judging"   // 评测中
+	StatusSubmitted           = "Submitted" // 已提交，OI赛制比赛结束前对参赛者隐藏结果
+	StatusAccepted            = "AC"        // 答案正确
+	StatusWrongAnswer         = "WA"        // 答案错误
+	StatusTimeLimitExceeded   = "TLE"       // 运行超时
+	StatusMemoryLimitExceeded = "MLE"       // 内存超限
+	StatusRuntimeError        = "RE"        // 运行错误
+	StatusCompileError        = "CE"        // 编译错误
+	StatusOutputLimitExceeded = "OLE"       // 输出超限
+	StatusPresentationError   = "PE"        // 格式错误
+	StatusPartialCorrect      = "PC"        // 部分正确
+	StatusSkipped             = "Skipped"   // 依赖的子任务未通过，跳过
+	StatusSystemError         = "SE"        // 系统错误
+)
+
+type Submission struct {
+	SubmissionID uint64    `json:"submission_id,string" db:"submission_id"`
+	ProblemID    uint64    `json:"problem_id,string" db:"problem_id"`
+	UserID       uint64    `json:"user_id,string" db:"user_id"`
+	ContestID    uint64    `json:"contest_id,string" db:"contest_id"` // 不属于比赛时为0
+	TimeMs       int64     `json:"time_ms" db:"time_ms"`              // 运行时间(毫秒)
+	MemoryKb     int64     `json:"memory_kb" db:"memory_kb"`          // 占用内存(KB)
+	Score        float64   `json:"score" db:"score"`
+	Language     string    `json:"language" db:"language"`
+	Status       string    `json:"status" db:"status"`
+	Source       string    `json:"source,omitempty" db:"source"` // 非本人查看时不展示源码
+	Message      string    `json:"message,omitempty" db:"message"`
+	CreateTime   time.Time `json:"create_time" db:"create_time"`
+}
+
+// SubmissionCase 提交在单个测试点上的评测结果
+type SubmissionCase struct {
+	SubmissionID uint64  `json:"-" db:"submission_id"`
+	Index        int     `json:"index" db:"case_index"`
+	Status       string  `json:"status" db:"status"`
+	Score        float64 `json:"score" db:"score"` // 得分比例，0~1
+	TimeMs       int64   `json:"time_ms" db:"time_ms"`
+	MemoryKb     int64   `json:"memory_kb" db:"memory_kb"`
+	Message      string  `json:"message,omitempty" db:"message"` // checker 输出的信息
+}
+
+// ApiSubmissionDetail 提交详情，包含各测试点及子任务的结果
+type ApiSubmissionDetail struct {
+	*Submission
+	Cases    []*SubmissionCase    `json:"cases"`
+	Subtasks []*SubmissionSubtask `json:"subtasks"`
+}
+
+// 评测进度事件类型
+const (
+	EventStatus = "status" // 状态变化，如 Pending -> Judging
+	EventCase   = "case"   // 一个测试点评测完成
+	EventResult = "result" // 评测结束，之后不会再有事件
+)
+
+// SubmissionEvent 推送给客户端的评测进度
+type SubmissionEvent struct {
+	Type         string          `json:"type"`
+	SubmissionID uint64          `json:"submission_id,string"`
+	Status       string          `json:"status"`
+	Case         *SubmissionCase `json:"case,omitempty"`
+	Result       *Submission     `json:"result,omitempty"` // 最终结果，不含源代码
+}
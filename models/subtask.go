@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 子任务得分方式
+const (
+	SubtaskMin = "min" // 取各测试点得分比例的最小值，即全部通过才得分
+	SubtaskSum = "sum" // 按各测试点得分比例平均分配
+)
+
+// FullScore 未设置子任务时题目的满分
+const FullScore = 100
+
+// IntList 以逗号分隔的整数列表形式存入数据库
+type IntList []int
+
+// Value 实现 driver.Valuer 接口
+func (l IntList) Value() (driver.Value, error) {
+	s := make([]string, 0, len(l))
+	for _, v := range l {
+		s = append(s, strconv.Itoa(v))
+	}
+	return strings.Join(s, ","), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *IntList) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into IntList", src)
+	}
+	*l = IntList{}
+	for _, field := range strings.Split(s, ",") {
+		if field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return err
+		}
+		*l = append(*l, v)
+	}
+	return nil
+}
+
+// Subtask 子任务，由若干测试点组成，可以依赖其它子任务
+type Subtask struct {
+	ProblemID    uint64  `json:"problem_id,string" db:"problem_id"`
+	Index        int     `json:"index" db:"subtask_index"`
+	Score        float64 `json:"score" db:"score"` // 子任务分值
+	Type         string  `json:"type" db:"type"`
+	Cases        IntList `json:"cases" db:"cases"`               // 包含的测试点编号
+	Dependencies IntList `json:"dependencies" db:"dependencies"` // 依赖的子任务编号，依赖没有拿满分时本子任务不得分
+}
+
+// SubmissionSubtask 提交在单个子任务上的得分
+type SubmissionSubtask struct {
+	SubmissionID uint64  `json:"-" db:"submission_id"`
+	Index        int     `json:"index" db:"subtask_index"`
+	Status       string  `json:"status" db:"status"` // 第一个未通过测试点的状态
+	Score        float64 `json:"score" db:"score"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Tag 题目标签，如 dp、graph
+type Tag struct {
+	TagID      uint64    `json:"tag_id,string" db:"tag_id"`
+	Name       string    `json:"name" db:"name"`
+	ProblemNum int64     `json:"problem_num" db:"problem_num"` // 带有该标签的题目数
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// ProblemTag 题目与标签的关联
+type ProblemTag struct {
+	ProblemID uint64 `db:"problem_id"`
+	TagID     uint64 `db:"tag_id"`
+	Name      string `db:"name"`
+}
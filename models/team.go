@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// TeamMaxMembers 队伍人数上限，含队长
+const TeamMaxMembers = 3
+
+// 邀请状态
+const (
+	InvitationPending  = "pending"  // 等待回复
+	InvitationAccepted = "accepted" // 已接受
+	InvitationDeclined = "declined" // 已拒绝
+)
+
+// Team 参加比赛的队伍，创建者为队长
+type Team struct {
+	TeamID     uint64    `json:"team_id,string" db:"team_id"`
+	Name       string    `json:"name" db:"name"`
+	CaptainID  uint64    `json:"captain_id,string" db:"captain_id"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// TeamMember 队伍成员
+type TeamMember struct {
+	TeamID     uint64    `json:"-" db:"team_id"`
+	UserID     uint64    `json:"user_id,string" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	CreateTime time.Time `json:"create_time" db:"create_time"` // 加入时间
+}
+
+// ApiTeamDetail 队伍详情
+type ApiTeamDetail struct {
+	*Team
+	Members []*TeamMember `json:"members"`
+}
+
+// TeamInvitation 队长发出的入队邀请，被邀请者接受后成为队员
+type TeamInvitation struct {
+	InvitationID uint64    `json:"invitation_id,string" db:"invitation_id"`
+	TeamID       uint64    `json:"team_id,string" db:"team_id"`
+	TeamName     string    `json:"team_name" db:"team_name"`
+	UserID       uint64    `json:"user_id,string" db:"user_id"`
+	InviterID    uint64    `json:"inviter_id,string" db:"inviter_id"`
+	Status       string    `json:"status" db:"status"`
+	CreateTime   time.Time `json:"create_time" db:"create_time"`
+}
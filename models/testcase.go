@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TestCase 测试数据元信息，文件本身保存在评测数据目录下
+type TestCase struct {
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Index      int       `json:"index" db:"case_index"` // 测试点编号，对应 N.in/N.out
+	InputSize  int64     `json:"input_size" db:"input_size"`
+	OutputSize int64     `json:"output_size" db:"output_size"`
+	InputMD5   string    `json:"input_md5" db:"input_md5"`
+	OutputMD5  string    `json:"output_md5" db:"output_md5"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
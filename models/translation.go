@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// 题面语言，与参数校验信息支持的语言一致
+const (
+	LocaleZh      = "zh"
+	LocaleEn      = "en"
+	DefaultLocale = LocaleZh
+)
+
+// IsSupportedLocale 是否为支持的题面语言
+func IsSupportedLocale(locale string) bool {
+	return locale == LocaleZh || locale == LocaleEn
+}
+
+// ProblemTranslation 题面的一种译文，与原始题面分别编辑
+type ProblemTranslation struct {
+	ProblemID  uint64    `json:"problem_id,string" db:"problem_id"`
+	Locale     string    `json:"locale" db:"locale"`
+	Title      string    `json:"title" db:"title"`
+	Content    string    `json:"content" db:"content"` // 由译文题面拼接的完整 Markdown
+	Statement  Statement `json:"statement" db:"statement"`
+	EditorID   uint64    `json:"editor_id,string" db:"editor_id"`
+	UpdateTime time.Time `json:"update_time" db:"update_time"`
+}
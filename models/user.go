@@ -5,10 +5,24 @@ import (
 	"errors"
 )
 
+// 用户角色
+const (
+	RoleUser      = "user"      // 普通用户
+	RoleSetter    = "setter"    // 出题人，可以发布题目
+	RoleModerator = "moderator" // 版主，可以修改、删除任何人的题目和题解
+	RoleAdmin     = "admin"     // 管理员，拥有全部权限
+)
+
+// IsManagerRole 是否可以管理他人发布的内容
+func IsManagerRole(role string) bool {
+	return role == RoleAdmin || role == RoleModerator
+}
+
 type User struct {
 	UserID       uint64 `json:"user_id,string" db:"user_id"`
 	UserName     string `json:"username" db:"username"`
 	Password     string `json:"password" db:"password"`
+	Role         string `json:"role" db:"role"`
 	AccessToken  string
 	RefreshToken string
 }
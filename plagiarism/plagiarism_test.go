@@ -0,0 +1,144 @@
+package plagiarism
+
+import (
+	"reflect"
+	"testing"
+)
+
+const original = `#include <stdio.h>
+
+int main(void) {
+	int n, sum = 0;
+	scanf("%d", &n);
+	for (int i = 1; i <= n; i++) {
+		if (i % 3 == 0 || i % 5 == 0) {
+			sum += i;
+		}
+	}
+	printf("%d\n", sum);
+	return 0;
+}
+`
+
+// renamed 与 original 相同，只是改了变量名、常量，加了注释和空行
+const renamed = `#include <stdio.h>
+
+/* 求 1..n 中 3 或 5 的倍数之和 */
+int main(void) {
+	int count, total = 0; // 累加结果
+
+	scanf("%d", &count);
+	for (int k = 1; k <= count; k++) {
+		// 判断是否为倍数
+		if (k % 7 == 0 || k % 11 == 0) {
+			total += k;
+		}
+	}
+	printf("%d\n", total);
+	return 0;
+}
+`
+
+const unrelated = `#include <stdio.h>
+#include <string.h>
+
+char s[1005];
+
+int main(void) {
+	scanf("%s", s);
+	int len = strlen(s);
+	int ok = 1;
+	while (len > 0 && s[len - 1] == 'x') {
+		len--;
+	}
+	for (int l = 0, r = len - 1; l < r; l++, r--) {
+		if (s[l] != s[r]) {
+			ok = 0;
+			break;
+		}
+	}
+	puts(ok ? "Yes" : "No");
+	return 0;
+}
+`
+
+func texts(tokens []Token) []string {
+	result := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, t.Text)
+	}
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		source   string
+		want     []string
+	}{
+		{"c comments and identifiers", "c", "int x = 10; // set x\n/* block\ncomment */ y += \"s\";",
+			[]string{"int", "V", "=", "N", ";", "V", "+", "=", "S", ";"}},
+		{"python hash comment", "python3", "# read input\nn = int(input())  # n\nprint(n * 2)",
+			[]string{"V", "=", "int", "(", "input", "(", ")", ")", "print", "(", "V", "*", "N", ")"}},
+		{"go raw string", "go", "fmt.Println(`a\nb`, 'c')",
+			[]string{"fmt", ".", "V", "(", "S", ",", "S", ")"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := texts(Tokenize(tt.language, tt.source)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeLines(t *testing.T) {
+	tokens := Tokenize("c", "a\n/* x\ny */ b\n\"s\ns\" c")
+	var lines []int
+	for _, tk := range tokens {
+		lines = append(lines, tk.Line)
+	}
+	if want := []int{1, 3, 4, 5}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Tokenize() lines = %v, want %v", lines, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		min     float64
+		max     float64
+		matched bool // 是否应当找到相同片段
+	}{
+		{"identical", original, original, 1, 1, true},
+		{"renamed with comments", original, renamed, 1, 1, true},
+		{"unrelated", original, unrelated, 0, 0.3, false},
+		{"too short", "int main() {}", "int main() {}", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(NewDocument("c", tt.a), NewDocument("c", tt.b))
+			if result.Similarity < tt.min || result.Similarity > tt.max {
+				t.Errorf("Compare() similarity = %v, want in [%v, %v]", result.Similarity, tt.min, tt.max)
+			}
+			if (len(result.Matches) > 0) != tt.matched {
+				t.Errorf("Compare() matches = %+v, want matches: %v", result.Matches, tt.matched)
+			}
+		})
+	}
+}
+
+// TestCompareMatchLines 相同片段的行号对应到各自的源代码。
+// 末尾的 "}" 不在任何被选中指纹的 k-gram 内，片段止于 return 所在行
+func TestCompareMatchLines(t *testing.T) {
+	result := Compare(NewDocument("c", original), NewDocument("c", renamed))
+	if len(result.Matches) != 1 {
+		t.Fatalf("Compare() matches = %+v, want one merged match", result.Matches)
+	}
+	m := result.Matches[0]
+	if m.AStartLine != 3 || m.AEndLine != 12 || m.BStartLine != 4 || m.BEndLine != 15 {
+		t.Errorf("Compare() match = %+v, want lines 3-12 and 4-15", m)
+	}
+}
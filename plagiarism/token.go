@@ -0,0 +1,128 @@
+// Package plagiarism 检测代码相似度：先把源代码规范化为记号序列(去掉注释和空白、
+// 标识符统一替换)，再用 winnowing 算法提取指纹，比较两份代码共同的指纹
+package plagiarism
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token 规范化后的记号，Line 为在源代码中的行号(从1开始)
+type Token struct {
+	Text string
+	Line int
+}
+
+// 规范化后标识符、数字、字符串统一替换为以下记号，改名、改常量不影响比较结果
+const (
+	tokenIdent  = "V"
+	tokenNumber = "N"
+	tokenString = "S"
+)
+
+var cKeywords = words(`auto break case char const continue default do double else enum extern float for goto if
+	inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while
+	bool true false class public private protected virtual template typename namespace using new delete this operator
+	friend try catch throw nullptr auto constexpr std cin cout endl printf scanf vector map set string pair queue stack
+	priority_queue sort include define`)
+
+var javaKeywords = words(`abstract boolean break byte case catch char class continue default do double else extends
+	final finally float for if implements import instanceof int interface long new package private protected public
+	return short static super switch this throw throws try void while true false null String Scanner System`)
+
+var goKeywords = words(`break case chan const continue default defer else fallthrough for func go goto if import
+	interface map package range return select struct switch type var int int64 int32 uint64 string byte rune bool
+	float64 true false nil make len cap append fmt`)
+
+var pythonKeywords = words(`and as assert break class continue def del elif else except False finally for from global
+	if import in is lambda None nonlocal not or pass raise return True try while with yield print input range len int
+	str list dict set map`)
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+// keywordsOf 语言的关键字，关键字在规范化时保留
+func keywordsOf(language string) map[string]bool {
+	switch {
+	case strings.HasPrefix(language, "python"):
+		return pythonKeywords
+	case strings.HasPrefix(language, "java"):
+		return javaKeywords
+	case strings.HasPrefix(language, "go"):
+		return goKeywords
+	default:
+		return cKeywords
+	}
+}
+
+// Tokenize 把源代码规范化为记号序列：去掉注释和空白，标识符、数字、字符串替换为统一记号，
+// 关键字和运算符保留。Python 以 # 开头注释，其余语言按 C 风格注释处理
+func Tokenize(language, source string) []Token {
+	keywords := keywordsOf(language)
+	hashComment := strings.HasPrefix(language, "python")
+	src := []rune(source)
+	tokens := make([]Token, 0, len(src)/3)
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case hashComment && c == '#', !hashComment && c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case !hashComment && c == '/' && i+1 < len(src) && src[i+1] == '*':
+			i += 2
+			for i < len(src) && !(src[i] == '*' && i+1 < len(src) && src[i+1] == '/') {
+				if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			i += 2
+		case c == '"' || c == '\'' || c == '`':
+			start := line
+			i++
+			for i < len(src) && src[i] != c {
+				if src[i] == '\\' && c != '`' {
+					i++
+				} else if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, Token{Text: tokenString, Line: start})
+		case unicode.IsDigit(c):
+			for i < len(src) && (unicode.IsLetter(src[i]) || unicode.IsDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, Token{Text: tokenNumber, Line: line})
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j]) || src[j] == '_') {
+				j++
+			}
+			word := string(src[i:j])
+			if keywords[word] {
+				tokens = append(tokens, Token{Text: word, Line: line})
+			} else {
+				tokens = append(tokens, Token{Text: tokenIdent, Line: line})
+			}
+			i = j
+		default:
+			tokens = append(tokens, Token{Text: string(c), Line: line})
+			i++
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,147 @@
+package plagiarism
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// 连续 KGram 个记号计算一个哈希，每 Window 个相邻哈希中取最小的作为指纹。
+// 长度不小于 KGram+Window-1 的相同片段一定能被检测到
+const (
+	KGram  = 12
+	Window = 8
+)
+
+// Fingerprint 指纹，Pos 为对应 k-gram 第一个记号的下标
+type Fingerprint struct {
+	Hash uint64
+	Pos  int
+}
+
+// Document 一份参与比较的代码
+type Document struct {
+	Tokens       []Token
+	Fingerprints []Fingerprint
+}
+
+// NewDocument 规范化源代码并提取指纹
+func NewDocument(language, source string) *Document {
+	tokens := Tokenize(language, source)
+	return &Document{Tokens: tokens, Fingerprints: winnow(tokens)}
+}
+
+func winnow(tokens []Token) []Fingerprint {
+	if len(tokens) < KGram {
+		return nil
+	}
+	hashes := make([]uint64, len(tokens)-KGram+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+KGram] {
+			h.Write([]byte(t.Text))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+	w := Window
+	if len(hashes) < w {
+		w = len(hashes)
+	}
+	var fps []Fingerprint
+	last := -1
+	for start := 0; start+w <= len(hashes); start++ {
+		// 窗口中取最小哈希，相同时取最右边的，同一位置只记录一次
+		min := start
+		for i := start; i < start+w; i++ {
+			if hashes[i] <= hashes[min] {
+				min = i
+			}
+		}
+		if min != last {
+			fps = append(fps, Fingerprint{Hash: hashes[min], Pos: min})
+			last = min
+		}
+	}
+	return fps
+}
+
+// Match 两份代码中相同的片段，行号从1开始，包含两端
+type Match struct {
+	AStartLine, AEndLine int
+	BStartLine, BEndLine int
+}
+
+// Result 两份代码的比较结果
+type Result struct {
+	Similarity float64 // 共同指纹数占较少一方指纹数的比例，0~1
+	Matches    []Match
+}
+
+// Compare 比较两份代码，返回相似度及相同的片段
+func Compare(a, b *Document) Result {
+	if len(a.Fingerprints) == 0 || len(b.Fingerprints) == 0 {
+		return Result{}
+	}
+	inB := make(map[uint64][]int, len(b.Fingerprints))
+	for _, fp := range b.Fingerprints {
+		inB[fp.Hash] = append(inB[fp.Hash], fp.Pos)
+	}
+	type span struct{ a, b int } // 两边 k-gram 的起始下标
+	var spans []span
+	shared := make(map[uint64]bool)
+	for _, fp := range a.Fingerprints {
+		positions, ok := inB[fp.Hash]
+		if !ok {
+			continue
+		}
+		shared[fp.Hash] = true
+		for _, pos := range positions {
+			spans = append(spans, span{fp.Pos, pos})
+		}
+	}
+	smaller := len(a.Fingerprints)
+	if len(b.Fingerprints) < smaller {
+		smaller = len(b.Fingerprints)
+	}
+	sharedA := 0
+	for _, fp := range a.Fingerprints {
+		if shared[fp.Hash] {
+			sharedA++
+		}
+	}
+	similarity := float64(sharedA) / float64(smaller)
+	if similarity > 1 {
+		similarity = 1
+	}
+
+	// 两边偏移相同且首尾重叠的片段是同一段连续的相同代码，合并为一段
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].a != spans[j].a {
+			return spans[i].a < spans[j].a
+		}
+		return spans[i].b < spans[j].b
+	})
+	type region struct{ aStart, aEnd, bStart, bEnd int } // 记号下标，左闭右开
+	var regions []region
+	byOffset := make(map[int]int) // 两边起始下标之差 -> 最近一段的下标
+	for _, s := range spans {
+		offset := s.a - s.b
+		if i, ok := byOffset[offset]; ok && s.a <= regions[i].aEnd {
+			regions[i].aEnd = s.a + KGram
+			regions[i].bEnd = s.b + KGram
+			continue
+		}
+		byOffset[offset] = len(regions)
+		regions = append(regions, region{s.a, s.a + KGram, s.b, s.b + KGram})
+	}
+	matches := make([]Match, 0, len(regions))
+	for _, r := range regions {
+		matches = append(matches, Match{
+			AStartLine: a.Tokens[r.aStart].Line,
+			AEndLine:   a.Tokens[r.aEnd-1].Line,
+			BStartLine: b.Tokens[r.bStart].Line,
+			BEndLine:   b.Tokens[r.bEnd-1].Line,
+		})
+	}
+	return Result{Similarity: similarity, Matches: matches}
+}
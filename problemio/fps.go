@@ -0,0 +1,197 @@
+package problemio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// fpsText 带单位属性的文本节点，导出时使用 CDATA
+type fpsText struct {
+	Unit string `xml:"unit,attr,omitempty"`
+	Text string `xml:",cdata"`
+}
+
+type fpsCode struct {
+	Language string `xml:"language,attr"`
+	Source   string `xml:",cdata"`
+}
+
+type fpsItem struct {
+	Title        fpsText   `xml:"title"`
+	TimeLimit    fpsText   `xml:"time_limit"`
+	MemoryLimit  fpsText   `xml:"memory_limit"`
+	Description  fpsText   `xml:"description"`
+	Input        fpsText   `xml:"input"`
+	Output       fpsText   `xml:"output"`
+	SampleInput  []fpsText `xml:"sample_input"`
+	SampleOutput []fpsText `xml:"sample_output"`
+	TestInput    []fpsText `xml:"test_input"`
+	TestOutput   []fpsText `xml:"test_output"`
+	Hint         fpsText   `xml:"hint"`
+	Source       fpsText   `xml:"source"`
+	Solutions    []fpsCode `xml:"solution"`
+	Spj          []fpsCode `xml:"spj"`
+	Images       []struct {
+		Src string `xml:"src"`
+	} `xml:"img"`
+}
+
+type fpsDocument struct {
+	XMLName   xml.Name `xml:"fps"`
+	Version   string   `xml:"version,attr"`
+	Generator struct {
+		Name string `xml:"name,attr"`
+	} `xml:"generator"`
+	Items []*fpsItem `xml:"item"`
+}
+
+// ParseFPS 解析 FreeProblemSet XML，一个文件可以包含多道题目。
+// HUSTOJ 的spj与 testlib checker 的参数顺序和返回值约定不同，不会导入
+func ParseFPS(r io.Reader) (problems []*Problem, err error) {
+	var doc fpsDocument
+	if err = xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorFormat, err)
+	}
+	if len(doc.Items) == 0 {
+		return nil, ErrorFormat
+	}
+	problems = make([]*Problem, 0, len(doc.Items))
+	for i, item := range doc.Items {
+		p := &Problem{
+			Title:        strings.TrimSpace(item.Title.Text),
+			Legend:       strings.TrimSpace(item.Description.Text),
+			InputFormat:  strings.TrimSpace(item.Input.Text),
+			OutputFormat: strings.TrimSpace(item.Output.Text),
+			Notes:        strings.TrimSpace(item.Hint.Text),
+		}
+		if p.Title == "" {
+			return nil, fmt.Errorf("%w: 第%d道题目缺少标题", ErrorFormat, i+1)
+		}
+		if p.TimeLimit, err = fpsTimeLimit(item.TimeLimit); err != nil {
+			return nil, err
+		}
+		if p.MemoryLimit, err = fpsMemoryLimit(item.MemoryLimit); err != nil {
+			return nil, err
+		}
+		if p.Samples, err = fpsTests(item.SampleInput, item.SampleOutput); err != nil {
+			return nil, err
+		}
+		if p.Tests, err = fpsTests(item.TestInput, item.TestOutput); err != nil {
+			return nil, err
+		}
+		// 部分导出的题目只有样例
+		if len(p.Tests) == 0 {
+			p.Tests = p.Samples
+		}
+		for _, s := range item.Solutions {
+			lang, ok := languageFromFPS(s.Language)
+			if !ok {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("忽略不支持的语言 %s 的参考程序", s.Language))
+				continue
+			}
+			p.Solutions = append(p.Solutions, &Solution{Tag: "accepted", Language: lang, Source: s.Source})
+		}
+		if len(p.Solutions) > 0 {
+			p.Solutions[0].Tag = "main"
+		}
+		if len(item.Spj) > 0 && strings.TrimSpace(item.Spj[0].Source) != "" {
+			p.Warnings = append(p.Warnings, "HUSTOJ spj 与 testlib checker 不兼容，未导入，请重新设置checker")
+		}
+		if len(item.Images) > 0 {
+			p.Warnings = append(p.Warnings, "题面中的图片未导入")
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+func fpsTests(inputs, outputs []fpsText) ([]*Test, error) {
+	if len(inputs) != len(outputs) {
+		return nil, fmt.Errorf("%w: 输入输出数量不一致", ErrorFormat)
+	}
+	tests := make([]*Test, 0, len(inputs))
+	for i := range inputs {
+		tests = append(tests, &Test{
+			Input:  BytesOpener([]byte(inputs[i].Text)),
+			Output: BytesOpener([]byte(outputs[i].Text)),
+		})
+	}
+	return tests, nil
+}
+
+// fpsTimeLimit 时间限制默认单位为秒，可以是小数
+func fpsTimeLimit(t fpsText) (int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(t.Text), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%w: 时间限制有误", ErrorFormat)
+	}
+	if strings.EqualFold(t.Unit, "ms") {
+		return int64(v), nil
+	}
+	return int64(v * 1000), nil
+}
+
+// fpsMemoryLimit 内存限制默认单位为MB
+func fpsMemoryLimit(t fpsText) (int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(t.Text), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%w: 内存限制有误", ErrorFormat)
+	}
+	if strings.EqualFold(t.Unit, "kb") {
+		return int64(v / 1024), nil
+	}
+	return int64(v), nil
+}
+
+// WriteFPS 把题目写为 FPS XML。自定义checker无法转换为 HUSTOJ spj，不会导出
+func WriteFPS(w io.Writer, problems ...*Problem) (err error) {
+	doc := fpsDocument{Version: "1.2"}
+	doc.Generator.Name = "LanShan"
+	for _, p := range problems {
+		item := &fpsItem{
+			Title:       fpsText{Text: p.Title},
+			TimeLimit:   fpsText{Unit: "ms", Text: strconv.FormatInt(p.TimeLimit, 10)},
+			MemoryLimit: fpsText{Unit: "mb", Text: strconv.FormatInt(p.MemoryLimit, 10)},
+			Description: fpsText{Text: p.Legend},
+			Input:       fpsText{Text: p.InputFormat},
+			Output:      fpsText{Text: p.OutputFormat},
+			Hint:        fpsText{Text: p.Notes},
+		}
+		if item.SampleInput, item.SampleOutput, err = fpsWriteTests(p.Samples); err != nil {
+			return
+		}
+		if item.TestInput, item.TestOutput, err = fpsWriteTests(p.Tests); err != nil {
+			return
+		}
+		for _, s := range p.Solutions {
+			name, _, _ := languageInfo(s.Language)
+			item.Solutions = append(item.Solutions, fpsCode{Language: name, Source: s.Source})
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(&doc)
+}
+
+func fpsWriteTests(tests []*Test) (inputs, outputs []fpsText, err error) {
+	for _, t := range tests {
+		in, err := t.Input.ReadAll()
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := t.Output.ReadAll()
+		if err != nil {
+			return nil, nil, err
+		}
+		inputs = append(inputs, fpsText{Text: string(in)})
+		outputs = append(outputs, fpsText{Text: string(out)})
+	}
+	return
+}
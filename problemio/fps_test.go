@@ -0,0 +1,116 @@
+package problemio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFPSRoundTrip(t *testing.T) {
+	want := testProblem()
+	// 自定义checker不会导出到 FPS
+	want.Checker = nil
+	second := testProblem()
+	second.Title = "A-B"
+	second.Checker = nil
+	var buf bytes.Buffer
+	if err := WriteFPS(&buf, want, second); err != nil {
+		t.Fatalf("WriteFPS() error = %v", err)
+	}
+	problems, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(problems) != 2 || problems[1].Title != "A-B" {
+		t.Fatalf("Parse() = %+v, want 2 problems", problems)
+	}
+	got := problems[0]
+	if got.Title != want.Title || got.Legend != want.Legend || got.InputFormat != want.InputFormat ||
+		got.OutputFormat != want.OutputFormat || got.Notes != want.Notes {
+		t.Errorf("statement = %+v, want %+v", got, want)
+	}
+	if got.TimeLimit != want.TimeLimit || got.MemoryLimit != want.MemoryLimit {
+		t.Errorf("limits = %d ms %d MB, want %d ms %d MB", got.TimeLimit, got.MemoryLimit, want.TimeLimit, want.MemoryLimit)
+	}
+	if g, w := readTests(t, got.Samples), readTests(t, want.Samples); !reflect.DeepEqual(g, w) {
+		t.Errorf("samples = %q, want %q", g, w)
+	}
+	if g, w := readTests(t, got.Tests), readTests(t, want.Tests); !reflect.DeepEqual(g, w) {
+		t.Errorf("tests = %q, want %q", g, w)
+	}
+	if got.Checker != nil {
+		t.Errorf("checker = %+v, want nil", got.Checker)
+	}
+	if !reflect.DeepEqual(got.Solutions, want.Solutions) {
+		t.Errorf("solutions = %+v, want %+v", got.Solutions, want.Solutions)
+	}
+}
+
+func TestParseFPS(t *testing.T) {
+	const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<fps version="1.2">
+  <generator name="HUSTOJ"/>
+  <item>
+    <title><![CDATA[ A+B ]]></title>
+    <time_limit unit="s"><![CDATA[1.5]]></time_limit>
+    <memory_limit unit="kb"><![CDATA[131072]]></memory_limit>
+    <description><![CDATA[<p>求 a+b</p>]]></description>
+    <input><![CDATA[两个整数]]></input>
+    <output><![CDATA[一个整数]]></output>
+    <sample_input><![CDATA[1 2]]></sample_input>
+    <sample_output><![CDATA[3]]></sample_output>
+    <hint><![CDATA[]]></hint>
+    <solution language="C++"><![CDATA[int main() {}]]></solution>
+    <solution language="Pascal"><![CDATA[begin end.]]></solution>
+    <solution language="Python"><![CDATA[print(sum(map(int, input().split())))]]></solution>
+    <spj language="C"><![CDATA[int main() { return 0; }]]></spj>
+    <img><src>/upload/a.png</src></img>
+  </item>
+</fps>`
+	problems, err := ParseFPS(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ParseFPS() error = %v", err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("ParseFPS() = %d problems, want 1", len(problems))
+	}
+	p := problems[0]
+	if p.Title != "A+B" || p.TimeLimit != 1500 || p.MemoryLimit != 128 {
+		t.Errorf("problem = %q %d ms %d MB, want A+B 1500 ms 128 MB", p.Title, p.TimeLimit, p.MemoryLimit)
+	}
+	// 只有样例时用样例作为测试数据
+	if got := readTests(t, p.Tests); !reflect.DeepEqual(got, []string{"1 2", "3"}) {
+		t.Errorf("tests = %q, want the sample", got)
+	}
+	want := []*Solution{
+		{Tag: "main", Language: "cpp", Source: "int main() {}"},
+		{Tag: "accepted", Language: "python3", Source: "print(sum(map(int, input().split())))"},
+	}
+	if !reflect.DeepEqual(p.Solutions, want) {
+		t.Errorf("solutions = %+v, want %+v", p.Solutions, want)
+	}
+	if p.Checker != nil || len(p.Warnings) != 3 {
+		t.Errorf("checker = %+v, warnings = %q, want spj, pascal and image skipped", p.Checker, p.Warnings)
+	}
+}
+
+func TestParseFPSInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"no items", `<fps version="1.2"></fps>`},
+		{"missing title", `<fps><item><time_limit>1</time_limit><memory_limit>128</memory_limit></item></fps>`},
+		{"bad time limit", `<fps><item><title>A</title><time_limit>x</time_limit><memory_limit>128</memory_limit></item></fps>`},
+		{"unpaired tests", `<fps><item><title>A</title><time_limit>1</time_limit><memory_limit>128</memory_limit>` +
+			`<test_input>1</test_input></item></fps>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFPS(strings.NewReader(tt.doc)); err == nil {
+				t.Errorf("ParseFPS() error = nil, want format error")
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package problemio
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 题面语言优先级
+var polygonLanguages = []string{"chinese", "english"}
+
+type polygonSource struct {
+	Path string `xml:"path,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type polygonTest struct {
+	Method string `xml:"method,attr,omitempty"`
+	Sample bool   `xml:"sample,attr,omitempty"`
+}
+
+type polygonTestset struct {
+	Name          string        `xml:"name,attr"`
+	TimeLimit     int64         `xml:"time-limit"`   // 毫秒
+	MemoryLimit   int64         `xml:"memory-limit"` // 字节
+	TestCount     int           `xml:"test-count"`
+	InputPattern  string        `xml:"input-path-pattern"`
+	AnswerPattern string        `xml:"answer-path-pattern"`
+	Tests         []polygonTest `xml:"tests>test"`
+}
+
+type polygonChecker struct {
+	Name   string         `xml:"name,attr,omitempty"`
+	Type   string         `xml:"type,attr"`
+	Source *polygonSource `xml:"source"`
+}
+
+type polygonSolution struct {
+	Tag    string        `xml:"tag,attr"`
+	Source polygonSource `xml:"source"`
+}
+
+type polygonName struct {
+	Language string `xml:"language,attr"`
+	Value    string `xml:"value,attr"`
+}
+
+type polygonProblem struct {
+	XMLName   xml.Name          `xml:"problem"`
+	ShortName string            `xml:"short-name,attr,omitempty"`
+	Names     []polygonName     `xml:"names>name"`
+	Testsets  []*polygonTestset `xml:"judging>testset"`
+	Checker   *polygonChecker   `xml:"assets>checker"`
+	Solutions []polygonSolution `xml:"assets>solutions>solution"`
+}
+
+// 常见的 testlib 标准checker与内置比较方式的对应关系，其余checker按自定义checker导入
+var polygonStdCheckers = map[string]Checker{
+	"std::fcmp.cpp":  {Type: CheckerLine},
+	"std::lcmp.cpp":  {Type: CheckerLine},
+	"std::wcmp.cpp":  {Type: CheckerToken},
+	"std::ncmp.cpp":  {Type: CheckerToken},
+	"std::icmp.cpp":  {Type: CheckerToken},
+	"std::hcmp.cpp":  {Type: CheckerToken},
+	"std::rcmp4.cpp": {Type: CheckerFloat, Epsilon: 1e-4},
+	"std::rcmp6.cpp": {Type: CheckerFloat, Epsilon: 1e-6},
+	"std::rcmp9.cpp": {Type: CheckerFloat, Epsilon: 1e-9},
+}
+
+var testlibInclude = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*include[ \t]*["<]testlib\.h[">].*$`)
+
+// polygonPackage 以 problem.xml 所在目录为根访问压缩包中的文件
+type polygonPackage struct {
+	root  string
+	files map[string]*zip.File
+}
+
+func (pkg *polygonPackage) opener(name string) (Opener, bool) {
+	f, ok := pkg.files[path.Join(pkg.root, name)]
+	if !ok {
+		return nil, false
+	}
+	return func() (io.ReadCloser, error) { return f.Open() }, true
+}
+
+// readText 读取文本文件，文件不存在时返回空串
+func (pkg *polygonPackage) readText(name string) (string, error) {
+	open, ok := pkg.opener(name)
+	if !ok {
+		return "", nil
+	}
+	data, err := open.ReadText()
+	return strings.TrimSpace(string(data)), err
+}
+
+// ParsePolygon 解析 Polygon 导出的题目包，需要包含测试答案(.a 文件)
+func ParsePolygon(r io.ReaderAt, size int64) (p *Problem, err error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrorFormat
+	}
+	pkg := &polygonPackage{files: make(map[string]*zip.File, len(zr.File))}
+	xmlName := ""
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		pkg.files[f.Name] = f
+		// 压缩包可能多包了一层目录，取层级最浅的 problem.xml
+		if path.Base(f.Name) == "problem.xml" && (xmlName == "" || len(f.Name) < len(xmlName)) {
+			xmlName = f.Name
+		}
+	}
+	pkg.root = path.Dir(xmlName)
+	if xmlName == "" {
+		return nil, fmt.Errorf("%w: 缺少 problem.xml", ErrorFormat)
+	}
+	open, _ := pkg.opener("problem.xml")
+	data, err := open.ReadText()
+	if err != nil {
+		return nil, err
+	}
+	var desc polygonProblem
+	if err = xml.Unmarshal(data, &desc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorFormat, err)
+	}
+	if len(desc.Testsets) == 0 {
+		return nil, fmt.Errorf("%w: 缺少测试数据", ErrorFormat)
+	}
+	testset := desc.Testsets[0]
+	for _, ts := range desc.Testsets {
+		if ts.Name == "tests" {
+			testset = ts
+		}
+	}
+	p = &Problem{
+		TimeLimit:   testset.TimeLimit,
+		MemoryLimit: testset.MemoryLimit >> 20,
+	}
+	if err = pkg.parseStatement(p, &desc); err != nil {
+		return nil, err
+	}
+	if err = pkg.parseTests(p, testset); err != nil {
+		return nil, err
+	}
+	if err = pkg.parseChecker(p, desc.Checker); err != nil {
+		return nil, err
+	}
+	for _, s := range desc.Solutions {
+		lang, ok := languageFromPolygon(s.Source.Type)
+		if !ok {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("忽略不支持的语言 %s 的参考程序 %s", s.Source.Type, s.Source.Path))
+			continue
+		}
+		source, err := pkg.readText(s.Source.Path)
+		if err != nil {
+			return nil, err
+		}
+		p.Solutions = append(p.Solutions, &Solution{Tag: s.Tag, Language: lang, Source: source})
+	}
+	return p, nil
+}
+
+// parseStatement 从 statement-sections 读取题面各部分，按中文、英文、其他语言的顺序选择
+func (pkg *polygonPackage) parseStatement(p *Problem, desc *polygonProblem) (err error) {
+	lang := ""
+	for _, l := range polygonLanguages {
+		if _, ok := pkg.opener(path.Join("statement-sections", l, "legend.tex")); ok {
+			lang = l
+			break
+		}
+	}
+	if lang == "" && len(desc.Names) > 0 {
+		lang = desc.Names[0].Language
+	}
+	for _, name := range desc.Names {
+		if name.Language == lang || p.Title == "" {
+			p.Title = strings.TrimSpace(name.Value)
+		}
+	}
+	if p.Title == "" {
+		p.Title = desc.ShortName
+	}
+	if p.Title == "" {
+		return fmt.Errorf("%w: 缺少题目名称", ErrorFormat)
+	}
+	dir := path.Join("statement-sections", lang)
+	if p.Legend, err = pkg.readText(path.Join(dir, "legend.tex")); err != nil {
+		return
+	}
+	if p.InputFormat, err = pkg.readText(path.Join(dir, "input.tex")); err != nil {
+		return
+	}
+	if p.OutputFormat, err = pkg.readText(path.Join(dir, "output.tex")); err != nil {
+		return
+	}
+	if p.Notes, err = pkg.readText(path.Join(dir, "notes.tex")); err != nil {
+		return
+	}
+	for i := 1; ; i++ {
+		name := path.Join(dir, fmt.Sprintf("example.%02d", i))
+		input, ok := pkg.opener(name)
+		if !ok {
+			break
+		}
+		output, ok := pkg.opener(name + ".a")
+		if !ok {
+			break
+		}
+		p.Samples = append(p.Samples, &Test{Input: input, Output: output})
+	}
+	return nil
+}
+
+// parseTests 按路径模板读取测试点，题面中没有样例时使用标记为样例的测试点
+func (pkg *polygonPackage) parseTests(p *Problem, testset *polygonTestset) error {
+	count := testset.TestCount
+	if count == 0 {
+		count = len(testset.Tests)
+	}
+	if count == 0 || testset.InputPattern == "" || testset.AnswerPattern == "" {
+		return fmt.Errorf("%w: 缺少测试数据", ErrorFormat)
+	}
+	samples := len(p.Samples) == 0
+	for i := 1; i <= count; i++ {
+		input, ok := pkg.opener(fmt.Sprintf(testset.InputPattern, i))
+		if !ok {
+			return fmt.Errorf("%w: 缺少第%d个测试点的输入", ErrorFormat, i)
+		}
+		output, ok := pkg.opener(fmt.Sprintf(testset.AnswerPattern, i))
+		if !ok {
+			return fmt.Errorf("%w: 缺少第%d个测试点的答案，请导出包含答案的完整题目包", ErrorFormat, i)
+		}
+		test := &Test{Input: input, Output: output}
+		p.Tests = append(p.Tests, test)
+		if samples && i <= len(testset.Tests) && testset.Tests[i-1].Sample {
+			p.Samples = append(p.Samples, test)
+		}
+	}
+	return nil
+}
+
+// parseChecker 标准checker转换为内置比较方式，其余作为自定义checker，
+// 并把 testlib.h 内联到源代码中，使其可以单文件编译
+func (pkg *polygonPackage) parseChecker(p *Problem, checker *polygonChecker) (err error) {
+	if checker == nil {
+		return nil
+	}
+	if std, ok := polygonStdCheckers[checker.Name]; ok {
+		p.Checker = &std
+		return nil
+	}
+	if checker.Source == nil {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("checker %s 缺少源代码，未导入", checker.Name))
+		return nil
+	}
+	lang, ok := languageFromPolygon(checker.Source.Type)
+	if !ok {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("checker 的语言 %s 不受支持，未导入", checker.Source.Type))
+		return nil
+	}
+	source, err := pkg.readText(checker.Source.Path)
+	if err != nil {
+		return err
+	}
+	if testlibInclude.MatchString(source) {
+		header, err := pkg.readText("files/testlib.h")
+		if err != nil {
+			return err
+		}
+		if header != "" {
+			source = testlibInclude.ReplaceAllLiteralString(source, header)
+		}
+	}
+	p.Checker = &Checker{Type: CheckerCustom, Language: lang, Source: source}
+	return nil
+}
+
+// polygonCheckerName 内置比较方式对应的 testlib 标准checker
+func polygonCheckerName(c *Checker) string {
+	switch c.Type {
+	case CheckerExact:
+		return "std::fcmp.cpp"
+	case CheckerToken:
+		return "std::wcmp.cpp"
+	case CheckerFloat:
+		switch {
+		case c.Epsilon >= 1e-4:
+			return "std::rcmp4.cpp"
+		case c.Epsilon <= 1e-9:
+			return "std::rcmp9.cpp"
+		}
+		return "std::rcmp6.cpp"
+	}
+	return "std::lcmp.cpp"
+}
+
+// WritePolygon 把题目写为 Polygon 题目包，题面放在 statement-sections/chinese 下
+func WritePolygon(w io.Writer, p *Problem, shortName string) (err error) {
+	zw := zip.NewWriter(w)
+	desc := polygonProblem{
+		ShortName: shortName,
+		Names:     []polygonName{{Language: polygonLanguages[0], Value: p.Title}},
+		Testsets: []*polygonTestset{{
+			Name:          "tests",
+			TimeLimit:     p.TimeLimit,
+			MemoryLimit:   p.MemoryLimit << 20,
+			TestCount:     len(p.Tests),
+			InputPattern:  "tests/%02d",
+			AnswerPattern: "tests/%02d.a",
+		}},
+	}
+	dir := path.Join("statement-sections", polygonLanguages[0])
+	sections := []struct{ name, text string }{
+		{"name.tex", p.Title},
+		{"legend.tex", p.Legend},
+		{"input.tex", p.InputFormat},
+		{"output.tex", p.OutputFormat},
+		{"notes.tex", p.Notes},
+	}
+	for _, s := range sections {
+		if s.text == "" {
+			continue
+		}
+		if err = writeZipFile(zw, path.Join(dir, s.name), BytesOpener([]byte(s.text))); err != nil {
+			return
+		}
+	}
+	for i, t := range p.Samples {
+		name := path.Join(dir, fmt.Sprintf("example.%02d", i+1))
+		if err = writeZipFile(zw, name, t.Input); err != nil {
+			return
+		}
+		if err = writeZipFile(zw, name+".a", t.Output); err != nil {
+			return
+		}
+	}
+	for i, t := range p.Tests {
+		desc.Testsets[0].Tests = append(desc.Testsets[0].Tests, polygonTest{Method: "manual"})
+		if err = writeZipFile(zw, fmt.Sprintf("tests/%02d", i+1), t.Input); err != nil {
+			return
+		}
+		if err = writeZipFile(zw, fmt.Sprintf("tests/%02d.a", i+1), t.Output); err != nil {
+			return
+		}
+	}
+	if c := p.Checker; c != nil {
+		if c.Type == CheckerCustom {
+			_, typ, ext := languageInfo(c.Language)
+			src := &polygonSource{Path: "files/check" + ext, Type: typ}
+			desc.Checker = &polygonChecker{Type: "testlib", Source: src}
+			if err = writeZipFile(zw, src.Path, BytesOpener([]byte(c.Source))); err != nil {
+				return
+			}
+		} else {
+			desc.Checker = &polygonChecker{Name: polygonCheckerName(c), Type: "testlib"}
+		}
+	}
+	for i, s := range p.Solutions {
+		_, typ, ext := languageInfo(s.Language)
+		src := polygonSource{Path: fmt.Sprintf("solutions/%s-%d%s", s.Tag, i+1, ext), Type: typ}
+		desc.Solutions = append(desc.Solutions, polygonSolution{Tag: s.Tag, Source: src})
+		if err = writeZipFile(zw, src.Path, BytesOpener([]byte(s.Source))); err != nil {
+			return
+		}
+	}
+	data, err := xml.MarshalIndent(&desc, "", "  ")
+	if err != nil {
+		return
+	}
+	data = append([]byte(xml.Header), data...)
+	if err = writeZipFile(zw, "problem.xml", BytesOpener(data)); err != nil {
+		return
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, open Opener) (err error) {
+	rc, err := open()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+	fw, err := zw.Create(name)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(fw, rc)
+	return
+}
@@ -0,0 +1,216 @@
+package problemio
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testProblem 导出测试用的题目
+func testProblem() *Problem {
+	return &Problem{
+		Title:        "A+B",
+		Legend:       "求 $a+b$",
+		InputFormat:  "一行两个整数",
+		OutputFormat: "一个整数",
+		Notes:        "$|a|,|b| \\le 10^9$",
+		TimeLimit:    1000,
+		MemoryLimit:  256,
+		Samples:      []*Test{{Input: BytesOpener([]byte("1 2\n")), Output: BytesOpener([]byte("3\n"))}},
+		Tests: []*Test{
+			{Input: BytesOpener([]byte("1 2\n")), Output: BytesOpener([]byte("3\n"))},
+			{Input: BytesOpener([]byte("-5 5\n")), Output: BytesOpener([]byte("0\n"))},
+		},
+		Checker:   &Checker{Type: CheckerCustom, Language: "cpp", Source: "#include \"testlib.h\"\nint main() {}"},
+		Solutions: []*Solution{{Tag: "main", Language: "cpp", Source: "int main() {}"}},
+	}
+}
+
+// readTests 读出全部测试点的输入和输出
+func readTests(t *testing.T, tests []*Test) []string {
+	t.Helper()
+	var result []string
+	for _, test := range tests {
+		for _, open := range []Opener{test.Input, test.Output} {
+			data, err := open.ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			result = append(result, string(data))
+		}
+	}
+	return result
+}
+
+// buildZip 按文件名和内容构造压缩包
+func buildZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		if err := writeZipFile(zw, name, BytesOpener([]byte(content))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestPolygonRoundTrip(t *testing.T) {
+	want := testProblem()
+	var buf bytes.Buffer
+	if err := WritePolygon(&buf, want, "a-plus-b"); err != nil {
+		t.Fatalf("WritePolygon() error = %v", err)
+	}
+	problems, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("Parse() = %d problems, want 1", len(problems))
+	}
+	got := problems[0]
+	if got.Title != want.Title || got.Legend != want.Legend || got.InputFormat != want.InputFormat ||
+		got.OutputFormat != want.OutputFormat || got.Notes != want.Notes {
+		t.Errorf("statement = %+v, want %+v", got, want)
+	}
+	if got.TimeLimit != want.TimeLimit || got.MemoryLimit != want.MemoryLimit {
+		t.Errorf("limits = %d ms %d MB, want %d ms %d MB", got.TimeLimit, got.MemoryLimit, want.TimeLimit, want.MemoryLimit)
+	}
+	if g, w := readTests(t, got.Samples), readTests(t, want.Samples); !reflect.DeepEqual(g, w) {
+		t.Errorf("samples = %q, want %q", g, w)
+	}
+	if g, w := readTests(t, got.Tests), readTests(t, want.Tests); !reflect.DeepEqual(g, w) {
+		t.Errorf("tests = %q, want %q", g, w)
+	}
+	if !reflect.DeepEqual(got.Checker, want.Checker) {
+		t.Errorf("checker = %+v, want %+v", got.Checker, want.Checker)
+	}
+	if !reflect.DeepEqual(got.Solutions, want.Solutions) {
+		t.Errorf("solutions = %+v, want %+v", got.Solutions, want.Solutions)
+	}
+}
+
+func TestParsePolygon(t *testing.T) {
+	const desc = `<?xml version="1.0" encoding="utf-8"?>
+<problem short-name="a-plus-b">
+  <names><name language="english" value="A Plus B"/></names>
+  <judging>
+    <testset name="pretests"><test-count>0</test-count></testset>
+    <testset name="tests">
+      <time-limit>2000</time-limit>
+      <memory-limit>268435456</memory-limit>
+      <test-count>2</test-count>
+      <input-path-pattern>tests/%02d</input-path-pattern>
+      <answer-path-pattern>tests/%02d.a</answer-path-pattern>
+      <tests><test method="manual" sample="true"/><test method="generated"/></tests>
+    </testset>
+  </judging>
+  <assets>
+    <checker type="testlib"><source path="files/check.cpp" type="cpp.g++17"/></checker>
+    <solutions>
+      <solution tag="main"><source path="solutions/main.cpp" type="cpp.g++17"/></solution>
+      <solution tag="accepted"><source path="solutions/ok.pas" type="pas.dpr"/></solution>
+    </solutions>
+  </assets>
+</problem>`
+	// 压缩包多包了一层目录，题面只有英文，没有样例文件
+	r := buildZip(t, map[string]string{
+		"a-plus-b/problem.xml":                           desc,
+		"a-plus-b/statement-sections/english/legend.tex": " Compute $a+b$. \n",
+		"a-plus-b/statement-sections/english/input.tex":  "Two integers.",
+		"a-plus-b/statement-sections/english/output.tex": "One integer.",
+		"a-plus-b/tests/01":                              "1 2\n",
+		"a-plus-b/tests/01.a":                            "3\n",
+		"a-plus-b/tests/02":                              "4 5\n",
+		"a-plus-b/tests/02.a":                            "9\n",
+		"a-plus-b/files/check.cpp":                       "#include \"testlib.h\"\nint main() {}",
+		"a-plus-b/files/testlib.h":                       "// testlib",
+		"a-plus-b/solutions/main.cpp":                    "int main() {}",
+		"a-plus-b/solutions/ok.pas":                      "begin end.",
+	})
+	p, err := ParsePolygon(r, r.Size())
+	if err != nil {
+		t.Fatalf("ParsePolygon() error = %v", err)
+	}
+	if p.Title != "A Plus B" || p.Legend != "Compute $a+b$." || p.Notes != "" {
+		t.Errorf("statement = %q %q %q", p.Title, p.Legend, p.Notes)
+	}
+	if p.TimeLimit != 2000 || p.MemoryLimit != 256 {
+		t.Errorf("limits = %d ms %d MB, want 2000 ms 256 MB", p.TimeLimit, p.MemoryLimit)
+	}
+	if got := readTests(t, p.Samples); !reflect.DeepEqual(got, []string{"1 2\n", "3\n"}) {
+		t.Errorf("samples = %q, want the test marked as sample", got)
+	}
+	if len(p.Tests) != 2 {
+		t.Errorf("tests = %d, want 2", len(p.Tests))
+	}
+	want := &Checker{Type: CheckerCustom, Language: "cpp", Source: "// testlib\nint main() {}"}
+	if !reflect.DeepEqual(p.Checker, want) {
+		t.Errorf("checker = %+v, want testlib.h inlined %+v", p.Checker, want)
+	}
+	if len(p.Solutions) != 1 || p.Solutions[0].Tag != "main" || len(p.Warnings) != 1 {
+		t.Errorf("solutions = %+v, warnings = %q, want pascal solution skipped", p.Solutions, p.Warnings)
+	}
+}
+
+func TestParsePolygonStdChecker(t *testing.T) {
+	var buf bytes.Buffer
+	p := testProblem()
+	p.Checker = &Checker{Type: CheckerFloat, Epsilon: 1e-6}
+	if err := WritePolygon(&buf, p, "a-plus-b"); err != nil {
+		t.Fatalf("WritePolygon() error = %v", err)
+	}
+	got, err := ParsePolygon(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ParsePolygon() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Checker, p.Checker) {
+		t.Errorf("checker = %+v, want %+v", got.Checker, p.Checker)
+	}
+}
+
+// TestParsePolygonTooLarge 压缩后很小、解压后超过上限的文本文件被拒绝
+func TestParsePolygonTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePolygon(&buf, testProblem(), "a-plus-b"); err != nil {
+		t.Fatalf("WritePolygon() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"statement-sections/chinese/legend.tex": strings.Repeat("a", MaxTextSize+1)}
+	for _, f := range zr.File {
+		if _, ok := files[f.Name]; ok {
+			continue
+		}
+		data, err := Opener(func() (io.ReadCloser, error) { return f.Open() }).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+	r := buildZip(t, files)
+	if r.Size() > MaxTextSize/100 {
+		t.Fatalf("zip size = %d, want a highly compressed package", r.Size())
+	}
+	if _, err := ParsePolygon(r, r.Size()); !errors.Is(err, ErrorTooLarge) {
+		t.Errorf("ParsePolygon() error = %v, want %v", err, ErrorTooLarge)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if _, err := Parse(strings.NewReader("PK\x03\x04broken"), 10); !errors.Is(err, ErrorFormat) {
+		t.Errorf("Parse(broken zip) error = %v, want %v", err, ErrorFormat)
+	}
+	if _, err := Parse(strings.NewReader("not xml"), 7); !errors.Is(err, ErrorFormat) {
+		t.Errorf("Parse(text) error = %v, want %v", err, ErrorFormat)
+	}
+}
@@ -0,0 +1,177 @@
+// Package problemio 在题目包格式(Codeforces Polygon、FreeProblemSet XML)与统一的题目描述之间转换，
+// 不涉及数据库和评测数据目录，由 service 层负责落库
+package problemio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrorFormat   = errors.New("无法识别的题目包格式")
+	ErrorTooLarge = errors.New("题目包中的文件过大")
+)
+
+// MaxTextSize 题目包中题面、样例、源代码等文本文件的大小上限，测试数据另有限制
+const MaxTextSize = 4 << 20
+
+// 导出格式
+const (
+	FormatPolygon = "polygon"
+	FormatFPS     = "fps"
+)
+
+// 标准checker类型，与 models 中的取值一致
+const (
+	CheckerExact  = "exact"
+	CheckerLine   = "line"
+	CheckerToken  = "token"
+	CheckerFloat  = "float"
+	CheckerCustom = "custom"
+)
+
+// Problem 与格式无关的题目描述
+type Problem struct {
+	Title        string
+	Legend       string // 题目描述
+	InputFormat  string
+	OutputFormat string
+	Notes        string // 提示、说明
+	TimeLimit    int64  // 毫秒
+	MemoryLimit  int64  // MB
+	Samples      []*Test
+	Tests        []*Test
+	Checker      *Checker // 为空时按默认方式比较
+	Solutions    []*Solution
+	Warnings     []string // 解析时忽略的内容，如不兼容的spj
+}
+
+// Test 一组输入输出，按需打开以免把大文件读入内存
+type Test struct {
+	Input  Opener
+	Output Opener
+}
+
+// Opener 打开一份测试数据
+type Opener func() (io.ReadCloser, error)
+
+// BytesOpener 以内存中的数据作为测试数据
+func BytesOpener(data []byte) Opener {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// ReadAll 读取全部内容，只用于本地已校验过大小的数据
+func (o Opener) ReadAll() ([]byte, error) {
+	rc, err := o()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// ReadText 读取不超过 MaxTextSize 的内容，解析上传的题目包时使用，
+// 压缩包中记录的大小不可信，按实际解压出的字节数判断
+func (o Opener) ReadText() ([]byte, error) {
+	rc, err := o()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, MaxTextSize+1))
+	if err == nil && len(data) > MaxTextSize {
+		return nil, ErrorTooLarge
+	}
+	return data, err
+}
+
+// Checker 输出比较方式，Type 为 custom 时 Source 是 testlib checker 的源代码
+type Checker struct {
+	Type     string
+	Epsilon  float64
+	Language string
+	Source   string
+}
+
+// Solution 参考程序，Tag 沿用 Polygon 的标记，如 main、accepted、wrong-answer
+type Solution struct {
+	Tag      string
+	Language string
+	Source   string
+}
+
+// Parse 根据文件内容识别格式并解析，zip 按 Polygon 包处理，其余按 FPS XML 处理
+func Parse(r io.ReaderAt, size int64) (problems []*Problem, err error) {
+	head := make([]byte, 4)
+	n, _ := r.ReadAt(head, 0)
+	if n == len(head) && string(head) == "PK\x03\x04" {
+		p, err := ParsePolygon(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return []*Problem{p}, nil
+	}
+	return ParseFPS(io.NewSectionReader(r, 0, size))
+}
+
+// 评测语言与各格式中语言名称的对应关系
+var languages = []struct {
+	name    string // 本系统的语言标识
+	fps     string
+	polygon string
+	ext     string
+}{
+	{"c", "C", "c.gcc", ".c"},
+	{"cpp", "C++", "cpp.g++17", ".cpp"},
+	{"java", "Java", "java11", ".java"},
+	{"python3", "Python", "python.3", ".py"},
+	{"go", "Go", "go", ".go"},
+}
+
+// languageFromPolygon 把 Polygon 的源代码类型(如 cpp.g++17、python.3)转换为语言标识
+func languageFromPolygon(typ string) (string, bool) {
+	switch {
+	case strings.HasPrefix(typ, "cpp."):
+		return "cpp", true
+	case strings.HasPrefix(typ, "c."):
+		return "c", true
+	case strings.HasPrefix(typ, "java"):
+		return "java", true
+	case strings.HasPrefix(typ, "python.3"), strings.HasPrefix(typ, "python.pypy3"):
+		return "python3", true
+	case typ == "go" || strings.HasPrefix(typ, "go."):
+		return "go", true
+	}
+	return "", false
+}
+
+// languageFromFPS 把 FPS 的语言名(如 C++、Python)转换为语言标识
+func languageFromFPS(name string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "c":
+		return "c", true
+	case "c++", "cpp":
+		return "cpp", true
+	case "java":
+		return "java", true
+	case "python", "python3":
+		return "python3", true
+	case "go", "golang":
+		return "go", true
+	}
+	return "", false
+}
+
+// languageInfo 按语言标识查找对应关系，未知语言按 C++ 处理
+func languageInfo(name string) (fps, polygon, ext string) {
+	for _, l := range languages {
+		if l.name == name {
+			return l.fps, l.polygon, l.ext
+		}
+	}
+	return languages[1].fps, languages[1].polygon, languages[1].ext
+}
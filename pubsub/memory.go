@@ -0,0 +1,68 @@
+package pubsub
+
+import "sync"
+
+// 订阅者处理不过来时最多缓存的消息数，超出的消息直接丢弃
+const memoryBuffer = 64
+
+// Memory 基于内存的发布订阅，只在单进程内有效，用于测试和本地开发
+type Memory struct {
+	mu   sync.Mutex
+	subs map[string]map[*memorySubscription]struct{}
+}
+
+func NewMemory() *Memory {
+	return &Memory{subs: make(map[string]map[*memorySubscription]struct{})}
+}
+
+func (m *Memory) Publish(channel string, message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for sub := range m.subs[channel] {
+		select {
+		case sub.ch <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(channel string) (Subscription, error) {
+	sub := &memorySubscription{
+		broker:  m,
+		channel: channel,
+		ch:      make(chan []byte, memoryBuffer),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subs[channel] == nil {
+		m.subs[channel] = make(map[*memorySubscription]struct{})
+	}
+	m.subs[channel][sub] = struct{}{}
+	return sub, nil
+}
+
+type memorySubscription struct {
+	broker  *Memory
+	channel string
+	ch      chan []byte
+	once    sync.Once
+}
+
+func (s *memorySubscription) Messages() <-chan []byte {
+	return s.ch
+}
+
+func (s *memorySubscription) Close() error {
+	s.once.Do(func() {
+		m := s.broker
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subs[s.channel], s)
+		if len(m.subs[s.channel]) == 0 {
+			delete(m.subs, s.channel)
+		}
+		close(s.ch)
+	})
+	return nil
+}
@@ -0,0 +1,64 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, sub Subscription) (string, bool) {
+	t.Helper()
+	select {
+	case msg, ok := <-sub.Messages():
+		return string(msg), ok
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("no message received")
+	}
+	return "", false
+}
+
+func TestMemoryPublishSubscribe(t *testing.T) {
+	m := NewMemory()
+	// 订阅前发布的消息不会收到
+	_ = m.Publish("c", []byte("before"))
+	sub, err := m.Subscribe("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := m.Subscribe("other")
+	defer other.Close()
+
+	// Subscribe 返回后订阅即生效
+	_ = m.Publish("c", []byte("1"))
+	_ = m.Publish("c", []byte("2"))
+	for _, want := range []string{"1", "2"} {
+		if got, _ := receive(t, sub); got != want {
+			t.Errorf("message = %q, want %q", got, want)
+		}
+	}
+	select {
+	case msg := <-other.Messages():
+		t.Errorf("other channel received %q", msg)
+	default:
+	}
+
+	// Close 后消息通道关闭，不再投递
+	_ = sub.Close()
+	_ = sub.Close()
+	_ = m.Publish("c", []byte("3"))
+	if _, ok := receive(t, sub); ok {
+		t.Error("Messages() not closed after Close()")
+	}
+}
+
+// TestMemorySlowSubscriber 订阅者处理不过来时丢弃多余消息，不阻塞发布者
+func TestMemorySlowSubscriber(t *testing.T) {
+	m := NewMemory()
+	sub, _ := m.Subscribe("c")
+	defer sub.Close()
+	for i := 0; i < memoryBuffer+10; i++ {
+		_ = m.Publish("c", []byte("x"))
+	}
+	if n := len(sub.Messages()); n != memoryBuffer {
+		t.Errorf("buffered %d messages, want %d", n, memoryBuffer)
+	}
+}
@@ -0,0 +1,17 @@
+// Package pubsub 发布订阅，用于把评测进度推送给所有API实例上等待结果的连接。
+// 消息只发给当前在线的订阅者，不做持久化。
+package pubsub
+
+type Broker interface {
+	// Publish 向频道发布消息
+	Publish(channel string, message []byte) error
+	// Subscribe 订阅频道，返回时订阅已经生效
+	Subscribe(channel string) (Subscription, error)
+}
+
+type Subscription interface {
+	// Messages 收到的消息，Close 后关闭
+	Messages() <-chan []byte
+	// Close 取消订阅
+	Close() error
+}
@@ -0,0 +1,118 @@
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory 基于内存的队列，只在单进程内有效，用于测试和本地开发
+type Memory struct {
+	mu         sync.Mutex
+	pending    []uint64
+	low        []uint64 // 低优先级任务
+	processing map[string][]memoryJob
+	heartbeats map[string]time.Time
+	notify     chan struct{} // 有新任务时关闭以唤醒等待者
+}
+
+// memoryJob 评测机手中的任务，记录来自哪个队列以便放回
+type memoryJob struct {
+	submissionID uint64
+	low          bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		processing: make(map[string][]memoryJob),
+		heartbeats: make(map[string]time.Time),
+		notify:     make(chan struct{}),
+	}
+}
+
+func (m *Memory) Push(submissionID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = append(m.pending, submissionID)
+	close(m.notify)
+	m.notify = make(chan struct{})
+	return nil
+}
+
+func (m *Memory) PushLow(submissionID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.low = append(m.low, submissionID)
+	close(m.notify)
+	m.notify = make(chan struct{})
+	return nil
+}
+
+func (m *Memory) Pop(worker string, timeout time.Duration) (submissionID uint64, ok bool, err error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		m.mu.Lock()
+		for i, list := range []*[]uint64{&m.pending, &m.low} {
+			if len(*list) > 0 {
+				submissionID = (*list)[0]
+				*list = (*list)[1:]
+				m.processing[worker] = append(m.processing[worker], memoryJob{submissionID, i == 1})
+				m.mu.Unlock()
+				return submissionID, true, nil
+			}
+		}
+		notify := m.notify
+		m.mu.Unlock()
+		select {
+		case <-notify:
+		case <-deadline.C:
+			return 0, false, nil
+		}
+	}
+}
+
+func (m *Memory) Ack(worker string, submissionID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := m.processing[worker]
+	for i, job := range jobs {
+		if job.submissionID == submissionID {
+			m.processing[worker] = append(jobs[:i], jobs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Heartbeat(worker string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.heartbeats[worker] = time.Now().Add(ttl)
+	return nil
+}
+
+func (m *Memory) Requeue() (n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for worker, jobs := range m.processing {
+		if expire, ok := m.heartbeats[worker]; ok && expire.After(now) {
+			continue
+		}
+		for _, job := range jobs {
+			if job.low {
+				m.low = append(m.low, job.submissionID)
+			} else {
+				m.pending = append(m.pending, job.submissionID)
+			}
+		}
+		n += len(jobs)
+		delete(m.processing, worker)
+		delete(m.heartbeats, worker)
+	}
+	if n > 0 {
+		close(m.notify)
+		m.notify = make(chan struct{})
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+// mustPop 取出一个任务，队列为空时测试失败
+func mustPop(t *testing.T, q Queue, worker string) uint64 {
+	t.Helper()
+	id, ok, err := q.Pop(worker, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Pop() error = %v", err)
+	}
+	if !ok {
+		t.Fatal("Pop() ok = false, want a job")
+	}
+	return id
+}
+
+// mustEmpty 确认队列中已没有任务
+func mustEmpty(t *testing.T, q Queue, worker string) {
+	t.Helper()
+	id, ok, err := q.Pop(worker, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Pop() error = %v", err)
+	}
+	if ok {
+		t.Fatalf("Pop() = %d, want empty queue", id)
+	}
+}
+
+func TestMemoryPushPop(t *testing.T) {
+	q := NewMemory()
+	for _, id := range []uint64{1, 2, 3} {
+		if err := q.Push(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []uint64{1, 2, 3} {
+		if got := mustPop(t, q, "w1"); got != want {
+			t.Errorf("Pop() = %d, want %d", got, want)
+		}
+	}
+	mustEmpty(t, q, "w1")
+}
+
+func TestMemoryPriority(t *testing.T) {
+	q := NewMemory()
+	_ = q.PushLow(10)
+	_ = q.PushLow(11)
+	_ = q.Push(1)
+	for _, want := range []uint64{1, 10, 11} {
+		if got := mustPop(t, q, "w1"); got != want {
+			t.Errorf("Pop() = %d, want %d", got, want)
+		}
+	}
+}
+
+func TestMemoryPopWakesOnPush(t *testing.T) {
+	q := NewMemory()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = q.Push(7)
+	}()
+	id, ok, err := q.Pop("w1", time.Second)
+	if err != nil || !ok || id != 7 {
+		t.Fatalf("Pop() = %d, %v, %v, want 7, true, nil", id, ok, err)
+	}
+}
+
+func TestMemoryRequeue(t *testing.T) {
+	q := NewMemory()
+	_ = q.Push(1)
+	_ = q.Push(2)
+	_ = q.Push(3)
+	_ = q.Heartbeat("alive", time.Minute)
+	_ = q.Heartbeat("dead", -time.Second) // 心跳已过期
+
+	if got := mustPop(t, q, "dead"); got != 1 {
+		t.Fatalf("Pop() = %d, want 1", got)
+	}
+	if got := mustPop(t, q, "dead"); got != 2 {
+		t.Fatalf("Pop() = %d, want 2", got)
+	}
+	if got := mustPop(t, q, "alive"); got != 3 {
+		t.Fatalf("Pop() = %d, want 3", got)
+	}
+	// 已确认的任务不会被放回
+	if err := q.Ack("dead", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := q.Requeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Requeue() = %d, want 1", n)
+	}
+	if got := mustPop(t, q, "alive"); got != 2 {
+		t.Errorf("Pop() = %d, want requeued job 2", got)
+	}
+	mustEmpty(t, q, "alive")
+
+	// 存活评测机手中的任务不受影响，确认后也不会再出现
+	if n, _ = q.Requeue(); n != 0 {
+		t.Errorf("Requeue() = %d, want 0", n)
+	}
+	_ = q.Ack("alive", 3)
+	_ = q.Ack("alive", 2)
+	_ = q.Heartbeat("alive", -time.Second)
+	if n, _ = q.Requeue(); n != 0 {
+		t.Errorf("Requeue() after Ack = %d, want 0", n)
+	}
+}
+
+// TestMemoryRequeueKeepsPriority 失联评测机手中的低优先级任务放回低优先级队列
+func TestMemoryRequeueKeepsPriority(t *testing.T) {
+	q := NewMemory()
+	_ = q.PushLow(10)
+	_ = q.Heartbeat("dead", -time.Second)
+	if got := mustPop(t, q, "dead"); got != 10 {
+		t.Fatalf("Pop() = %d, want 10", got)
+	}
+	_ = q.Push(1)
+	if n, _ := q.Requeue(); n != 1 {
+		t.Fatalf("Requeue() = %d, want 1", n)
+	}
+	_ = q.Push(2)
+	for _, want := range []uint64{1, 2, 10} {
+		if got := mustPop(t, q, "w1"); got != want {
+			t.Errorf("Pop() = %d, want %d", got, want)
+		}
+	}
+}
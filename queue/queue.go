@@ -0,0 +1,20 @@
+// Package queue 评测任务队列。任务即提交id，评测机取出任务后先放入自己的处理列表，
+// 评测完成再确认删除；评测机定时发送心跳，心跳过期的评测机手中的任务会被放回队列。
+package queue
+
+import "time"
+
+type Queue interface {
+	// Push 提交id入队
+	Push(submissionID uint64) error
+	// PushLow 以低优先级入队，只有普通队列为空时才会被取出，用于重新评测
+	PushLow(submissionID uint64) error
+	// Pop 阻塞取出一个任务并记入评测机的处理列表，超时返回 ok=false
+	Pop(worker string, timeout time.Duration) (submissionID uint64, ok bool, err error)
+	// Ack 评测完成，从处理列表中删除任务
+	Ack(worker string, submissionID uint64) error
+	// Heartbeat 刷新评测机心跳，ttl 内没有再次心跳即视为失联
+	Heartbeat(worker string, ttl time.Duration) error
+	// Requeue 把失联评测机手中的任务放回队列，返回放回的任务数
+	Requeue() (n int, err error)
+}
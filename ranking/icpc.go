@@ -0,0 +1,68 @@
+package ranking
+
+import (
+	"LanShan/models"
+	"sort"
+)
+
+// DefaultPenalty 每次错误提交的罚时(分钟)
+const DefaultPenalty = 20
+
+// ICPC ACM-ICPC 赛制：按通过题数降序、罚时升序排名。
+// 罚时为每道通过题目的通过时间加上通过前错误提交次数乘以 Penalty，编译错误不计罚时
+type ICPC struct {
+	Penalty int64
+}
+
+func (r ICPC) Rank(problems []Problem, participants []Participant, submissions []Submission) []*Row {
+	penalty := r.Penalty
+	if penalty <= 0 {
+		penalty = DefaultPenalty
+	}
+	rows, byUser, labels := newRows(problems, participants, submissions)
+	firstBlood := make(map[int]bool, len(problems))
+	for _, s := range submissions {
+		index, ok := labels[s.ProblemID]
+		if !ok {
+			continue
+		}
+		cell := byUser[s.UserID].Cells[index]
+		if cell.Accepted {
+			continue
+		}
+		switch {
+		case isPending(s.Status):
+			cell.Pending++
+		case isIgnored(s.Status):
+		case s.Status == models.StatusAccepted:
+			cell.Accepted = true
+			cell.Attempts++
+			cell.Time = minutes(s.Time)
+			// 提交按时间排序，第一个通过的即为一血
+			if !firstBlood[index] {
+				firstBlood[index] = true
+				cell.FirstBlood = true
+			}
+		default:
+			cell.Attempts++
+		}
+	}
+
+	for _, row := range rows {
+		for _, cell := range row.Cells {
+			if cell.Accepted {
+				row.Solved++
+				row.Penalty += cell.Time + int64(cell.Attempts-1)*penalty
+			}
+		}
+	}
+	less := func(a, b *Row) bool {
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		return a.Penalty < b.Penalty
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+	assignRanks(rows, less)
+	return rows
+}
@@ -0,0 +1,101 @@
+package ranking
+
+import (
+	"LanShan/models"
+	"math"
+)
+
+// OI OI赛制：每道题以最后一次提交的得分为准，按总分排名
+type OI struct{}
+
+func (OI) Rank(problems []Problem, participants []Participant, submissions []Submission) []*Row {
+	rows, byUser, labels := newRows(problems, participants, submissions)
+	for _, s := range submissions {
+		index, ok := labels[s.ProblemID]
+		if !ok {
+			continue
+		}
+		cell := byUser[s.UserID].Cells[index]
+		cell.Attempts++
+		if isPending(s.Status) {
+			cell.Pending++
+			continue
+		}
+		// 后面的提交覆盖前面的结果
+		cell.Score = s.Score
+		cell.Time = minutes(s.Time)
+		cell.Accepted = s.Status == models.StatusAccepted
+	}
+	for _, row := range rows {
+		for _, cell := range row.Cells {
+			row.Score += cell.Score
+			if cell.Accepted {
+				row.Solved++
+			}
+		}
+	}
+	sortByScore(rows)
+	return rows
+}
+
+// IOI IOI赛制：每个子任务取历次提交中的最高分，题目得分为各子任务最高分之和，按总分排名。
+// 题目没有子任务时取历次提交的最高分
+type IOI struct{}
+
+func (IOI) Rank(problems []Problem, participants []Participant, submissions []Submission) []*Row {
+	rows, byUser, labels := newRows(problems, participants, submissions)
+	// 用户 -> 题目 -> 子任务 -> 最高分
+	best := make(map[*Cell]map[int]float64)
+	for _, s := range submissions {
+		index, ok := labels[s.ProblemID]
+		if !ok {
+			continue
+		}
+		cell := byUser[s.UserID].Cells[index]
+		cell.Attempts++
+		if isPending(s.Status) {
+			cell.Pending++
+			continue
+		}
+		subtasks := s.Subtasks
+		if len(subtasks) == 0 {
+			subtasks = map[int]float64{0: s.Score}
+		}
+		if best[cell] == nil {
+			best[cell] = make(map[int]float64, len(subtasks))
+		}
+		improved := false
+		for i, score := range subtasks {
+			if score > best[cell][i] {
+				best[cell][i] = score
+				improved = true
+			}
+		}
+		if improved {
+			cell.Time = minutes(s.Time)
+		}
+		if s.Status == models.StatusAccepted {
+			cell.Accepted = true
+		}
+	}
+	for _, row := range rows {
+		for i, cell := range row.Cells {
+			if scores, ok := best[cell]; ok {
+				for _, score := range scores {
+					cell.Score += score
+				}
+				cell.Score = math.Round(cell.Score*100) / 100
+				// 各子任务分别在不同提交中拿满也算通过
+				if cell.Score >= problems[i].fullScore() {
+					cell.Accepted = true
+				}
+			}
+			row.Score += cell.Score
+			if cell.Accepted {
+				row.Solved++
+			}
+		}
+	}
+	sortByScore(rows)
+	return rows
+}
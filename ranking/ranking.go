@@ -0,0 +1,174 @@
+// Package ranking 根据比赛中的提交计算排行榜，不同赛制实现不同的 Ranker
+package ranking
+
+import (
+	"LanShan/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Problem 比赛中的一道题，MaxScore 为满分(各子任务分值之和)，为0时按 models.FullScore 计算
+type Problem struct {
+	ProblemID uint64
+	Label     string
+	MaxScore  float64
+}
+
+// fullScore 题目的满分
+func (p Problem) fullScore() float64 {
+	if p.MaxScore > 0 {
+		return p.MaxScore
+	}
+	return models.FullScore
+}
+
+// Participant 参赛者，以队伍参赛时 UserID 为队伍id、Username 为队名
+type Participant struct {
+	UserID   uint64
+	Username string
+	Virtual  bool     // 虚拟参赛
+	Team     bool     // 以队伍参赛
+	Members  []string // 队员用户名
+}
+
+// Submission 比赛中的一次提交，Time 为距比赛开始的时间，队员的提交 UserID 为队伍id
+type Submission struct {
+	SubmissionID uint64
+	UserID       uint64
+	ProblemID    uint64
+	Status       string
+	Score        float64
+	Subtasks     map[int]float64 // 各子任务得分，IOI赛制使用
+	Time         time.Duration
+}
+
+// Cell 参赛者在一道题上的结果
+type Cell struct {
+	Label      string  `json:"label"`
+	Accepted   bool    `json:"accepted"`
+	Attempts   int     `json:"attempts"`    // 首次通过前(含)的有效提交次数
+	Pending    int     `json:"pending"`     // 尚未出结果的提交次数
+	Time       int64   `json:"time"`        // 首次通过或取得最高分的时间(分钟)
+	Score      float64 `json:"score"`       // 得分，ACM赛制下为0
+	FirstBlood bool    `json:"first_blood"` // 全场第一个通过
+}
+
+// Row 排行榜中的一行
+type Row struct {
+	Rank     int      `json:"rank"`
+	UserID   uint64   `json:"user_id,string"`
+	Username string   `json:"username"`
+	Virtual  bool     `json:"virtual,omitempty"`
+	Team     bool     `json:"team,omitempty"`
+	Members  []string `json:"members,omitempty"`
+	Solved   int      `json:"solved"`
+	Penalty  int64    `json:"penalty"` // 罚时(分钟)
+	Score    float64  `json:"score"`
+	Cells    []*Cell  `json:"cells"`
+}
+
+// Scoreboard 排行榜，封榜时封榜后的提交均显示为等待结果
+type Scoreboard struct {
+	Frozen bool   `json:"frozen"`
+	Rows   []*Row `json:"rows"`
+}
+
+// Ranker 赛制，根据提交计算排行榜，提交需按时间先后排序
+type Ranker interface {
+	Rank(problems []Problem, participants []Participant, submissions []Submission) []*Row
+}
+
+var (
+	rankerMu sync.RWMutex
+	rankers  = map[string]Ranker{
+		models.ContestRuleACM: ICPC{Penalty: DefaultPenalty},
+		models.ContestRuleOI:  OI{},
+		models.ContestRuleIOI: IOI{},
+	}
+)
+
+// Register 注册或替换赛制对应的排名方式
+func Register(rule string, r Ranker) {
+	rankerMu.Lock()
+	defer rankerMu.Unlock()
+	rankers[rule] = r
+}
+
+// Get 获取赛制对应的排名方式，未知赛制按ACM处理
+func Get(rule string) Ranker {
+	rankerMu.RLock()
+	defer rankerMu.RUnlock()
+	if r, ok := rankers[rule]; ok {
+		return r
+	}
+	return rankers[models.ContestRuleACM]
+}
+
+// isPending 是否尚未出结果
+func isPending(status string) bool {
+	return status == models.StatusPending || status == models.StatusJudging
+}
+
+// isIgnored 不计入尝试次数的结果，如编译错误和系统错误
+func isIgnored(status string) bool {
+	return status == models.StatusCompileError || status == models.StatusSystemError
+}
+
+// newRows 为每个参赛者创建空的一行，未报名但有提交的用户同样计入
+func newRows(problems []Problem, participants []Participant, submissions []Submission) (rows []*Row, byUser map[uint64]*Row, labels map[uint64]int) {
+	labels = make(map[uint64]int, len(problems))
+	for i, p := range problems {
+		labels[p.ProblemID] = i
+	}
+	byUser = make(map[uint64]*Row, len(participants))
+	add := func(p Participant) {
+		if _, ok := byUser[p.UserID]; ok {
+			return
+		}
+		row := &Row{
+			UserID:   p.UserID,
+			Username: p.Username,
+			Virtual:  p.Virtual,
+			Team:     p.Team,
+			Members:  p.Members,
+			Cells:    make([]*Cell, len(problems)),
+		}
+		for i, p := range problems {
+			row.Cells[i] = &Cell{Label: p.Label}
+		}
+		byUser[p.UserID] = row
+		rows = append(rows, row)
+	}
+	for _, p := range participants {
+		add(p)
+	}
+	for _, s := range submissions {
+		add(Participant{UserID: s.UserID})
+	}
+	return
+}
+
+// assignRanks 按排好的顺序编排名，less 判断两行是否严格先后，成绩相同时名次并列
+func assignRanks(rows []*Row, less func(a, b *Row) bool) {
+	for i, row := range rows {
+		if i > 0 && !less(rows[i-1], row) {
+			row.Rank = rows[i-1].Rank
+		} else {
+			row.Rank = i + 1
+		}
+	}
+}
+
+func minutes(d time.Duration) int64 {
+	return int64(d / time.Minute)
+}
+
+// sortByScore 按总分降序排名，总分相同时名次并列
+func sortByScore(rows []*Row) {
+	less := func(a, b *Row) bool {
+		return a.Score > b.Score
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+	assignRanks(rows, less)
+}
@@ -0,0 +1,205 @@
+package ranking
+
+import (
+	"LanShan/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testProblems = []Problem{{ProblemID: 1, Label: "A"}, {ProblemID: 2, Label: "B"}}
+
+// sub 构造距比赛开始 minute 分钟时的一次提交
+func sub(userID, problemID uint64, minute int, status string, score float64) Submission {
+	return Submission{
+		UserID:    userID,
+		ProblemID: problemID,
+		Status:    status,
+		Score:     score,
+		Time:      time.Duration(minute) * time.Minute,
+	}
+}
+
+// rowSummary 排行榜一行中参与比较的字段
+type rowSummary struct {
+	UserID  uint64
+	Rank    int
+	Solved  int
+	Penalty int64
+	Score   float64
+}
+
+func summarize(rows []*Row) []rowSummary {
+	result := make([]rowSummary, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, rowSummary{r.UserID, r.Rank, r.Solved, r.Penalty, r.Score})
+	}
+	return result
+}
+
+func TestICPCRank(t *testing.T) {
+	tests := []struct {
+		name        string
+		submissions []Submission
+		want        []rowSummary
+	}{
+		{"solved then penalty", []Submission{
+			sub(1, 1, 10, models.StatusWrongAnswer, 0),
+			sub(1, 1, 20, models.StatusAccepted, 0),
+			sub(2, 1, 15, models.StatusAccepted, 0),
+			sub(3, 1, 5, models.StatusAccepted, 0),
+			sub(3, 2, 50, models.StatusAccepted, 0),
+		}, []rowSummary{
+			{3, 1, 2, 55, 0},
+			{2, 2, 1, 15, 0},
+			{1, 3, 1, 40, 0},
+		}},
+		{"compile error and later submissions ignored", []Submission{
+			sub(1, 1, 10, models.StatusCompileError, 0),
+			sub(1, 1, 12, models.StatusAccepted, 0),
+			sub(1, 1, 30, models.StatusWrongAnswer, 0),
+			sub(2, 1, 12, models.StatusAccepted, 0),
+		}, []rowSummary{
+			{1, 1, 1, 12, 0},
+			{2, 1, 1, 12, 0},
+		}},
+		{"pending not counted", []Submission{
+			sub(1, 1, 10, models.StatusPending, 0),
+			sub(2, 1, 10, models.StatusWrongAnswer, 0),
+		}, []rowSummary{
+			{1, 1, 0, 0, 0},
+			{2, 1, 0, 0, 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := ICPC{}.Rank(testProblems, nil, tt.submissions)
+			if got := summarize(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestICPCCells(t *testing.T) {
+	rows := ICPC{Penalty: 10}.Rank(testProblems, []Participant{{UserID: 9, Username: "idle"}}, []Submission{
+		sub(1, 1, 3, models.StatusWrongAnswer, 0),
+		sub(1, 1, 7, models.StatusAccepted, 0),
+		sub(2, 1, 8, models.StatusAccepted, 0),
+		sub(2, 2, 9, models.StatusJudging, 0),
+	})
+	byUser := make(map[uint64]*Row)
+	for _, r := range rows {
+		byUser[r.UserID] = r
+	}
+	if len(rows) != 3 || byUser[9] == nil || byUser[9].Solved != 0 {
+		t.Fatalf("Rank() rows = %+v, want registered participant without submissions", summarize(rows))
+	}
+	first := byUser[1].Cells[0]
+	if !first.Accepted || !first.FirstBlood || first.Attempts != 2 || first.Time != 7 {
+		t.Errorf("user 1 cell A = %+v, want first blood accepted at 7 after 2 attempts", first)
+	}
+	if byUser[1].Penalty != 17 {
+		t.Errorf("user 1 penalty = %d, want 17", byUser[1].Penalty)
+	}
+	if byUser[2].Cells[0].FirstBlood {
+		t.Error("user 2 cell A is first blood, want only the first accepted submission")
+	}
+	if byUser[2].Cells[1].Pending != 1 {
+		t.Errorf("user 2 cell B pending = %d, want 1", byUser[2].Cells[1].Pending)
+	}
+}
+
+func TestOIRank(t *testing.T) {
+	tests := []struct {
+		name        string
+		submissions []Submission
+		want        []rowSummary
+	}{
+		{"last submission counts", []Submission{
+			sub(1, 1, 10, models.StatusAccepted, 100),
+			sub(1, 1, 20, models.StatusWrongAnswer, 30),
+			sub(2, 1, 10, models.StatusWrongAnswer, 40),
+			sub(2, 2, 20, models.StatusAccepted, 100),
+		}, []rowSummary{
+			{2, 1, 1, 0, 140},
+			{1, 2, 0, 0, 30},
+		}},
+		{"pending keeps previous result", []Submission{
+			sub(1, 1, 10, models.StatusAccepted, 100),
+			sub(1, 1, 20, models.StatusPending, 0),
+			sub(2, 1, 10, models.StatusAccepted, 100),
+		}, []rowSummary{
+			{1, 1, 1, 0, 100},
+			{2, 1, 1, 0, 100},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := OI{}.Rank(testProblems, nil, tt.submissions)
+			if got := summarize(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIOIRank(t *testing.T) {
+	// 题目A有两个子任务，共60分；题目B没有子任务，满分100
+	problems := []Problem{{ProblemID: 1, Label: "A", MaxScore: 60}, {ProblemID: 2, Label: "B"}}
+	withSubtasks := func(s Submission, subtasks map[int]float64) Submission {
+		s.Subtasks = subtasks
+		return s
+	}
+	tests := []struct {
+		name        string
+		submissions []Submission
+		want        []rowSummary
+		accepted    map[uint64]bool // 用户是否通过题目A
+	}{
+		{"best subtask across submissions", []Submission{
+			withSubtasks(sub(1, 1, 10, models.StatusWrongAnswer, 20), map[int]float64{1: 20, 2: 0}),
+			withSubtasks(sub(1, 1, 20, models.StatusWrongAnswer, 40), map[int]float64{1: 0, 2: 40}),
+			withSubtasks(sub(2, 1, 10, models.StatusWrongAnswer, 40), map[int]float64{1: 0, 2: 40}),
+		}, []rowSummary{
+			{1, 1, 1, 0, 60},
+			{2, 2, 0, 0, 40},
+		}, map[uint64]bool{1: true, 2: false}},
+		{"no subtasks uses best score", []Submission{
+			sub(1, 2, 10, models.StatusAccepted, 100),
+			sub(1, 2, 20, models.StatusWrongAnswer, 10),
+			sub(2, 2, 10, models.StatusWrongAnswer, 50),
+		}, []rowSummary{
+			{1, 1, 1, 0, 100},
+			{2, 2, 0, 0, 50},
+		}, map[uint64]bool{}},
+		{"frozen submissions are pending", []Submission{
+			withSubtasks(sub(1, 1, 10, models.StatusWrongAnswer, 20), map[int]float64{1: 20, 2: 0}),
+			sub(1, 1, 50, models.StatusPending, 0),
+		}, []rowSummary{
+			{1, 1, 0, 0, 20},
+		}, map[uint64]bool{1: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := IOI{}.Rank(problems, nil, tt.submissions)
+			if got := summarize(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank() = %+v, want %+v", got, tt.want)
+			}
+			for _, r := range rows {
+				if want, ok := tt.accepted[r.UserID]; ok && r.Cells[0].Accepted != want {
+					t.Errorf("user %d cell A accepted = %v, want %v", r.UserID, r.Cells[0].Accepted, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	if _, ok := Get(models.ContestRuleIOI).(IOI); !ok {
+		t.Error("Get(ioi) is not IOI")
+	}
+	if _, ok := Get("unknown").(ICPC); !ok {
+		t.Error("Get(unknown) is not ICPC")
+	}
+}
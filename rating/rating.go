@@ -0,0 +1,154 @@
+// Package rating 按 Codeforces 的等级分算法，根据比赛最终排名计算参赛者的等级分变化。
+// 计算只依赖输入，相同输入总是得到相同结果
+package rating
+
+import (
+	"math"
+	"sort"
+)
+
+// InitialRating 未参加过计分比赛的用户的初始等级分
+const InitialRating = 1500
+
+// Contestant 参加计分比赛的用户，Rank 从1开始，并列时 Rank 相同
+type Contestant struct {
+	UserID uint64
+	Rank   int
+	Rating int
+}
+
+// Change 等级分变化
+type Change struct {
+	UserID    uint64
+	Rank      int
+	OldRating int
+	NewRating int
+}
+
+// Delta 等级分变化量
+func (c Change) Delta() int {
+	return c.NewRating - c.OldRating
+}
+
+// winProbability 等级分为 a 的选手战胜等级分为 b 的选手的概率
+func winProbability(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+type contestant struct {
+	Contestant
+	place float64 // 并列时取并列名次中最靠后的
+	seed  float64 // 按等级分预期的名次
+	delta int
+}
+
+// seedOf 等级分为 r 的选手在参赛者中的预期名次，exclude 为自身，为空时与所有参赛者比较
+func seedOf(all []*contestant, r float64, exclude *contestant) float64 {
+	seed := 1.0
+	for _, c := range all {
+		if c != exclude {
+			seed += winProbability(float64(c.Rating), r)
+		}
+	}
+	return seed
+}
+
+// ratingToRank 二分查找使预期名次等于 rank 的等级分。与 Codeforces 一致，
+// 这里把假想的选手与包括自身在内的所有参赛者比较
+func ratingToRank(all []*contestant, rank float64) int {
+	left, right := 1, 8000
+	for right-left > 1 {
+		mid := (left + right) / 2
+		if seedOf(all, float64(mid), nil) < rank {
+			right = mid
+		} else {
+			left = mid
+		}
+	}
+	return left
+}
+
+// Calculate 计算一场比赛所有参赛者的等级分变化，结果按名次排序，名次相同时按用户id排序
+func Calculate(contestants []Contestant) []Change {
+	n := len(contestants)
+	if n == 0 {
+		return []Change{}
+	}
+	all := make([]*contestant, n)
+	for i := range contestants {
+		all[i] = &contestant{Contestant: contestants[i]}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Rank != all[j].Rank {
+			return all[i].Rank < all[j].Rank
+		}
+		return all[i].UserID < all[j].UserID
+	})
+	// 并列的选手都按并列中最靠后的名次计算
+	for i := 0; i < n; {
+		j := i
+		for j+1 < n && all[j+1].Rank == all[i].Rank {
+			j++
+		}
+		for k := i; k <= j; k++ {
+			all[k].place = float64(j + 1)
+		}
+		i = j + 1
+	}
+
+	for _, c := range all {
+		c.seed = seedOf(all, float64(c.Rating), c)
+	}
+	for _, c := range all {
+		// 期望名次取预期名次与实际名次的几何平均
+		mid := math.Sqrt(c.place * c.seed)
+		need := ratingToRank(all, mid)
+		c.delta = (need - c.Rating) / 2
+	}
+
+	// 所有人的变化之和略小于0，防止等级分整体膨胀
+	sum := 0
+	for _, c := range all {
+		sum += c.delta
+	}
+	inc := -sum/n - 1
+	for _, c := range all {
+		c.delta += inc
+	}
+
+	// 等级分最高的一部分人变化之和调整为0，限制在 [-10, 0]
+	byRating := make([]*contestant, n)
+	copy(byRating, all)
+	sort.SliceStable(byRating, func(i, j int) bool {
+		return byRating[i].Rating > byRating[j].Rating
+	})
+	zeroSumCount := 4 * int(math.Round(math.Sqrt(float64(n))))
+	if zeroSumCount > n {
+		zeroSumCount = n
+	}
+	sum = 0
+	for _, c := range byRating[:zeroSumCount] {
+		sum += c.delta
+	}
+	inc = -sum / zeroSumCount
+	if inc > 0 {
+		inc = 0
+	}
+	if inc < -10 {
+		inc = -10
+	}
+	for _, c := range all {
+		c.delta += inc
+	}
+
+	changes := make([]Change, 0, n)
+	for _, c := range all {
+		changes = append(changes, Change{
+			UserID:    c.UserID,
+			Rank:      c.Rank,
+			OldRating: c.Rating,
+			NewRating: c.Rating + c.delta,
+		})
+	}
+	return changes
+}
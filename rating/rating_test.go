@@ -0,0 +1,94 @@
+package rating
+
+import (
+	"reflect"
+	"testing"
+)
+
+// contestantsOf 由期望结果还原比赛的输入
+func contestantsOf(changes []Change) []Contestant {
+	contestants := make([]Contestant, 0, len(changes))
+	for _, c := range changes {
+		contestants = append(contestants, Contestant{UserID: c.UserID, Rank: c.Rank, Rating: c.OldRating})
+	}
+	return contestants
+}
+
+// TestCalculate 期望值按 Codeforces 公布的参考实现(CodeforcesRatingCalculator)计算
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name string
+		want []Change
+	}{
+		{"two newcomers", []Change{
+			{UserID: 1, Rank: 1, OldRating: 1500, NewRating: 1565},
+			{UserID: 2, Rank: 2, OldRating: 1500, NewRating: 1433},
+		}},
+		{"tie", []Change{
+			{UserID: 1, Rank: 1, OldRating: 1500, NewRating: 1518},
+			{UserID: 2, Rank: 1, OldRating: 1500, NewRating: 1518},
+			{UserID: 3, Rank: 3, OldRating: 1500, NewRating: 1463},
+		}},
+		{"upset", []Change{
+			{UserID: 1, Rank: 1, OldRating: 1200, NewRating: 1477},
+			{UserID: 2, Rank: 2, OldRating: 1500, NewRating: 1544},
+			{UserID: 3, Rank: 3, OldRating: 1800, NewRating: 1689},
+			{UserID: 4, Rank: 4, OldRating: 2100, NewRating: 1887},
+		}},
+		{"expected order", []Change{
+			{UserID: 1, Rank: 1, OldRating: 2100, NewRating: 2204},
+			{UserID: 2, Rank: 2, OldRating: 1800, NewRating: 1770},
+			{UserID: 3, Rank: 3, OldRating: 1500, NewRating: 1461},
+			{UserID: 4, Rank: 4, OldRating: 1200, NewRating: 1161},
+		}},
+		{"mixed", []Change{
+			{UserID: 1, Rank: 1, OldRating: 1650, NewRating: 1862},
+			{UserID: 2, Rank: 2, OldRating: 1500, NewRating: 1592},
+			{UserID: 3, Rank: 2, OldRating: 1820, NewRating: 1835},
+			{UserID: 4, Rank: 4, OldRating: 1400, NewRating: 1479},
+			{UserID: 5, Rank: 5, OldRating: 2300, NewRating: 2149},
+			{UserID: 6, Rank: 6, OldRating: 1500, NewRating: 1487},
+			{UserID: 7, Rank: 7, OldRating: 1100, NewRating: 1183},
+			{UserID: 8, Rank: 8, OldRating: 1980, NewRating: 1821},
+			{UserID: 9, Rank: 9, OldRating: 1500, NewRating: 1415},
+			{UserID: 10, Rank: 10, OldRating: 1350, NewRating: 1268},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 输入顺序不影响结果
+			contestants := contestantsOf(tt.want)
+			for i, j := 0, len(contestants)-1; i < j; i, j = i+1, j-1 {
+				contestants[i], contestants[j] = contestants[j], contestants[i]
+			}
+			if got := Calculate(contestants); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Calculate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateEmpty(t *testing.T) {
+	if got := Calculate(nil); len(got) != 0 {
+		t.Errorf("Calculate(nil) = %v, want empty", got)
+	}
+}
+
+// TestCalculateNoInflation 所有人的变化之和不大于0，名次越靠前变化越大(等级分相同时)
+func TestCalculateNoInflation(t *testing.T) {
+	contestants := make([]Contestant, 0, 50)
+	for i := 1; i <= 50; i++ {
+		contestants = append(contestants, Contestant{UserID: uint64(i), Rank: i, Rating: InitialRating})
+	}
+	changes := Calculate(contestants)
+	sum := 0
+	for i, c := range changes {
+		sum += c.Delta()
+		if i > 0 && c.Delta() > changes[i-1].Delta() {
+			t.Errorf("rank %d gains %d, more than rank %d (%d)", c.Rank, c.Delta(), changes[i-1].Rank, changes[i-1].Delta())
+		}
+	}
+	if sum > 0 {
+		t.Errorf("sum of deltas = %d, want <= 0", sum)
+	}
+}
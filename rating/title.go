@@ -0,0 +1,27 @@
+package rating
+
+// 等级分对应的称号，与 Codeforces 一致
+var titles = []struct {
+	min   int
+	title string
+}{
+	{3000, "legendary grandmaster"},
+	{2600, "international grandmaster"},
+	{2400, "grandmaster"},
+	{2300, "international master"},
+	{2100, "master"},
+	{1900, "candidate master"},
+	{1600, "expert"},
+	{1400, "specialist"},
+	{1200, "pupil"},
+}
+
+// Title 等级分对应的称号
+func Title(rating int) string {
+	for _, t := range titles {
+		if rating >= t.min {
+			return t.title
+		}
+	}
+	return "newbie"
+}
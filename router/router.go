@@ -3,6 +3,7 @@ package router
 import (
 	"LanShan/api"
 	"LanShan/api/middlewares"
+	"LanShan/models"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -25,23 +26,103 @@ func SetupRouter(mode string) *gin.Engine {
 	v1.GET("/community", api.CommunityHandler)           // 获取分类社区列表
 	v1.GET("/community/:id", api.CommunityDetailHandler) // 根据ID查找社区详情
 
-	v1.GET("/problem/:id", api.ProblemDetailHandler) // 查询问题详情
-	v1.GET("/problems", api.ProblemListHandler)      // 分页展示问题列表
+	v1.GET("/tags", api.TagListHandler) // 标签列表
 
-	v1.GET("/answers/:id", api.AnswerListHandler)  // 根据题目获取题解列表
-	v1.GET("/answer/:id", api.AnswerDetailHandler) // 获取题解
+	// 尚未开始的比赛中的题目只有题目作者、比赛创建者和管理者可见，需要识别当前用户
+	problems := v1.Group("", middlewares.OptionalJWTMiddleware())
+	problems.GET("/problem/:id", api.ProblemDetailHandler)                       // 查询问题详情
+	problems.GET("/problems", api.ProblemListHandler)                            // 分页展示问题列表，可按标签、难度筛选
+	problems.GET("/search", api.SearchHandler)                                   // 搜索题目或题解
+	problems.GET("/problem/:id/revisions", api.ProblemRevisionListHandler)       // 题目的版本列表
+	problems.GET("/problem/:id/revisions/diff", api.ProblemRevisionDiffHandler)  // 比较两个版本
+	problems.GET("/problem/:id/translations", api.ProblemTranslationListHandler) // 题目的译文列表
+
+	v1.GET("/languages", api.LanguageListHandler) // 获取评测语言列表
+
+	v1.GET("/contests", api.ContestListHandler) // 分页展示比赛列表
+	v1.GET("/contest/:id", middlewares.OptionalJWTMiddleware(),
+		api.ContestDetailHandler) // 比赛详情，私有比赛的题目仅报名者和管理者可见
+	v1.GET("/contest/:id/scoreboard", middlewares.OptionalJWTMiddleware(),
+		api.ContestScoreboardHandler) // 比赛排行榜，私有比赛仅报名者和管理者可见，OI赛制比赛结束前仅创建者和管理员可见
+
+	v1.GET("/user/:id/rating", api.UserRatingHandler) // 用户等级分及历史
+
+	problems.GET("/answers/:id", api.AnswerListHandler)  // 根据题目获取题解列表
+	problems.GET("/answer/:id", api.AnswerDetailHandler) // 获取题解
+
+	// WebSocket 连接允许通过查询参数传递Token
+	v1.GET("/submission/:id/ws", middlewares.QueryTokenMiddleware(), middlewares.JWTAuthMiddleware(),
+		api.SubmissionWebSocketHandler) // 推送评测进度
 
 	v1.Use(middlewares.JWTAuthMiddleware()) // 应用JWT认证中间件
 	{
 
-		v1.POST("/problem", api.CreateProblemHandler)            // 发布问题
-		v1.GET("/problem/delete/:id", api.ProblemDeleteHandler)  // 删除问题
-		v1.POST("/problem/update/:id", api.ProblemUpdateHandler) // 修改问题
+		v1.POST("/problem", middlewares.RequireRole(models.RoleSetter, models.RoleModerator),
+			api.CreateProblemHandler) // 发布问题，需要出题人及以上角色
+		v1.POST("/problem/import", middlewares.RequireRole(models.RoleSetter, models.RoleModerator),
+			api.ProblemImportHandler) // 导入 Polygon 题目包或 FPS XML
+		v1.GET("/problem/:id/export", api.ProblemExportHandler)      // 导出题目，format=fps|polygon
+		v1.GET("/problem/delete/:id", api.ProblemDeleteHandler)      // 删除问题
+		v1.POST("/problem/update/:id", api.ProblemUpdateHandler)     // 修改问题
+		v1.POST("/problem/:id/rollback", api.ProblemRollbackHandler) // 回滚到指定版本
+
+		v1.PUT("/problem/:id/translation/:locale", api.ProblemTranslationSaveHandler)      // 新增或修改译文
+		v1.DELETE("/problem/:id/translation/:locale", api.ProblemTranslationDeleteHandler) // 删除译文
+
+		v1.POST("/problem/:id/testcases", api.TestCaseUploadHandler)         // 上传测试数据压缩包
+		v1.GET("/problem/:id/testcases", api.TestCaseListHandler)            // 测试数据列表
+		v1.PUT("/problem/:id/testcase/:index", api.TestCaseUpdateHandler)    // 新增或替换测试点
+		v1.DELETE("/problem/:id/testcase/:index", api.TestCaseDeleteHandler) // 删除测试点
+		v1.POST("/problem/:id/checker", api.CheckerUpdateHandler)            // 设置checker
+		v1.GET("/problem/:id/checker", api.CheckerDetailHandler)             // 查询checker
+		v1.POST("/problem/:id/interactor", api.InteractorUpdateHandler)      // 设置交互器
+		v1.GET("/problem/:id/interactor", api.InteractorDetailHandler)       // 查询交互器
+		v1.DELETE("/problem/:id/interactor", api.InteractorDeleteHandler)    // 删除交互器
+		v1.PUT("/problem/:id/subtasks", api.SubtaskUpdateHandler)            // 设置子任务
+		v1.GET("/problem/:id/subtasks", api.SubtaskListHandler)              // 子任务列表
 
 		v1.POST("/answer", api.AnswerHandler)                  // 发布题解
 		v1.GET("/answer/delete/:id", api.AnswerDeleteHandler)  // 删除题解
 		v1.POST("/answer/update/:id", api.AnswerUpdateHandler) //  修改题解
 
+		v1.POST("/submit", api.SubmitHandler)                           // 提交代码
+		v1.GET("/submission/:id", api.SubmissionDetailHandler)          // 查询提交详情
+		v1.GET("/submissions", api.SubmissionListHandler)               // 筛选提交记录
+		v1.GET("/submission/:id/history", api.SubmissionHistoryHandler) // 重新评测前的结果
+		v1.GET("/submission/:id/stream", api.SubmissionStreamHandler)   // 以SSE推送评测进度
+
+		v1.POST("/contest", middlewares.RequireRole(models.RoleSetter, models.RoleModerator),
+			api.CreateContestHandler) // 创建比赛，需要出题人及以上角色
+		v1.POST("/contest/:id/register", api.ContestRegisterHandler)            // 报名比赛
+		v1.POST("/contest/:id/virtual", api.VirtualStartHandler)                // 开始虚拟参赛
+		v1.GET("/contest/:id/virtual/scoreboard", api.VirtualScoreboardHandler) // 虚拟参赛排行榜
+
+		v1.POST("/contest/:id/clarification", api.ClarificationCreateHandler)             // 提问
+		v1.POST("/contest/:id/clarification/:cid/answer", api.ClarificationAnswerHandler) // 回复答疑
+		v1.GET("/contest/:id/clarifications", api.ClarificationListHandler)               // 答疑列表
+		v1.GET("/contest/:id/clarifications/unread", api.ClarificationUnreadHandler)      // 未读答疑数量
+
+		v1.POST("/team", api.TeamCreateHandler)                          // 创建队伍
+		v1.GET("/teams", api.TeamListHandler)                            // 我的队伍
+		v1.GET("/team/:id", api.TeamDetailHandler)                       // 队伍详情
+		v1.POST("/team/:id/invite", api.TeamInviteHandler)               // 邀请队员
+		v1.GET("/invitations", api.InvitationListHandler)                // 待回复的入队邀请
+		v1.POST("/invitation/:id/accept", api.InvitationAcceptHandler)   // 接受邀请
+		v1.POST("/invitation/:id/decline", api.InvitationDeclineHandler) // 拒绝邀请
+
+		admin := v1.Group("", middlewares.RequireRole(models.RoleAdmin))
+		admin.POST("/rejudge", api.RejudgeHandler)                        // 重新评测
+		admin.GET("/rejudge/:id", api.RejudgeDetailHandler)               // 重新评测进度
+		admin.PUT("/user/:id/role", api.UserRoleHandler)                  // 修改用户角色
+		admin.POST("/contest/:id/unfreeze", api.ContestUnfreezeHandler)   // 解除封榜
+		admin.GET("/contest/:id/resolver", api.ContestResolverHandler)    // 导出滚榜事件
+		admin.POST("/contest/:id/rating", api.RatingFinalizeHandler)      // 计算比赛等级分
+		admin.POST("/plagiarism", api.PlagiarismCheckHandler)             // 发起查重
+		admin.GET("/plagiarism/:id", api.PlagiarismReportHandler)         // 查重报告
+		admin.GET("/plagiarism/:id/pair/:pid", api.PlagiarismPairHandler) // 可疑提交对的对比
+		admin.POST("/tag", api.TagCreateHandler)                          // 创建标签
+		admin.PUT("/tag/:id", api.TagUpdateHandler)                       // 修改标签
+		admin.DELETE("/tag/:id", api.TagDeleteHandler)                    // 删除标签
 	}
 
 	return r
@@ -1,5 +1,38 @@
 package service
 
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+)
+
 func CreatAnswer() {
 
 }
+
+// GetAnswerList 分页查询题目的题解，题目属于对查看者隐藏的未开始比赛时返回 ErrorProblemNotStarted
+func GetAnswerList(page, size int64, problemID uint64, viewer *models.ProblemViewer) ([]*models.Answer, error) {
+	hidden, err := mysql.IsProblemHidden(problemID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrorProblemNotStarted
+	}
+	return mysql.GetAnswerList(page, size, int64(problemID))
+}
+
+// GetAnswerDetail 查询题解，所属题目对查看者隐藏时返回 ErrorProblemNotStarted
+func GetAnswerDetail(answerID int64, viewer *models.ProblemViewer) (*models.Answer, error) {
+	answer, err := mysql.GetAnswerById(answerID)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := mysql.IsProblemHidden(answer.ProblemID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrorProblemNotStarted
+	}
+	return answer, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/settings"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"path/filepath"
+	"strconv"
+)
+
+var ErrorCheckerSource = errors.New("自定义checker需要指定语言和源代码")
+
+// programDir 辅助程序的编译目录，按语言和源代码的哈希区分，修改后自动重新编译
+func programDir(kind string, problemID uint64, language, source string) string {
+	sum := md5.Sum([]byte(language + "\x00" + source))
+	return filepath.Join(settings.Get().JudgeConfig.DataDir, kind,
+		strconv.FormatUint(problemID, 10), hex.EncodeToString(sum[:]))
+}
+
+// loadChecker 读取题目的checker并转换为评测使用的形式，自定义checker在此编译
+func loadChecker(problemID uint64) (*judge.Checker, error) {
+	checker, err := mysql.GetChecker(problemID)
+	if err != nil || checker == nil {
+		return nil, err
+	}
+	ck := &judge.Checker{Type: checker.Type, Epsilon: checker.Epsilon}
+	if checker.Type == models.CheckerCustom {
+		dir := programDir("checker", problemID, checker.Language, checker.Source)
+		if ck.Program, err = judge.PrepareProgram(dir, checker.Language, checker.Source); err != nil {
+			return nil, err
+		}
+	}
+	return ck, nil
+}
+
+// SaveChecker 设置题目的checker，自定义checker立即编译，编译失败时不保存
+func SaveChecker(problemID uint64, p *models.ParamChecker) (checker *models.Checker, err error) {
+	checker = &models.Checker{
+		ProblemID: problemID,
+		Type:      p.Type,
+		Epsilon:   p.Epsilon,
+	}
+	switch p.Type {
+	case models.CheckerFloat:
+		if checker.Epsilon == 0 {
+			checker.Epsilon = models.DefaultCheckerEpsilon
+		}
+	case models.CheckerCustom:
+		if p.Language == "" || p.Source == "" {
+			return nil, ErrorCheckerSource
+		}
+		checker.Language = p.Language
+		checker.Source = p.Source
+		dir := programDir("checker", problemID, p.Language, p.Source)
+		if _, err = judge.PrepareProgram(dir, p.Language, p.Source); err != nil {
+			zap.L().Error("judge.PrepareProgram() failed", zap.Uint64("problemID", problemID), zap.Error(err))
+			return nil, err
+		}
+	}
+	if err = mysql.SaveChecker(checker); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// GetChecker 查询题目的checker，未设置时返回默认的按行比较
+func GetChecker(problemID uint64) (checker *models.Checker, err error) {
+	checker, err = mysql.GetChecker(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if checker == nil {
+		checker = &models.Checker{ProblemID: problemID, Type: models.CheckerLine}
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/utils/snowflake"
+	"go.uber.org/zap"
+	"time"
+)
+
+// isContestManager 比赛创建者、版主和管理员可以管理比赛
+func isContestManager(contest *models.Contest, viewerID uint64, role string) bool {
+	return contest.AuthorID == viewerID || models.IsManagerRole(role)
+}
+
+// CreateClarification 已报名的参赛者(含队伍成员)在比赛期间提问，可以指定题号
+func CreateClarification(contestID, userID uint64, p *models.ParamClarification) (clar *models.Clarification, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if !contest.Running(time.Now()) {
+		return nil, ErrorContestNotRunning
+	}
+	registered, err := isContestRegistered(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !registered {
+		return nil, ErrorContestNotRegistered
+	}
+	if p.ProblemLabel != "" {
+		problems, err := mysql.GetContestProblems(contestID)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, cp := range problems {
+			if cp.Label == p.ProblemLabel {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrorProblemNotInContest
+		}
+	}
+	clarificationID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	clar = &models.Clarification{
+		ClarificationID: clarificationID,
+		ContestID:       contestID,
+		UserID:          userID,
+		ProblemLabel:    p.ProblemLabel,
+		Question:        p.Question,
+	}
+	if err = mysql.CreateClarification(clar); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AnswerClarification 比赛管理者回复答疑，可以只回复提问者或广播给所有参赛者
+func AnswerClarification(contestID, clarificationID, viewerID uint64, role string, p *models.ParamClarificationAnswer) error {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return err
+	}
+	if !isContestManager(contest, viewerID, role) {
+		return ErrorContestPermission
+	}
+	clar, err := mysql.GetClarificationByID(clarificationID)
+	if err != nil {
+		return err
+	}
+	if clar.ContestID != contestID {
+		return mysql.ErrorInvalidID
+	}
+	return mysql.AnswerClarification(clarificationID, viewerID, p.Answer, p.Public)
+}
+
+// GetClarificationList 查询答疑并标记为已读。比赛管理者可以看到全部提问，
+// 参赛者只能看到自己的提问和公开的答疑，他人的提问不展示提问者
+func GetClarificationList(contestID, viewerID uint64, role string) ([]*models.Clarification, error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	// 查询前记录时间，查询期间产生的新回复下次仍算未读
+	now := time.Now()
+	manager := isContestManager(contest, viewerID, role)
+	var clars []*models.Clarification
+	if manager {
+		clars, err = mysql.GetClarificationList(contestID, 0)
+	} else {
+		clars, err = mysql.GetClarificationList(contestID, viewerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !manager {
+		for _, clar := range clars {
+			if clar.UserID != viewerID {
+				clar.UserID, clar.Username = 0, ""
+			}
+		}
+	}
+	if err = mysql.SetClarificationReadTime(contestID, viewerID, now); err != nil {
+		return nil, err
+	}
+	return clars, nil
+}
+
+// GetUnreadClarificationCount 未读答疑数量。比赛管理者统计新提问，参赛者统计新回复
+func GetUnreadClarificationCount(contestID, viewerID uint64, role string) (data *models.ApiClarificationUnread, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	readTime, err := mysql.GetClarificationReadTime(contestID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	data = new(models.ApiClarificationUnread)
+	if isContestManager(contest, viewerID, role) {
+		data.Unread, err = mysql.CountNewQuestions(contestID, readTime)
+	} else {
+		data.Unread, err = mysql.CountNewAnswers(contestID, viewerID, readTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/ranking"
+	"LanShan/utils/snowflake"
+	"errors"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+var (
+	ErrorContestProblem       = errors.New("比赛题目或题号重复")
+	ErrorContestEnded         = errors.New("比赛已结束")
+	ErrorContestNotRunning    = errors.New("比赛不在进行中")
+	ErrorContestPassword      = errors.New("比赛密码错误")
+	ErrorContestNotRegistered = errors.New("未报名该比赛")
+	ErrorProblemNotInContest  = errors.New("题目不在比赛中")
+	ErrorProblemInContest     = errors.New("题目所在的比赛尚未结束，请在比赛中提交")
+	ErrorScoreboardHidden     = errors.New("比赛结束后公布排行榜")
+	ErrorContestNotEnded      = errors.New("比赛尚未结束")
+	ErrorContestPermission    = errors.New("没有权限管理该比赛")
+)
+
+// CreateContest 创建比赛，题目必须存在且题号、题目不能重复
+func CreateContest(userID uint64, p *models.ParamContest) (contest *models.Contest, err error) {
+	labels := make(map[string]bool, len(p.Problems))
+	ids := make(map[uint64]bool, len(p.Problems))
+	problems := make([]*models.ContestProblem, 0, len(p.Problems))
+	for _, cp := range p.Problems {
+		if labels[cp.Label] || ids[cp.ProblemID] {
+			return nil, ErrorContestProblem
+		}
+		labels[cp.Label], ids[cp.ProblemID] = true, true
+		if _, err = mysql.GetProblemByID(int64(cp.ProblemID)); err != nil {
+			return nil, err
+		}
+		problems = append(problems, &models.ContestProblem{ProblemID: cp.ProblemID, Label: cp.Label})
+	}
+	contestID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	contest = &models.Contest{
+		ContestID:   contestID,
+		AuthorID:    userID,
+		Title:       p.Title,
+		Description: p.Description,
+		Rule:        p.Rule,
+		Visibility:  p.Visibility,
+		StartTime:   p.StartTime,
+		Duration:    p.Duration,
+		Freeze:      p.Freeze,
+		Rated:       p.Rated,
+	}
+	if p.Visibility == models.ContestPrivate {
+		// 报名密码只保存哈希
+		hash, err := bcrypt.GenerateFromPassword([]byte(p.Password), bcrypt.DefaultCost)
+		if err != nil {
+			zap.L().Error("bcrypt.GenerateFromPassword() failed", zap.Error(err))
+			return nil, err
+		}
+		contest.Password = string(hash)
+	}
+	if err = mysql.CreateContest(contest, problems); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func GetContestList(page, size int64) ([]*models.Contest, error) {
+	return mysql.GetContestList(page, size)
+}
+
+// GetContestDetail 查询比赛详情，比赛开始前不展示题目
+func GetContestDetail(contestID, viewerID uint64, role string) (data *models.ApiContestDetail, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiContestDetail{
+		Contest:  contest,
+		EndTime:  contest.EndTime(),
+		Problems: []*models.ContestProblem{},
+	}
+	if time.Now().Before(contest.StartTime) {
+		return data, nil
+	}
+	// 私有比赛的题目只对报名者和管理者展示，其他人只能看到比赛信息用于报名
+	ok, err := canViewContest(contest, viewerID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return data, nil
+	}
+	if data.Problems, err = mysql.GetContestProblems(contestID); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// canViewContest 私有比赛的题目和排行榜只有报名者(含队伍成员)和管理者可以查看，公开比赛不限制
+func canViewContest(contest *models.Contest, viewerID uint64, role string) (bool, error) {
+	if contest.Visibility != models.ContestPrivate || isContestManager(contest, viewerID, role) {
+		return true, nil
+	}
+	if viewerID == 0 {
+		return false, nil
+	}
+	return isContestRegistered(contest.ContestID, viewerID)
+}
+
+// RegisterContest 报名比赛，比赛结束前都可以报名，私有比赛需要密码。
+// 指定队伍时由队长为整支队伍报名，同一场比赛中每个人只能属于一个参赛者
+func RegisterContest(contestID, userID uint64, p *models.ParamContestRegister) (err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return err
+	}
+	if !time.Now().Before(contest.EndTime()) {
+		return ErrorContestEnded
+	}
+	if contest.Visibility == models.ContestPrivate &&
+		bcrypt.CompareHashAndPassword([]byte(contest.Password), []byte(p.Password)) != nil {
+		return ErrorContestPassword
+	}
+	if p.TeamID != 0 {
+		return registerContestTeam(contestID, userID, p.TeamID)
+	}
+	teamID, err := mysql.GetContestTeamOfUser(contestID, userID)
+	if err != nil {
+		return err
+	}
+	if teamID != 0 {
+		return ErrorTeamConflict
+	}
+	return mysql.RegisterContest(contestID, userID)
+}
+
+// checkContestSubmission 在比赛中提交时，比赛需正在进行、用户已单独或随队伍报名且题目属于比赛
+func checkContestSubmission(userID uint64, p *models.ParamSubmit) error {
+	contest, err := mysql.GetContestByID(p.ContestID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if contest.Running(now) {
+		ok, err := isContestRegistered(p.ContestID, userID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrorContestNotRegistered
+		}
+	} else {
+		// 比赛结束后只有正在虚拟参赛的用户可以提交
+		if now.Before(contest.EndTime()) {
+			return ErrorContestNotRunning
+		}
+		v, err := mysql.GetVirtualParticipation(p.ContestID, userID)
+		if err != nil {
+			return err
+		}
+		if v == nil || !v.Running(now) {
+			return ErrorContestNotRunning
+		}
+	}
+	problems, err := mysql.GetContestProblems(p.ContestID)
+	if err != nil {
+		return err
+	}
+	for _, cp := range problems {
+		if cp.ProblemID == p.ProblemID {
+			return nil
+		}
+	}
+	return ErrorProblemNotInContest
+}
+
+// checkPracticeSubmission 不指定比赛提交时，题目所在的比赛须已经结束，否则参赛者可以绕过
+// OI赛制的结果隐藏和封榜直接看到评测结果。题目作者、比赛创建者和管理者不受限制
+func checkPracticeSubmission(userID uint64, role string, problem *models.Problem) error {
+	if models.IsManagerRole(role) || problem.AuthorId == userID {
+		return nil
+	}
+	contests, err := mysql.GetUnfinishedContestsOfProblem(problem.ProblemID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, contest := range contests {
+		if contest.AuthorID != userID {
+			return ErrorProblemInContest
+		}
+	}
+	return nil
+}
+
+// GetScoreboard 计算比赛排行榜，只统计比赛期间的提交。
+// OI/IOI赛制比赛结束前只有比赛创建者和管理员可以查看；
+// 封榜期间其他人看到的封榜后提交均为等待结果
+func GetScoreboard(contestID, viewerID uint64, role string) (board *ranking.Scoreboard, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := canViewContest(contest, viewerID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrorContestNotRegistered
+	}
+	now := time.Now()
+	manager := isContestManager(contest, viewerID, role)
+	if contest.HidesResults(now) && !manager {
+		return nil, ErrorScoreboardHidden
+	}
+	return contestScoreboard(contest, contest.Frozen(now) && !manager)
+}
+
+// contestScoreboard 计算比赛排行榜，frozen 为 true 时封榜后的提交均为等待结果
+func contestScoreboard(contest *models.Contest, frozen bool) (board *ranking.Scoreboard, err error) {
+	problems, participants, teamOf, err := scoreboardMembers(contest)
+	if err != nil {
+		return nil, err
+	}
+	contestSubmissions, err := getContestSubmissions(contest)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := rankingSubmissions(contest, contestSubmissions, contest.StartTime, teamOf)
+	if err != nil {
+		return nil, err
+	}
+	if frozen {
+		freezeAt := contest.FreezeTime().Sub(contest.StartTime)
+		for i := range submissions {
+			if submissions[i].Time >= freezeAt {
+				freezeSubmission(&submissions[i])
+			}
+		}
+	}
+	board = &ranking.Scoreboard{
+		Frozen: frozen,
+		Rows:   ranking.Get(contest.Rule).Rank(problems, participants, submissions),
+	}
+	return
+}
+
+// scoreboardMembers 查询排行榜的题目及报名者，teamOf 为队员到所属队伍的映射
+func scoreboardMembers(contest *models.Contest) (problems []ranking.Problem, participants []ranking.Participant,
+	teamOf map[uint64]uint64, err error) {
+	contestProblems, err := mysql.GetContestProblems(contest.ContestID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	contestParticipants, err := mysql.GetContestParticipants(contest.ContestID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	teams, err := getContestTeams(contest.ContestID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	problems = make([]ranking.Problem, 0, len(contestProblems))
+	for _, cp := range contestProblems {
+		problem := ranking.Problem{ProblemID: cp.ProblemID, Label: cp.Label}
+		// IOI赛制按子任务分值之和判断是否拿满
+		if contest.Rule == models.ContestRuleIOI {
+			subtasks, err := mysql.GetSubtaskList(cp.ProblemID)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			for _, st := range subtasks {
+				problem.MaxScore += st.Score
+			}
+		}
+		problems = append(problems, problem)
+	}
+	participants = make([]ranking.Participant, 0, len(contestParticipants))
+	for _, cp := range contestParticipants {
+		participants = append(participants, ranking.Participant{UserID: cp.UserID, Username: cp.Username})
+	}
+	teamOf = make(map[uint64]uint64)
+	for _, t := range teams {
+		members := make([]string, 0, len(t.Members))
+		for _, m := range t.Members {
+			members = append(members, m.Username)
+			teamOf[m.UserID] = t.TeamID
+		}
+		participants = append(participants, ranking.Participant{
+			UserID:   t.TeamID,
+			Username: t.Name,
+			Team:     true,
+			Members:  members,
+		})
+	}
+	return
+}
+
+// getContestTeams 查询以队伍报名的参赛者及队员
+func getContestTeams(contestID uint64) ([]*models.ContestTeam, error) {
+	teams, err := mysql.GetContestTeams(contestID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(teams))
+	byID := make(map[uint64]*models.ContestTeam, len(teams))
+	for _, t := range teams {
+		ids = append(ids, t.TeamID)
+		byID[t.TeamID] = t
+		t.Members = make([]*models.TeamMember, 0, models.TeamMaxMembers)
+	}
+	members, err := mysql.GetTeamMembers(ids...)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		byID[m.TeamID].Members = append(byID[m.TeamID].Members, m)
+	}
+	return teams, nil
+}
+
+// rankingSubmissions 转换为排名用的提交，Time 为距 start 的时间，IOI赛制附带各子任务得分，
+// 队员的提交计入所属队伍
+func rankingSubmissions(contest *models.Contest, contestSubmissions []*models.Submission, start time.Time,
+	teamOf map[uint64]uint64) ([]ranking.Submission, error) {
+	subtasks := make(map[uint64]map[int]float64)
+	if contest.Rule == models.ContestRuleIOI {
+		results, err := mysql.GetContestSubmissionSubtasks(contest.ContestID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			if subtasks[r.SubmissionID] == nil {
+				subtasks[r.SubmissionID] = make(map[int]float64)
+			}
+			subtasks[r.SubmissionID][r.Index] = r.Score
+		}
+	}
+	submissions := make([]ranking.Submission, 0, len(contestSubmissions))
+	for _, s := range contestSubmissions {
+		userID := s.UserID
+		if teamID, ok := teamOf[userID]; ok {
+			userID = teamID
+		}
+		submissions = append(submissions, ranking.Submission{
+			SubmissionID: s.SubmissionID,
+			UserID:       userID,
+			ProblemID:    s.ProblemID,
+			Status:       s.Status,
+			Score:        s.Score,
+			Subtasks:     subtasks[s.SubmissionID],
+			Time:         s.CreateTime.Sub(start),
+		})
+	}
+	return submissions, nil
+}
+
+// freezeSubmission 封榜后的提交显示为等待结果
+func freezeSubmission(s *ranking.Submission) {
+	s.Status = models.StatusPending
+	s.Score = 0
+	s.Subtasks = nil
+}
+
+// getContestSubmissions 查询比赛期间的提交，比赛开始前及结束后的提交不计入排名
+func getContestSubmissions(contest *models.Contest) ([]*models.Submission, error) {
+	submissions, err := mysql.GetContestSubmissions(contest.ContestID)
+	if err != nil {
+		return nil, err
+	}
+	end := contest.EndTime()
+	data := submissions[:0]
+	for _, s := range submissions {
+		if s.CreateTime.Before(contest.StartTime) || !s.CreateTime.Before(end) {
+			continue
+		}
+		data = append(data, s)
+	}
+	return data, nil
+}
+
+// UnfreezeContest 比赛结束后解除封榜，公布最终排行榜
+func UnfreezeContest(contestID uint64) error {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(contest.EndTime()) {
+		return ErrorContestNotEnded
+	}
+	return mysql.UnfreezeContest(contestID)
+}
+
+// resultFilter 判断提交结果对查看者是否可见，同一次请求中缓存查询过的比赛
+type resultFilter struct {
+	viewerID uint64
+	role     string
+	now      time.Time
+	contests map[uint64]*models.Contest
+	virtuals map[[2]uint64]*models.VirtualParticipation // 比赛id和用户id -> 虚拟参赛记录
+}
+
+func newResultFilter(viewerID uint64, role string) *resultFilter {
+	return &resultFilter{
+		viewerID: viewerID,
+		role:     role,
+		now:      time.Now(),
+		contests: make(map[uint64]*models.Contest),
+		virtuals: make(map[[2]uint64]*models.VirtualParticipation),
+	}
+}
+
+// hidden OI/IOI赛制比赛结束前，除比赛创建者和管理员外都看不到评测结果，提交者本人也不例外；
+// 虚拟参赛同样在虚拟比赛结束前隐藏；封榜期间看不到他人在封榜后的评测结果
+func (f *resultFilter) hidden(s *models.Submission) (bool, error) {
+	if s.ContestID == 0 || models.IsManagerRole(f.role) {
+		return false, nil
+	}
+	contest, ok := f.contests[s.ContestID]
+	if !ok {
+		var err error
+		if contest, err = mysql.GetContestByID(s.ContestID); err != nil {
+			return false, err
+		}
+		f.contests[s.ContestID] = contest
+	}
+	if contest.AuthorID == f.viewerID {
+		return false, nil
+	}
+	if contest.HidesResults(f.now) {
+		return true, nil
+	}
+	if contest.IsOIRule() && !s.CreateTime.Before(contest.EndTime()) {
+		key := [2]uint64{s.ContestID, s.UserID}
+		v, ok := f.virtuals[key]
+		if !ok {
+			var err error
+			if v, err = mysql.GetVirtualParticipation(s.ContestID, s.UserID); err != nil {
+				return false, err
+			}
+			f.virtuals[key] = v
+		}
+		return v != nil && v.Running(f.now), nil
+	}
+	return s.UserID != f.viewerID && contest.Frozen(f.now) && !s.CreateTime.Before(contest.FreezeTime()), nil
+}
+
+// hideResult 隐藏评测结果，只展示已提交
+func hideResult(s *models.Submission) {
+	s.Status = models.StatusSubmitted
+	s.Score = 0
+	s.TimeMs = 0
+	s.MemoryKb = 0
+	s.Message = ""
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"go.uber.org/zap"
+)
+
+// loadInteractor 读取并编译题目的交互器，非交互题返回 nil
+func loadInteractor(problemID uint64) (*judge.Program, error) {
+	interactor, err := mysql.GetInteractor(problemID)
+	if err != nil || interactor == nil {
+		return nil, err
+	}
+	dir := programDir("interactor", problemID, interactor.Language, interactor.Source)
+	return judge.PrepareProgram(dir, interactor.Language, interactor.Source)
+}
+
+// SaveInteractor 设置题目的交互器，立即编译，编译失败时不保存
+func SaveInteractor(problemID uint64, p *models.ParamInteractor) (interactor *models.Interactor, err error) {
+	dir := programDir("interactor", problemID, p.Language, p.Source)
+	if _, err = judge.PrepareProgram(dir, p.Language, p.Source); err != nil {
+		zap.L().Error("judge.PrepareProgram() failed", zap.Uint64("problemID", problemID), zap.Error(err))
+		return nil, err
+	}
+	interactor = &models.Interactor{
+		ProblemID: problemID,
+		Language:  p.Language,
+		Source:    p.Source,
+	}
+	if err = mysql.SaveInteractor(interactor); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func GetInteractor(problemID uint64) (*models.Interactor, error) {
+	return mysql.GetInteractor(problemID)
+}
+
+func DeleteInteractor(problemID uint64) error {
+	return mysql.DeleteInteractor(problemID)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/queue"
+	"LanShan/settings"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+const (
+	heartbeatInterval = 5 * time.Second
+	heartbeatTTL      = 3 * heartbeatInterval // 连续三次没有心跳视为失联
+	popTimeout        = 5 * time.Second
+	retryDelay        = time.Second // 评测因数据库错误中断后，重新入队前的等待时间
+)
+
+var (
+	ErrorNoTestCase      = errors.New("题目没有测试数据")
+	ErrorRunUserRequired = errors.New("以root运行评测服务时必须设置 judge.run_uid")
+)
+
+var judgeQueue queue.Queue
+
+// InitJudgeQueue 设置评测队列，提交代码后入队等待评测
+func InitJudgeQueue(q queue.Queue) {
+	judgeQueue = q
+}
+
+// StartJudgeWorkers 启动若干评测机，从队列中取出提交进行评测
+func StartJudgeWorkers(cfg *settings.JudgeConfig) (err error) {
+	if cfg.WorkDir != "" {
+		if err = os.MkdirAll(cfg.WorkDir, 0755); err != nil {
+			return
+		}
+		judge.WorkDir = cfg.WorkDir
+	}
+	if cfg.RunUID != 0 {
+		if err = initRunUser(cfg); err != nil {
+			return
+		}
+	} else if cfg.Workers > 0 && os.Geteuid() == 0 {
+		// 以root运行选手程序时 ulimit -u 不生效，且可以读写所有测试数据
+		return ErrorRunUserRequired
+	} else if cfg.Workers > 0 {
+		zap.L().Warn("judge.run_uid is not set, contestant programs run as the judge service user and can read test data")
+	}
+	hostname, _ := os.Hostname()
+	for i := 0; i < cfg.Workers; i++ {
+		// 名称包含进程号，重启后的评测机不会认领旧进程遗留的任务，由心跳超时统一放回队列
+		name := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), i)
+		go runJudgeWorker(name)
+	}
+	zap.L().Info("judge workers started", zap.Int("workers", cfg.Workers))
+	return
+}
+
+// initRunUser 以独立用户运行选手程序，并收紧数据目录的权限，使该用户无法读取测试数据
+func initRunUser(cfg *settings.JudgeConfig) (err error) {
+	if uint32(os.Geteuid()) == cfg.RunUID {
+		return errors.New("评测服务不能与选手程序使用同一个用户运行")
+	}
+	if err = os.MkdirAll(cfg.DataDir, 0700); err != nil {
+		return
+	}
+	if err = os.Chmod(cfg.DataDir, 0700); err != nil {
+		return
+	}
+	judge.RunUser = &judge.Credential{UID: cfg.RunUID, GID: cfg.RunGID}
+	return
+}
+
+func runJudgeWorker(name string) {
+	heartbeat := func() {
+		if err := judgeQueue.Heartbeat(name, heartbeatTTL); err != nil {
+			zap.L().Error("judge worker heartbeat failed", zap.String("worker", name), zap.Error(err))
+		}
+		// 顺便回收失联评测机的任务
+		if n, err := judgeQueue.Requeue(); err != nil {
+			zap.L().Error("requeue judge jobs failed", zap.Error(err))
+		} else if n > 0 {
+			zap.L().Warn("requeued judge jobs of dead workers", zap.Int("jobs", n))
+		}
+	}
+	heartbeat()
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			heartbeat()
+		}
+	}()
+
+	for {
+		submissionID, ok, err := judgeQueue.Pop(name, popTimeout)
+		if err != nil {
+			zap.L().Error("pop judge job failed", zap.String("worker", name), zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err = judgeSubmission(submissionID); err != nil {
+			// 数据库暂时不可用等情况下重新入队，稍后再评测，避免提交一直停留在等待评测
+			time.Sleep(retryDelay)
+			if err = judgeQueue.PushLow(submissionID); err != nil {
+				// 不确认任务，评测机重启后由心跳超时放回队列
+				zap.L().Error("requeue judge job failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+				continue
+			}
+		}
+		if err = judgeQueue.Ack(name, submissionID); err != nil {
+			zap.L().Error("ack judge job failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+		}
+	}
+}
+
+// judgeSubmission 评测一次提交并保存结果，状态依次为 Pending -> Judging -> 最终结果。
+// 读取提交或保存结果失败时返回错误，由调用方重新入队；评测本身的错误记为系统错误，不返回
+func judgeSubmission(submissionID uint64) (retryErr error) {
+	submission, err := mysql.GetSubmissionByID(int64(submissionID))
+	if errors.Is(err, mysql.ErrorInvalidID) {
+		// 提交已被删除
+		zap.L().Warn("judge submission not found", zap.Uint64("submissionID", submissionID))
+		return nil
+	}
+	if err != nil {
+		zap.L().Error("mysql.GetSubmissionByID() failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+		return err
+	}
+	if err = mysql.UpdateSubmissionStatus(submissionID, models.StatusJudging); err != nil {
+		return err
+	}
+	publishSubmissionEvent(&models.SubmissionEvent{
+		Type:         models.EventStatus,
+		SubmissionID: submissionID,
+		Status:       models.StatusJudging,
+	})
+
+	var (
+		cases    []*models.SubmissionCase
+		subtasks []*models.SubmissionSubtask
+	)
+	defer func() {
+		// 评测过程中的异常不能让评测机退出
+		if r := recover(); r != nil {
+			zap.L().Error("judge submission panic", zap.Uint64("submissionID", submissionID), zap.Any("panic", r))
+			submission.Status = models.StatusSystemError
+			submission.Message = fmt.Sprint(r)
+		}
+		if err := mysql.UpdateSubmissionResult(submission, cases, subtasks); err != nil {
+			zap.L().Error("mysql.UpdateSubmissionResult() failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+			retryErr = err
+			return
+		}
+		publishSubmissionResult(submission)
+	}()
+
+	result, err := runJudge(submission)
+	if err != nil {
+		zap.L().Error("judge submission failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+		submission.Status = models.StatusSystemError
+		submission.Message = err.Error()
+		return
+	}
+	submission.Status = result.Status
+	submission.TimeMs = result.TimeMs
+	submission.MemoryKb = result.MemoryKb
+	submission.Message = result.CompileMessage
+	for _, cr := range result.Cases {
+		cases = append(cases, submissionCase(cr))
+	}
+	// 按子任务计算得分
+	problemSubtasks, err := mysql.GetSubtaskList(submission.ProblemID)
+	if err != nil {
+		submission.Status = models.StatusSystemError
+		submission.Message = err.Error()
+		return
+	}
+	submission.Score, subtasks = scoreSubmission(problemSubtasks, cases)
+	return
+}
+
+// runJudge 根据题目限制和测试数据构造评测任务
+func runJudge(submission *models.Submission) (*judge.Result, error) {
+	problem, err := mysql.GetProblemByID(int64(submission.ProblemID))
+	if err != nil {
+		return nil, err
+	}
+	cases, err := mysql.GetTestCaseList(submission.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, ErrorNoTestCase
+	}
+	checker, err := loadChecker(submission.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	interactor, err := loadInteractor(submission.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	task := &judge.Task{
+		Language:    submission.Language,
+		Source:      submission.Source,
+		TimeLimit:   problem.TimeLimit,
+		MemoryLimit: problem.MemoryLimit,
+		Checker:     checker,
+		Interactor:  interactor,
+		TestCases:   make([]judge.TestCase, 0, len(cases)),
+		OnCase: func(cr *judge.CaseResult) {
+			publishSubmissionEvent(&models.SubmissionEvent{
+				Type:         models.EventCase,
+				SubmissionID: submission.SubmissionID,
+				Status:       models.StatusJudging,
+				Case:         submissionCase(cr),
+			})
+		},
+	}
+	for _, tc := range cases {
+		input, answer := TestCaseFiles(tc.ProblemID, tc.Index)
+		task.TestCases = append(task.TestCases, judge.TestCase{Index: tc.Index, Input: input, Answer: answer})
+	}
+	return judge.Run(task)
+}
+
+func submissionCase(cr *judge.CaseResult) *models.SubmissionCase {
+	return &models.SubmissionCase{
+		Index:    cr.Index,
+		Status:   cr.Status,
+		Score:    cr.Score,
+		TimeMs:   cr.TimeMs,
+		MemoryKb: cr.MemoryKb,
+		Message:  cr.Message,
+	}
+}
+
+// GetLanguageList 获取已启用的评测语言
+func GetLanguageList() []*judge.Language {
+	return judge.Languages()
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"LanShan/settings"
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestStartJudgeWorkersRequiresRunUser 以root运行且未设置运行用户时拒绝启动评测机
+func TestStartJudgeWorkersRequiresRunUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root")
+	}
+	err := StartJudgeWorkers(&settings.JudgeConfig{DataDir: t.TempDir(), Workers: 1})
+	if !errors.Is(err, ErrorRunUserRequired) {
+		t.Errorf("StartJudgeWorkers() error = %v, want %v", err, ErrorRunUserRequired)
+	}
+}
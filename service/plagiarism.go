@@ -0,0 +1,222 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/plagiarism"
+	"LanShan/utils/snowflake"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+)
+
+const (
+	plagiarismMinSimilarity = 0.5 // 相似度不低于该值的提交对才列入报告
+	plagiarismMaxPairs      = 200 // 报告最多保留的提交对数量
+)
+
+var ErrorPlagiarismScope = errors.New("需要指定比赛或题目")
+
+// StartPlagiarismCheck 发起查重，在后台比较，通过 GetPlagiarismReport 查看结果
+func StartPlagiarismCheck(userID uint64, p *models.ParamPlagiarism) (report *models.PlagiarismReport, err error) {
+	if p.ContestID == 0 && p.ProblemID == 0 {
+		return nil, ErrorPlagiarismScope
+	}
+	if p.ContestID != 0 {
+		if _, err = mysql.GetContestByID(p.ContestID); err != nil {
+			return nil, err
+		}
+	}
+	if p.ProblemID != 0 {
+		if _, err = mysql.GetProblemByID(int64(p.ProblemID)); err != nil {
+			return nil, err
+		}
+	}
+	reportID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	report = &models.PlagiarismReport{
+		ReportID:  reportID,
+		UserID:    userID,
+		ContestID: p.ContestID,
+		ProblemID: p.ProblemID,
+		Status:    models.PlagiarismRunning,
+	}
+	if err = mysql.CreatePlagiarismReport(report); err != nil {
+		return nil, err
+	}
+	// 后台任务修改的是副本，不影响返回给调用方的结果
+	task := *report
+	go runPlagiarismCheck(&task)
+	return
+}
+
+// plagiarismGroup 同一道题、同一种语言的提交互相比较
+type plagiarismGroup struct {
+	problemID uint64
+	language  string
+}
+
+// runPlagiarismCheck 每个用户在每道题每种语言只取最后一次提交，
+// 同一道题、同一种语言中不同用户的提交两两比较
+func runPlagiarismCheck(report *models.PlagiarismReport) {
+	// 比较过程中 panic 时记为失败，避免报告一直处于进行中并拖垮整个进程
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("plagiarism check panic", zap.Uint64("reportID", report.ReportID), zap.Any("panic", r))
+			report.Status = models.PlagiarismFailed
+			report.Message = fmt.Sprint(r)
+			if err := mysql.FinishPlagiarismReport(report, nil); err != nil {
+				zap.L().Error("save plagiarism report failed", zap.Uint64("reportID", report.ReportID), zap.Error(err))
+			}
+		}
+	}()
+	pairs, err := comparePlagiarismSubmissions(report)
+	if err != nil {
+		zap.L().Error("plagiarism check failed", zap.Uint64("reportID", report.ReportID), zap.Error(err))
+		report.Status = models.PlagiarismFailed
+		report.Message = err.Error()
+		pairs = nil
+	} else {
+		report.Status = models.PlagiarismFinished
+	}
+	if err = mysql.FinishPlagiarismReport(report, pairs); err != nil {
+		zap.L().Error("save plagiarism report failed", zap.Uint64("reportID", report.ReportID), zap.Error(err))
+	}
+}
+
+func comparePlagiarismSubmissions(report *models.PlagiarismReport) ([]*models.PlagiarismPair, error) {
+	submissions, err := mysql.GetPlagiarismSubmissions(report.ContestID, report.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	// 组队赛中同队队员共享代码是正常的，不互相比较
+	teamOf := make(map[uint64]uint64)
+	if report.ContestID != 0 {
+		teams, err := getContestTeams(report.ContestID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams {
+			for _, m := range t.Members {
+				teamOf[m.UserID] = t.TeamID
+			}
+		}
+	}
+	pairs := findPlagiarismPairs(report, submissions, teamOf)
+	for _, p := range pairs {
+		if p.PairID, err = snowflake.GetID(); err != nil {
+			return nil, mysql.ErrorGenIDFailed
+		}
+	}
+	return pairs, nil
+}
+
+// findPlagiarismPairs 比较按时间排序的提交，返回相似度最高的若干提交对，teamOf 为队员到所属队伍的映射
+func findPlagiarismPairs(report *models.PlagiarismReport, submissions []*models.Submission,
+	teamOf map[uint64]uint64) []*models.PlagiarismPair {
+	// 按时间顺序覆盖，保留每个用户最后一次提交
+	type key struct {
+		group  plagiarismGroup
+		userID uint64
+	}
+	latest := make(map[key]*models.Submission)
+	for _, s := range submissions {
+		latest[key{plagiarismGroup{s.ProblemID, s.Language}, s.UserID}] = s
+	}
+	groups := make(map[plagiarismGroup][]*models.Submission)
+	for k, s := range latest {
+		groups[k.group] = append(groups[k.group], s)
+	}
+
+	var pairs []*models.PlagiarismPair
+	for group, list := range groups {
+		// 按提交id排序，保证结果稳定，且 A 总是较早的提交
+		sort.Slice(list, func(i, j int) bool { return list[i].SubmissionID < list[j].SubmissionID })
+		docs := make([]*plagiarism.Document, len(list))
+		for i, s := range list {
+			docs[i] = plagiarism.NewDocument(group.language, s.Source)
+		}
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if team := teamOf[list[i].UserID]; team != 0 && team == teamOf[list[j].UserID] {
+					continue
+				}
+				report.Compared++
+				result := plagiarism.Compare(docs[i], docs[j])
+				if result.Similarity < plagiarismMinSimilarity {
+					continue
+				}
+				matches := make(models.PlagiarismMatches, 0, len(result.Matches))
+				for _, m := range result.Matches {
+					matches = append(matches, models.PlagiarismMatch{
+						AStartLine: m.AStartLine,
+						AEndLine:   m.AEndLine,
+						BStartLine: m.BStartLine,
+						BEndLine:   m.BEndLine,
+					})
+				}
+				pairs = append(pairs, &models.PlagiarismPair{
+					ReportID:    report.ReportID,
+					ProblemID:   group.problemID,
+					Language:    group.language,
+					SubmissionA: list[i].SubmissionID,
+					UserA:       list[i].UserID,
+					SubmissionB: list[j].SubmissionID,
+					UserB:       list[j].UserID,
+					Similarity:  result.Similarity,
+					Matches:     matches,
+				})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		return pairs[i].SubmissionA < pairs[j].SubmissionA ||
+			pairs[i].SubmissionA == pairs[j].SubmissionA && pairs[i].SubmissionB < pairs[j].SubmissionB
+	})
+	if len(pairs) > plagiarismMaxPairs {
+		pairs = pairs[:plagiarismMaxPairs]
+	}
+	return pairs
+}
+
+// GetPlagiarismReport 查询查重报告
+func GetPlagiarismReport(reportID uint64) (data *models.ApiPlagiarismReport, err error) {
+	report, err := mysql.GetPlagiarismReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := mysql.GetPlagiarismPairs(reportID)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiPlagiarismReport{PlagiarismReport: report, Pairs: pairs}
+	return
+}
+
+// GetPlagiarismPair 查询可疑提交对的两份源代码及相同片段
+func GetPlagiarismPair(reportID, pairID uint64) (data *models.ApiPlagiarismPair, err error) {
+	pair, err := mysql.GetPlagiarismPair(pairID)
+	if err != nil {
+		return nil, err
+	}
+	if pair.ReportID != reportID {
+		return nil, mysql.ErrorInvalidID
+	}
+	a, err := mysql.GetSubmissionByID(int64(pair.SubmissionA))
+	if err != nil {
+		return nil, err
+	}
+	b, err := mysql.GetSubmissionByID(int64(pair.SubmissionB))
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiPlagiarismPair{PlagiarismPair: pair, SourceA: a.Source, SourceB: b.Source}
+	return
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"LanShan/models"
+	"testing"
+)
+
+const plagiarismSource = `#include <stdio.h>
+int main(void) {
+	int n, sum = 0;
+	scanf("%d", &n);
+	for (int i = 1; i <= n; i++) {
+		if (i % 3 == 0 || i % 5 == 0) {
+			sum += i;
+		}
+	}
+	printf("%d\n", sum);
+	return 0;
+}
+`
+
+// TestFindPlagiarismPairsSkipsTeammates 同队队员的提交不互相比较，其余用户照常比较
+func TestFindPlagiarismPairsSkipsTeammates(t *testing.T) {
+	submissions := make([]*models.Submission, 0, 3)
+	for i, userID := range []uint64{1, 2, 3} {
+		submissions = append(submissions, &models.Submission{
+			SubmissionID: uint64(i + 1),
+			ProblemID:    100,
+			UserID:       userID,
+			Language:     "c",
+			Source:       plagiarismSource,
+		})
+	}
+	report := &models.PlagiarismReport{ReportID: 1, ContestID: 10}
+	// 用户1、2同队
+	pairs := findPlagiarismPairs(report, submissions, map[uint64]uint64{1: 50, 2: 50})
+	if report.Compared != 2 {
+		t.Errorf("Compared = %d, want 2", report.Compared)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("pairs = %d, want 2", len(pairs))
+	}
+	for _, p := range pairs {
+		if p.UserA != 3 && p.UserB != 3 {
+			t.Errorf("pair %d-%d is between teammates", p.UserA, p.UserB)
+		}
+		if p.Similarity != 1 {
+			t.Errorf("pair %d-%d similarity = %v, want 1", p.UserA, p.UserB, p.Similarity)
+		}
+	}
+
+	// 不区分队伍时三人两两比较
+	report = &models.PlagiarismReport{ReportID: 2}
+	if pairs = findPlagiarismPairs(report, submissions, nil); len(pairs) != 3 || report.Compared != 3 {
+		t.Errorf("pairs = %d, compared = %d, want 3, 3", len(pairs), report.Compared)
+	}
+}
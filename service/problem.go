@@ -2,12 +2,16 @@ package service
 
 import (
 	"LanShan/dao/mysql"
+	"LanShan/markdown"
 	"LanShan/models"
 	"LanShan/utils/snowflake"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 )
 
+var ErrorProblemNotStarted = errors.New("题目所在的比赛尚未开始")
+
 func GetCommunityList() ([]*models.Community, error) {
 	// 查数据库 查找到所有的community 并返回
 	return mysql.GetCommunityList()
@@ -25,11 +29,28 @@ func CreateProblem(problem *models.Problem) (err error) {
 		return
 	}
 	problem.ProblemID = problemID
+	if problem.TimeLimit == 0 {
+		problem.TimeLimit = models.DefaultTimeLimit
+	}
+	if problem.MemoryLimit == 0 {
+		problem.MemoryLimit = models.DefaultMemoryLimit
+	}
+	// 先校验标签，避免题目创建后才发现标签不存在
+	tagIDs, err := getTagIDs(problem.Tags)
+	if err != nil {
+		return
+	}
 	// 2、创建问题 保存到数据库
 	if err := mysql.CreateProblem(problem); err != nil {
 		zap.L().Error("mysql.CreateProblem(&problem) failed", zap.Error(err))
 		return err
 	}
+	if len(tagIDs) > 0 {
+		if err = mysql.SetProblemTags(problemID, tagIDs); err != nil {
+			zap.L().Error("mysql.SetProblemTags() failed", zap.Error(err))
+			return
+		}
+	}
 
 	return
 }
@@ -59,23 +80,73 @@ func GetProblemById(problemID int64) (data *models.ApiProblemDetail, err error)
 			zap.Error(err))
 		return
 	}
+	if err = fillProblemTags(problem); err != nil {
+		return
+	}
+	stat, err := mysql.GetProblemStat(problem.ProblemID)
+	if err != nil {
+		return
+	}
+	problem.EnsureStatement()
 	// 接口数据拼接
 	data = &models.ApiProblemDetail{
 		Problem:         problem,
 		CommunityDetail: community,
 		AuthorName:      user.UserName,
+		ProblemStat:     *stat,
+		Rendered:        renderStatement(&problem.Statement),
 	}
 	return
 }
 
-func GetProblemList(page, size int64) (data []*models.ApiProblemDetail, err error) {
-	problemList, err := mysql.GetProblemList(page, size)
+// getVisibleProblem 查询题目，尚未开始的比赛中的题目只有题目作者、比赛创建者和管理者可见
+func getVisibleProblem(problemID uint64, viewer *models.ProblemViewer) (*models.Problem, error) {
+	problem, err := mysql.GetProblemByID(int64(problemID))
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := mysql.IsProblemHidden(problemID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrorProblemNotStarted
+	}
+	return problem, nil
+}
+
+// renderStatement 把题面各部分渲染为过滤后的 HTML，样例原样展示不做渲染
+func renderStatement(s *models.Statement) *models.RenderedStatement {
+	return &models.RenderedStatement{
+		Background:   markdown.Render(s.Background),
+		Description:  markdown.Render(s.Description),
+		InputFormat:  markdown.Render(s.InputFormat),
+		OutputFormat: markdown.Render(s.OutputFormat),
+		Notes:        markdown.Render(s.Notes),
+	}
+}
+
+// GetProblemList 题目列表，有 prefs 中语言的译文时展示译文
+func GetProblemList(p *models.ParamProblemList, prefs []string, viewer *models.ProblemViewer) (data []*models.ApiProblemDetail, err error) {
+	problemList, err := mysql.GetProblemList(p, viewer)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
+	problems := make([]*models.Problem, 0, len(problemList))
+	for _, item := range problemList {
+		problems = append(problems, &item.Problem)
+	}
+	if err = fillProblemTags(problems...); err != nil {
+		return
+	}
+	if err = localizeProblems(problems, prefs); err != nil {
+		return
+	}
 	data = make([]*models.ApiProblemDetail, 0, len(problemList)) // data 初始化
-	for _, problem := range problemList {
+	for _, item := range problemList {
+		problem := &item.Problem
+		problem.EnsureStatement()
 		// 根据作者id查询作者信息
 		user, err := mysql.GetUserByID(problem.AuthorId)
 		if err != nil {
@@ -97,13 +168,15 @@ func GetProblemList(page, size int64) (data []*models.ApiProblemDetail, err erro
 			Problem:         problem,
 			CommunityDetail: community,
 			AuthorName:      user.UserName,
+			ProblemStat:     item.ProblemStat,
 		}
 		data = append(data, problemdetail)
 	}
 	return
 }
 
-func UpdateProblem(newProblem *models.Problem, pastProblemID int64) (data *models.ApiProblemDetail, err error) {
+// UpdateProblem 修改题目并记录新版本，资源限制和难度为0时保持不变，未指定标签时保留原有标签
+func UpdateProblem(newProblem *models.Problem, pastProblemID int64, editorID uint64) (data *models.ApiProblemDetail, err error) {
 	// 查询信息
 	pastProblem, err := mysql.GetProblemByID(pastProblemID)
 	if err != nil {
@@ -112,13 +185,40 @@ func UpdateProblem(newProblem *models.Problem, pastProblemID int64) (data *model
 			zap.Error(err))
 		return nil, err
 	}
-	problem, err := mysql.UpdateProblem(newProblem, pastProblem)
-	if err != nil {
-		zap.L().Error("mysql.UpdateProblem() failed", zap.Error(err))
-		return nil, err
+	var tagIDs []uint64
+	if newProblem.Tags != nil {
+		if tagIDs, err = getTagIDs(newProblem.Tags); err != nil {
+			return nil, err
+		}
+	}
+	problem := *pastProblem
+	problem.Title = newProblem.Title
+	problem.Content = newProblem.Content
+	problem.Statement = newProblem.Statement
+	if newProblem.TimeLimit != 0 {
+		problem.TimeLimit = newProblem.TimeLimit
+	}
+	if newProblem.MemoryLimit != 0 {
+		problem.MemoryLimit = newProblem.MemoryLimit
+	}
+	if newProblem.Difficulty != 0 {
+		problem.Difficulty = newProblem.Difficulty
+	}
+	// 只修改标签时不产生新版本
+	if problemChanged(&problem, pastProblem) {
+		if err = mysql.UpdateProblem(&problem, pastProblem, editorID, ""); err != nil {
+			zap.L().Error("mysql.UpdateProblem() failed", zap.Error(err))
+			return nil, err
+		}
+	}
+	if newProblem.Tags != nil {
+		if err = mysql.SetProblemTags(pastProblem.ProblemID, tagIDs); err != nil {
+			zap.L().Error("mysql.SetProblemTags() failed", zap.Error(err))
+			return nil, err
+		}
 	}
 
-	data, err = GetProblemById(int64(problem.ProblemID))
+	data, err = GetProblemById(pastProblemID)
 	if err != nil {
 		zap.L().Error("GetProblemById failed", zap.Error(err))
 		return nil, err
@@ -126,9 +226,14 @@ func UpdateProblem(newProblem *models.Problem, pastProblemID int64) (data *model
 	return
 }
 
+// problemChanged 题面、资源限制或难度是否有变化
+func problemChanged(a, b *models.Problem) bool {
+	return a.Title != b.Title || a.Content != b.Content || a.TimeLimit != b.TimeLimit ||
+		a.MemoryLimit != b.MemoryLimit || a.Difficulty != b.Difficulty
+}
+
 func DeleteProblem(problemID int64) (err error) {
-	mysql.DeleteProblem(problemID)
-	if err != nil {
+	if err = mysql.DeleteProblem(problemID); err != nil {
 		zap.L().Error("mysql.DeleteProblem() failed", zap.Error(err))
 		return
 	}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/problemio"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrorProblemImport = errors.New("题目包内容不符合要求")
+
+// ImportProblems 导入 Polygon 题目包或 FPS XML，一个 FPS 文件可以包含多道题目。
+// 全部解析和校验通过后才开始创建，中途失败时删除本次已创建的题目
+func ImportProblems(userID uint64, p *models.ParamProblemImport, r io.ReaderAt, size int64) (data []*models.ApiProblemImport, err error) {
+	packages, err := problemio.Parse(r, size)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]*models.Problem, 0, len(packages))
+	for _, pkg := range packages {
+		problem, err := packageToProblem(pkg)
+		if err != nil {
+			return nil, err
+		}
+		problem.AuthorId = userID
+		problem.CommunityID = p.CommunityID
+		problems = append(problems, problem)
+	}
+	data = make([]*models.ApiProblemImport, 0, len(packages))
+	created := make([]uint64, 0, len(packages))
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, problemID := range created {
+			removeImportedProblem(problemID)
+		}
+	}()
+	for i, pkg := range packages {
+		if err = CreateProblem(problems[i]); err != nil {
+			return nil, err
+		}
+		created = append(created, problems[i].ProblemID)
+		item := &models.ApiProblemImport{
+			ProblemID: problems[i].ProblemID,
+			Title:     problems[i].Title,
+			TestNum:   len(pkg.Tests),
+			Warnings:  append([]string{}, pkg.Warnings...),
+		}
+		data = append(data, item)
+		if err = importProblemData(item, pkg); err != nil {
+			zap.L().Error("import problem data failed", zap.Uint64("problemID", item.ProblemID), zap.Error(err))
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// packageToProblem 把题目包中的题面转换为结构化题面
+func packageToProblem(pkg *problemio.Problem) (problem *models.Problem, err error) {
+	statement := models.Statement{
+		Description:  pkg.Legend,
+		InputFormat:  pkg.InputFormat,
+		OutputFormat: pkg.OutputFormat,
+		Samples:      make([]*models.Sample, 0, len(pkg.Samples)),
+		Notes:        pkg.Notes,
+	}
+	for _, sample := range pkg.Samples {
+		input, err := sample.Input.ReadText()
+		if err != nil {
+			return nil, err
+		}
+		output, err := sample.Output.ReadText()
+		if err != nil {
+			return nil, err
+		}
+		statement.Samples = append(statement.Samples, &models.Sample{Input: string(input), Output: string(output)})
+	}
+	problem = &models.Problem{
+		Title:       pkg.Title,
+		Content:     statement.Markdown(),
+		Statement:   statement,
+		Locale:      models.DefaultLocale,
+		TimeLimit:   pkg.TimeLimit,
+		MemoryLimit: pkg.MemoryLimit,
+	}
+	switch {
+	case utf8.RuneCountInString(problem.Title) > 128:
+		return nil, fmt.Errorf("%w: 标题过长", ErrorProblemImport)
+	case problem.Content == "":
+		return nil, fmt.Errorf("%w: %s 缺少题面", ErrorProblemImport, problem.Title)
+	case utf8.RuneCountInString(problem.Content) > models.MaxContentLength:
+		return nil, fmt.Errorf("%w: %s 的题面超过%d字", ErrorProblemImport, problem.Title, models.MaxContentLength)
+	case len(pkg.Tests) == 0:
+		return nil, fmt.Errorf("%w: %s 没有测试数据", ErrorProblemImport, problem.Title)
+	}
+	return problem, nil
+}
+
+// importProblemData 保存测试数据、checker和参考程序，checker编译失败只记录警告
+func importProblemData(item *models.ApiProblemImport, pkg *problemio.Problem) (err error) {
+	for i, test := range pkg.Tests {
+		if err = importTestCase(item.ProblemID, i+1, test); err != nil {
+			return
+		}
+	}
+	if c := pkg.Checker; c != nil {
+		p := &models.ParamChecker{Type: c.Type, Epsilon: c.Epsilon, Language: c.Language, Source: c.Source}
+		if _, err := SaveChecker(item.ProblemID, p); err != nil {
+			zap.L().Warn("import checker failed", zap.Uint64("problemID", item.ProblemID), zap.Error(err))
+			item.Warnings = append(item.Warnings, "checker 编译失败，已按默认方式比较")
+		}
+	}
+	solutions := make([]*models.ProblemSolution, 0, len(pkg.Solutions))
+	for _, s := range pkg.Solutions {
+		solutions = append(solutions, &models.ProblemSolution{Tag: s.Tag, Language: s.Language, Source: s.Source})
+	}
+	return mysql.ReplaceProblemSolutions(item.ProblemID, solutions)
+}
+
+func importTestCase(problemID uint64, index int, test *problemio.Test) (err error) {
+	input, err := test.Input()
+	if err != nil {
+		return
+	}
+	defer input.Close()
+	output, err := test.Output()
+	if err != nil {
+		return
+	}
+	defer output.Close()
+	_, err = SaveTestCase(problemID, index, input, output)
+	return
+}
+
+// removeImportedProblem 删除导入失败的题目及其测试数据
+func removeImportedProblem(problemID uint64) {
+	if err := mysql.DeleteProblem(int64(problemID)); err != nil {
+		zap.L().Error("delete imported problem failed", zap.Uint64("problemID", problemID), zap.Error(err))
+	}
+	if err := mysql.ReplaceTestCases(problemID, nil); err != nil {
+		zap.L().Error("delete imported testcases failed", zap.Uint64("problemID", problemID), zap.Error(err))
+	}
+	if err := os.RemoveAll(testCaseDir(problemID)); err != nil {
+		zap.L().Error("remove imported testcases failed", zap.Uint64("problemID", problemID), zap.Error(err))
+	}
+}
+
+// ProblemExport 准备好的导出内容，写出时才读取测试数据文件
+type ProblemExport struct {
+	Filename    string
+	ContentType string
+	format      string
+	problem     *problemio.Problem
+}
+
+// ExportProblem 把题目导出为 Polygon 题目包或 FPS XML，题目背景放在题目描述之前
+func ExportProblem(problemID uint64, format string) (export *ProblemExport, err error) {
+	problem, err := mysql.GetProblemByID(int64(problemID))
+	if err != nil {
+		return nil, err
+	}
+	problem.EnsureStatement()
+	s := &problem.Statement
+	legend := s.Description
+	if s.Background != "" {
+		legend = s.Background + "\n\n" + s.Description
+	}
+	pkg := &problemio.Problem{
+		Title:        problem.Title,
+		Legend:       legend,
+		InputFormat:  s.InputFormat,
+		OutputFormat: s.OutputFormat,
+		Notes:        s.Notes,
+		TimeLimit:    problem.TimeLimit,
+		MemoryLimit:  problem.MemoryLimit,
+	}
+	for _, sample := range s.Samples {
+		pkg.Samples = append(pkg.Samples, &problemio.Test{
+			Input:  problemio.BytesOpener([]byte(sample.Input)),
+			Output: problemio.BytesOpener([]byte(sample.Output)),
+		})
+	}
+	cases, err := mysql.GetTestCaseList(problemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tc := range cases {
+		input, output := TestCaseFiles(problemID, tc.Index)
+		pkg.Tests = append(pkg.Tests, &problemio.Test{Input: fileOpener(input), Output: fileOpener(output)})
+	}
+	checker, err := mysql.GetChecker(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if checker != nil {
+		pkg.Checker = &problemio.Checker{
+			Type:     checker.Type,
+			Epsilon:  checker.Epsilon,
+			Language: checker.Language,
+			Source:   checker.Source,
+		}
+	}
+	solutions, err := mysql.GetProblemSolutions(problemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range solutions {
+		pkg.Solutions = append(pkg.Solutions, &problemio.Solution{Tag: s.Tag, Language: s.Language, Source: s.Source})
+	}
+	export = &ProblemExport{format: format, problem: pkg}
+	if format == problemio.FormatPolygon {
+		export.Filename = fmt.Sprintf("problem-%d.zip", problemID)
+		export.ContentType = "application/zip"
+	} else {
+		export.Filename = fmt.Sprintf("problem-%d.xml", problemID)
+		export.ContentType = "application/xml"
+	}
+	return export, nil
+}
+
+// Write 写出导出内容
+func (e *ProblemExport) Write(w io.Writer) error {
+	if e.format == problemio.FormatPolygon {
+		return problemio.WritePolygon(w, e.problem, strings.TrimSuffix(e.Filename, ".zip"))
+	}
+	return problemio.WriteFPS(w, e.problem)
+}
+
+func fileOpener(name string) problemio.Opener {
+	return func() (io.ReadCloser, error) { return os.Open(name) }
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/ranking"
+	"LanShan/rating"
+	"errors"
+	"time"
+)
+
+var (
+	ErrorContestNotRated    = errors.New("该比赛不计算等级分")
+	ErrorRatingFinalized    = errors.New("该比赛的等级分已经计算过")
+	ErrorRatingNoContestant = errors.New("没有可以计算等级分的参赛者")
+)
+
+// participated 参赛者是否在比赛中有过有效提交，只报名未提交的不计算等级分
+func participated(row *ranking.Row) bool {
+	for _, cell := range row.Cells {
+		if cell.Accepted || cell.Attempts > 0 || cell.Pending > 0 || cell.Score > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FinalizeRating 比赛结束后根据最终排名计算等级分，每场比赛只能计算一次。
+// 等级分是个人的，以队伍报名的参赛者不参与计算
+func FinalizeRating(contestID uint64) (changes []*models.RatingChange, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if !contest.Rated {
+		return nil, ErrorContestNotRated
+	}
+	if time.Now().Before(contest.EndTime()) {
+		return nil, ErrorContestNotEnded
+	}
+	count, err := mysql.CountRatingChanges(contestID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrorRatingFinalized
+	}
+	board, err := contestScoreboard(contest, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 去掉队伍和未提交的参赛者后重新计算名次，并列的名次保持相同
+	rows := make([]*ranking.Row, 0, len(board.Rows))
+	for _, row := range board.Rows {
+		if !row.Team && participated(row) {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, ErrorRatingNoContestant
+	}
+	userIDs := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		userIDs = append(userIDs, row.UserID)
+	}
+	ratings, err := mysql.GetUserRatings(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	contestants := make([]rating.Contestant, 0, len(rows))
+	rank := 0
+	for i, row := range rows {
+		if i == 0 || row.Rank != rows[i-1].Rank {
+			rank = i + 1
+		}
+		r, ok := ratings[row.UserID]
+		if !ok {
+			r = rating.InitialRating
+		}
+		contestants = append(contestants, rating.Contestant{UserID: row.UserID, Rank: rank, Rating: r})
+	}
+
+	results := rating.Calculate(contestants)
+	changes = make([]*models.RatingChange, 0, len(results))
+	for _, r := range results {
+		changes = append(changes, &models.RatingChange{
+			ContestID:    contestID,
+			ContestTitle: contest.Title,
+			UserID:       r.UserID,
+			Rank:         r.Rank,
+			OldRating:    r.OldRating,
+			NewRating:    r.NewRating,
+			Delta:        r.Delta(),
+		})
+	}
+	if err = mysql.SaveRatingChanges(contestID, changes); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// GetUserRating 查询用户当前等级分、称号及历史变化
+func GetUserRating(userID uint64) (data *models.ApiUserRating, err error) {
+	current, err := mysql.GetUserRating(userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := mysql.GetUserRatingHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiUserRating{
+		UserID:    userID,
+		Rating:    current,
+		MaxRating: current,
+		Title:     rating.Title(current),
+		History:   history,
+	}
+	for _, h := range history {
+		h.Delta = h.NewRating - h.OldRating
+		if h.NewRating > data.MaxRating {
+			data.MaxRating = h.NewRating
+		}
+	}
+	return
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/utils/snowflake"
+	"errors"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	ErrorRejudgeCondition = errors.New("至少指定题目、用户、提交id或时间范围中的一个条件")
+	ErrorRejudgeEmpty     = errors.New("没有符合条件的提交")
+)
+
+// Rejudge 重新评测符合条件的提交，原结果存入历史表，任务以低优先级入队，不影响正常提交的评测
+func Rejudge(userID uint64, p *models.ParamRejudge) (rejudge *models.Rejudge, err error) {
+	if p.ProblemID == 0 && p.UserID == 0 && len(p.SubmissionIDs) == 0 && p.StartTime.IsZero() && p.EndTime.IsZero() {
+		return nil, ErrorRejudgeCondition
+	}
+	submissionIDs := make([]uint64, 0, len(p.SubmissionIDs))
+	for _, s := range p.SubmissionIDs {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, mysql.ErrorInvalidID
+		}
+		submissionIDs = append(submissionIDs, id)
+	}
+	ids, err := mysql.GetRejudgeSubmissionIDs(p, submissionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrorRejudgeEmpty
+	}
+
+	rejudgeID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	rejudge = &models.Rejudge{
+		RejudgeID: rejudgeID,
+		UserID:    userID,
+		Total:     int64(len(ids)),
+	}
+	if err = mysql.CreateRejudge(rejudge, ids); err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		if err = judgeQueue.PushLow(id); err != nil {
+			zap.L().Error("judgeQueue.PushLow() failed", zap.Uint64("submissionID", id), zap.Error(err))
+			// 未入队的提交不会被评测，标记为系统错误，避免一直停留在等待评测
+			if uerr := mysql.UpdateSubmissionsStatus(ids[i:], models.StatusSystemError); uerr != nil {
+				zap.L().Error("mark rejudge submissions failed", zap.Uint64("rejudgeID", rejudgeID), zap.Error(uerr))
+			}
+			return nil, err
+		}
+	}
+	zap.L().Info("rejudge created",
+		zap.Uint64("rejudgeID", rejudgeID),
+		zap.Uint64("userID", userID),
+		zap.Int("total", len(ids)))
+	return
+}
+
+// GetRejudge 查询重新评测进度
+func GetRejudge(rejudgeID uint64) (*models.Rejudge, error) {
+	return mysql.GetRejudgeByID(rejudgeID)
+}
+
+// GetSubmissionHistory 查询提交重新评测前的结果
+func GetSubmissionHistory(submissionID, viewerID uint64, role string) ([]*models.SubmissionHistory, error) {
+	submission, err := mysql.GetSubmissionByID(int64(submissionID))
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := newResultFilter(viewerID, role).hidden(submission)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return []*models.SubmissionHistory{}, nil
+	}
+	return mysql.GetSubmissionHistory(submissionID)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/ranking"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// 提交状态对应的 CLICS 判题结果，未列出的按 WA 处理
+var feedJudgementTypes = []*models.FeedJudgementType{
+	{ID: "AC", Name: "accepted", Penalty: false, Solved: true},
+	{ID: "WA", Name: "wrong answer", Penalty: true, Solved: false},
+	{ID: "TLE", Name: "time limit exceeded", Penalty: true, Solved: false},
+	{ID: "MLE", Name: "memory limit exceeded", Penalty: true, Solved: false},
+	{ID: "RTE", Name: "run-time error", Penalty: true, Solved: false},
+	{ID: "OLE", Name: "output limit exceeded", Penalty: true, Solved: false},
+	{ID: "PE", Name: "presentation error", Penalty: true, Solved: false},
+	{ID: "CE", Name: "compiler error", Penalty: false, Solved: false},
+	{ID: "JE", Name: "judging error", Penalty: false, Solved: false},
+}
+
+func feedJudgementType(status string) string {
+	switch status {
+	case models.StatusAccepted:
+		return "AC"
+	case models.StatusTimeLimitExceeded:
+		return "TLE"
+	case models.StatusMemoryLimitExceeded:
+		return "MLE"
+	case models.StatusRuntimeError:
+		return "RTE"
+	case models.StatusOutputLimitExceeded:
+		return "OLE"
+	case models.StatusPresentationError:
+		return "PE"
+	case models.StatusCompileError:
+		return "CE"
+	case models.StatusSystemError:
+		return "JE"
+	default:
+		return "WA"
+	}
+}
+
+// feedTime CLICS 绝对时间
+func feedTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// feedRelTime CLICS 相对时间 h:mm:ss.sss
+func feedRelTime(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// GetResolverEvents 导出比赛的完整事件序列，包含封榜后的真实结果，供滚榜程序回放
+func GetResolverEvents(contestID uint64) (events []*models.FeedEvent, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	problems, err := mysql.GetContestProblems(contestID)
+	if err != nil {
+		return nil, err
+	}
+	// 队伍参赛时以队伍作为 CLICS 中的 team
+	_, participants, teamOf, err := scoreboardMembers(contest)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := getContestSubmissions(contest)
+	if err != nil {
+		return nil, err
+	}
+
+	add := func(typ, id string, data interface{}) {
+		events = append(events, &models.FeedEvent{Type: typ, ID: id, Op: "create", Data: data})
+	}
+	id := strconv.FormatUint(contest.ContestID, 10)
+	feedContest := &models.FeedContest{
+		ID:          id,
+		Name:        contest.Title,
+		FormalName:  contest.Title,
+		StartTime:   feedTime(contest.StartTime),
+		Duration:    feedRelTime(time.Duration(contest.Duration) * time.Minute),
+		PenaltyTime: ranking.DefaultPenalty,
+	}
+	if contest.Freeze > 0 {
+		feedContest.ScoreboardFreezeDuration = feedRelTime(time.Duration(contest.Freeze) * time.Minute)
+	}
+	add(models.FeedContests, id, feedContest)
+	for _, jt := range feedJudgementTypes {
+		add(models.FeedJudgementTypes, jt.ID, jt)
+	}
+	for i, p := range problems {
+		pid := strconv.FormatUint(p.ProblemID, 10)
+		add(models.FeedProblems, pid, &models.FeedProblem{ID: pid, Label: p.Label, Name: p.Title, Ordinal: i})
+	}
+	for _, p := range participants {
+		uid := strconv.FormatUint(p.UserID, 10)
+		add(models.FeedTeams, uid, &models.FeedTeam{ID: uid, Name: p.Username})
+	}
+	judged := true
+	for _, s := range submissions {
+		sid := strconv.FormatUint(s.SubmissionID, 10)
+		contestTime := feedRelTime(s.CreateTime.Sub(contest.StartTime))
+		teamID := s.UserID
+		if t, ok := teamOf[teamID]; ok {
+			teamID = t
+		}
+		add(models.FeedSubmissions, sid, &models.FeedSubmission{
+			ID:          sid,
+			LanguageID:  s.Language,
+			ProblemID:   strconv.FormatUint(s.ProblemID, 10),
+			TeamID:      strconv.FormatUint(teamID, 10),
+			Time:        feedTime(s.CreateTime),
+			ContestTime: contestTime,
+		})
+		// 尚未评测完成的提交没有判题结果；不记录评测耗时，开始和结束时间均取提交时间
+		if !isFinalStatus(s.Status) {
+			judged = false
+			continue
+		}
+		add(models.FeedJudgements, sid, &models.FeedJudgement{
+			ID:               sid,
+			SubmissionID:     sid,
+			JudgementTypeID:  feedJudgementType(s.Status),
+			StartTime:        feedTime(s.CreateTime),
+			StartContestTime: contestTime,
+			EndTime:          feedTime(s.CreateTime),
+			EndContestTime:   contestTime,
+		})
+	}
+
+	state := new(models.FeedStateData)
+	now := time.Now()
+	at := func(t time.Time) *string {
+		if now.Before(t) {
+			return nil
+		}
+		s := feedTime(t)
+		return &s
+	}
+	state.Started = at(contest.StartTime)
+	state.Ended = at(contest.EndTime())
+	if contest.Freeze > 0 {
+		state.Frozen = at(contest.FreezeTime())
+	}
+	if contest.Freeze > 0 && contest.Unfrozen {
+		state.Thawed = state.Ended
+	}
+	// 比赛结束且全部提交评测完成后结果不再变化
+	if state.Ended != nil && judged {
+		state.Finalized = state.Ended
+	}
+	add(models.FeedState, "", state)
+	return
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/utils/diff"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+var ErrorRevisionCurrent = errors.New("该版本与当前题目内容相同")
+
+// diffContext unified diff 中保留的上下文行数
+const diffContext = 3
+
+// GetProblemRevisions 题目的版本列表，题目早于版本记录功能创建且从未修改时为空
+func GetProblemRevisions(problemID uint64, viewer *models.ProblemViewer) ([]*models.ProblemRevision, error) {
+	if _, err := getVisibleProblem(problemID, viewer); err != nil {
+		return nil, err
+	}
+	return mysql.GetProblemRevisions(problemID)
+}
+
+// GetProblemRevisionDiff 比较题目的两个版本，返回从 from 到 to 的 unified diff
+func GetProblemRevisionDiff(problemID uint64, p *models.ParamRevisionDiff, viewer *models.ProblemViewer) (data *models.ApiRevisionDiff, err error) {
+	if _, err = getVisibleProblem(problemID, viewer); err != nil {
+		return nil, err
+	}
+	from, err := mysql.GetProblemRevision(problemID, p.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := mysql.GetProblemRevision(problemID, p.To)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiRevisionDiff{
+		From: p.From,
+		To:   p.To,
+		Diff: diff.Unified(from.Text(), to.Text(),
+			fmt.Sprintf("版本%d", p.From), fmt.Sprintf("版本%d", p.To), diffContext),
+	}
+	return
+}
+
+// RollbackProblem 把题面和限制恢复为指定版本的内容，回滚本身也记录为一个新版本
+func RollbackProblem(problemID uint64, version int, editorID uint64) (data *models.ApiProblemDetail, err error) {
+	revision, err := mysql.GetProblemRevision(problemID, version)
+	if err != nil {
+		return nil, err
+	}
+	pastProblem, err := mysql.GetProblemByID(int64(problemID))
+	if err != nil {
+		return nil, err
+	}
+	problem := *pastProblem
+	problem.Title = revision.Title
+	problem.Content = revision.Content
+	if revision.Statement != nil && !revision.Statement.IsEmpty() {
+		problem.Statement = *revision.Statement
+	} else {
+		// 早于结构化题面的版本只有完整内容
+		problem.Statement = models.Statement{Description: revision.Content, Samples: []*models.Sample{}}
+	}
+	problem.TimeLimit = revision.TimeLimit
+	problem.MemoryLimit = revision.MemoryLimit
+	problem.Difficulty = revision.Difficulty
+	if !problemChanged(&problem, pastProblem) {
+		return nil, ErrorRevisionCurrent
+	}
+	note := fmt.Sprintf("回滚到版本%d", version)
+	if err = mysql.UpdateProblem(&problem, pastProblem, editorID, note); err != nil {
+		zap.L().Error("mysql.UpdateProblem() failed", zap.Uint64("problemID", problemID), zap.Error(err))
+		return nil, err
+	}
+	return GetProblemById(int64(problemID))
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// snippetWidth 摘要长度(字符数)
+const snippetWidth = 120
+
+// Search 全文检索题目或题解，结果带有高亮的标题和摘要
+func Search(p *models.ParamSearch, viewer *models.ProblemViewer) (data *models.ApiSearchPage, err error) {
+	q := strings.TrimSpace(p.Q)
+	if p.Type == "" {
+		p.Type = models.SearchProblem
+	}
+	var (
+		hits  []*models.SearchHit
+		total int64
+	)
+	if p.Type == models.SearchAnswer {
+		hits, total, err = mysql.SearchAnswers(q, p.Page, p.Size, viewer)
+	} else {
+		hits, total, err = mysql.SearchProblems(q, p.Page, p.Size, viewer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(q)
+	data = &models.ApiSearchPage{
+		Total: total,
+		List:  make([]*models.ApiSearchResult, 0, len(hits)),
+	}
+	for _, hit := range hits {
+		data.List = append(data.List, &models.ApiSearchResult{
+			Type:      p.Type,
+			ID:        hit.ID,
+			ProblemID: hit.ProblemID,
+			Title:     highlight(hit.Title, terms, 0),
+			Snippet:   highlight(hit.Content, terms, snippetWidth),
+			Score:     hit.Score,
+		})
+	}
+	return
+}
+
+// highlight 转义文本并用 <em> 标出关键词(不区分大小写)，
+// width 大于0时截取第一个关键词附近的一段作为摘要
+func highlight(text string, terms []string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != string(t) {
+				continue
+			}
+			for j := i; j < i+len(t); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	begin, end := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		// 关键词前保留少量上下文
+		if first > width/4 {
+			begin = first - width/4
+		}
+		end = begin + width
+		if end > len(runes) {
+			end = len(runes)
+			begin = end - width
+		}
+	}
+	var b strings.Builder
+	if begin > 0 {
+		b.WriteString("...")
+	}
+	for i := begin; i < end; i++ {
+		if marked[i] && (i == begin || !marked[i-1]) {
+			b.WriteString("<em>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString("</em>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/pubsub"
+	"encoding/json"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+)
+
+var broker pubsub.Broker
+
+// getSubmission 查询提交的当前状态，测试时可替换为不依赖数据库的实现
+var getSubmission = mysql.GetSubmissionByID
+
+// InitBroker 设置发布订阅，评测机通过它把评测进度推送给所有API实例
+func InitBroker(b pubsub.Broker) {
+	broker = b
+}
+
+// submissionChannel 提交评测进度的频道
+func submissionChannel(submissionID uint64) string {
+	return "submission:event:" + strconv.FormatUint(submissionID, 10)
+}
+
+// isFinalStatus 是否已经评测结束
+func isFinalStatus(status string) bool {
+	return status != models.StatusPending && status != models.StatusJudging
+}
+
+// publishSubmissionEvent 发布评测进度，失败只记录日志，不影响评测
+func publishSubmissionEvent(event *models.SubmissionEvent) {
+	if broker == nil {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		zap.L().Error("marshal submission event failed", zap.Error(err))
+		return
+	}
+	if err = broker.Publish(submissionChannel(event.SubmissionID), data); err != nil {
+		zap.L().Error("publish submission event failed", zap.Uint64("submissionID", event.SubmissionID), zap.Error(err))
+	}
+}
+
+// publishSubmissionResult 发布最终评测结果，不含源代码
+func publishSubmissionResult(submission *models.Submission) {
+	result := *submission
+	result.Source = ""
+	publishSubmissionEvent(&models.SubmissionEvent{
+		Type:         models.EventResult,
+		SubmissionID: submission.SubmissionID,
+		Status:       submission.Status,
+		Result:       &result,
+	})
+}
+
+// WatchSubmission 订阅提交的评测进度。先推送当前状态，已评测结束时只推送结果；
+// 收到结果事件后关闭 events，调用方提前结束时调用 cancel。
+// 结果尚未公布时只推送一个已提交的结果事件
+func WatchSubmission(submissionID, viewerID uint64, role string) (events <-chan *models.SubmissionEvent, cancel func(), err error) {
+	// 先订阅再查询当前状态，避免两者之间产生的事件丢失
+	sub, err := broker.Subscribe(submissionChannel(submissionID))
+	if err != nil {
+		zap.L().Error("subscribe submission event failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+		return nil, nil, err
+	}
+	submission, err := getSubmission(int64(submissionID))
+	if err != nil {
+		_ = sub.Close()
+		return nil, nil, err
+	}
+	submission.Source = ""
+	hidden, err := newResultFilter(viewerID, role).hidden(submission)
+	if err != nil {
+		_ = sub.Close()
+		return nil, nil, err
+	}
+	if hidden {
+		hideResult(submission)
+	}
+
+	ch := make(chan *models.SubmissionEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+		send := func(event *models.SubmissionEvent) bool {
+			select {
+			case ch <- event:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		if hidden || isFinalStatus(submission.Status) {
+			send(&models.SubmissionEvent{
+				Type:         models.EventResult,
+				SubmissionID: submissionID,
+				Status:       submission.Status,
+				Result:       submission,
+			})
+			return
+		}
+		if !send(&models.SubmissionEvent{
+			Type:         models.EventStatus,
+			SubmissionID: submissionID,
+			Status:       submission.Status,
+		}) {
+			return
+		}
+		for {
+			select {
+			case data, ok := <-sub.Messages():
+				if !ok {
+					return
+				}
+				event := new(models.SubmissionEvent)
+				if err := json.Unmarshal(data, event); err != nil {
+					zap.L().Error("unmarshal submission event failed", zap.Error(err))
+					continue
+				}
+				if !send(event) || event.Type == models.EventResult {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() { close(done) })
+	}
+	return ch, cancel, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"LanShan/models"
+	"LanShan/pubsub"
+	"testing"
+	"time"
+)
+
+// watchAll 收集 WatchSubmission 推送的全部事件
+func watchAll(t *testing.T, submissionID uint64) []*models.SubmissionEvent {
+	t.Helper()
+	events, cancel, err := WatchSubmission(submissionID, 1, models.RoleUser)
+	if err != nil {
+		t.Fatalf("WatchSubmission() error = %v", err)
+	}
+	defer cancel()
+	var got []*models.SubmissionEvent
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, event)
+		case <-timeout:
+			t.Fatalf("WatchSubmission() did not finish, got %d events", len(got))
+		}
+	}
+}
+
+// TestWatchSubmissionSubscribesFirst 查询当前状态期间评测机发布的事件不会丢失
+func TestWatchSubmissionSubscribesFirst(t *testing.T) {
+	InitBroker(pubsub.NewMemory())
+	defer InitBroker(nil)
+	defer func(fn func(int64) (*models.Submission, error)) { getSubmission = fn }(getSubmission)
+
+	const submissionID = 42
+	getSubmission = func(int64) (*models.Submission, error) {
+		// 模拟查询数据库的同时评测完成：状态仍是 Pending，但事件已经发出
+		publishSubmissionEvent(&models.SubmissionEvent{
+			Type: models.EventStatus, SubmissionID: submissionID, Status: models.StatusJudging,
+		})
+		publishSubmissionResult(&models.Submission{SubmissionID: submissionID, Status: models.StatusAccepted})
+		return &models.Submission{SubmissionID: submissionID, Status: models.StatusPending}, nil
+	}
+
+	got := watchAll(t, submissionID)
+	want := []struct{ typ, status string }{
+		{models.EventStatus, models.StatusPending},
+		{models.EventStatus, models.StatusJudging},
+		{models.EventResult, models.StatusAccepted},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Status != w.status {
+			t.Errorf("event %d = %s/%s, want %s/%s", i, got[i].Type, got[i].Status, w.typ, w.status)
+		}
+	}
+}
+
+// TestWatchSubmissionFinished 已评测结束的提交只推送结果
+func TestWatchSubmissionFinished(t *testing.T) {
+	InitBroker(pubsub.NewMemory())
+	defer InitBroker(nil)
+	defer func(fn func(int64) (*models.Submission, error)) { getSubmission = fn }(getSubmission)
+
+	getSubmission = func(id int64) (*models.Submission, error) {
+		return &models.Submission{SubmissionID: uint64(id), Status: models.StatusWrongAnswer, Source: "secret"}, nil
+	}
+	got := watchAll(t, 43)
+	if len(got) != 1 || got[0].Type != models.EventResult || got[0].Status != models.StatusWrongAnswer {
+		t.Fatalf("got %+v, want a single WA result event", got)
+	}
+	if got[0].Result.Source != "" {
+		t.Errorf("result event leaks source code")
+	}
+}
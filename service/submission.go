@@ -0,0 +1,129 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/judge"
+	"LanShan/models"
+	"LanShan/utils/snowflake"
+	"go.uber.org/zap"
+)
+
+// CreateSubmission 创建提交记录
+func CreateSubmission(userID uint64, role string, p *models.ParamSubmit) (submission *models.Submission, err error) {
+	// 1、确认语言受支持、题目存在
+	if _, ok := judge.GetLanguage(p.Language); !ok {
+		return nil, judge.ErrorUnknownLanguage
+	}
+	problem, err := mysql.GetProblemByID(int64(p.ProblemID))
+	if err != nil {
+		zap.L().Error("mysql.GetProblemByID() failed",
+			zap.Uint64("problemID", p.ProblemID),
+			zap.Error(err))
+		return nil, err
+	}
+	if p.ContestID != 0 {
+		err = checkContestSubmission(userID, p)
+	} else {
+		err = checkPracticeSubmission(userID, role, problem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 2、生成ID
+	submissionID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	submission = &models.Submission{
+		SubmissionID: submissionID,
+		ProblemID:    p.ProblemID,
+		UserID:       userID,
+		ContestID:    p.ContestID,
+		Language:     p.Language,
+		Source:       p.Source,
+		Status:       models.StatusPending,
+	}
+	// 3、保存到数据库
+	if err = mysql.CreateSubmission(submission); err != nil {
+		zap.L().Error("mysql.CreateSubmission() failed", zap.Error(err))
+		return nil, err
+	}
+	// 4、加入评测队列，由评测机异步评测
+	if err = judgeQueue.Push(submissionID); err != nil {
+		zap.L().Error("judgeQueue.Push() failed", zap.Uint64("submissionID", submissionID), zap.Error(err))
+		// 入队失败的提交不会被评测，标记为系统错误，避免一直停留在等待评测
+		if uerr := mysql.UpdateSubmissionStatus(submissionID, models.StatusSystemError); uerr != nil {
+			zap.L().Error("mark submission failed", zap.Uint64("submissionID", submissionID), zap.Error(uerr))
+		}
+		return nil, err
+	}
+	return
+}
+
+// GetSubmissionByID 查询提交详情及各测试点结果，非提交者本人不返回源代码，
+// OI/IOI赛制比赛结束前不返回评测结果
+func GetSubmissionByID(submissionID int64, viewerID uint64, role string) (data *models.ApiSubmissionDetail, err error) {
+	submission, err := mysql.GetSubmissionByID(submissionID)
+	if err != nil {
+		zap.L().Error("mysql.GetSubmissionByID() failed",
+			zap.Int64("submissionID", submissionID),
+			zap.Error(err))
+		return nil, err
+	}
+	if submission.UserID != viewerID {
+		submission.Source = ""
+	}
+	hidden, err := newResultFilter(viewerID, role).hidden(submission)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		hideResult(submission)
+		data = &models.ApiSubmissionDetail{
+			Submission: submission,
+			Cases:      []*models.SubmissionCase{},
+			Subtasks:   []*models.SubmissionSubtask{},
+		}
+		return
+	}
+	cases, err := mysql.GetSubmissionCases(submission.SubmissionID)
+	if err != nil {
+		return nil, err
+	}
+	subtasks, err := mysql.GetSubmissionSubtasks(submission.SubmissionID)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiSubmissionDetail{
+		Submission: submission,
+		Cases:      cases,
+		Subtasks:   subtasks,
+	}
+	return
+}
+
+// GetSubmissionList 筛选提交记录，结果尚未公布的提交只展示已提交
+func GetSubmissionList(p *models.ParamSubmissionList, viewerID uint64, role string) ([]*models.Submission, error) {
+	submissions, err := mysql.GetSubmissionList(p)
+	if err != nil {
+		return nil, err
+	}
+	filter := newResultFilter(viewerID, role)
+	data := submissions[:0]
+	for _, s := range submissions {
+		hidden, err := filter.hidden(s)
+		if err != nil {
+			return nil, err
+		}
+		if hidden {
+			// 按状态筛选时结果未公布的提交不出现，否则能从筛选结果推断出评测结果
+			if p.Status != "" {
+				continue
+			}
+			hideResult(s)
+		}
+		data = append(data, s)
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrorSubtaskInvalid = errors.New("子任务设置有误")
+
+// SaveSubtasks 替换题目的全部子任务。测试点必须存在，依赖只能指向编号更小的子任务，从而避免循环依赖
+func SaveSubtasks(problemID uint64, p *models.ParamSubtasks) (subtasks []*models.Subtask, err error) {
+	testcases, err := mysql.GetTestCaseList(problemID)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[int]bool, len(testcases))
+	for _, tc := range testcases {
+		exists[tc.Index] = true
+	}
+	subtasks = make([]*models.Subtask, 0, len(p.Subtasks))
+	for i, ps := range p.Subtasks {
+		index := i + 1
+		seen := make(map[int]bool, len(ps.Cases))
+		for _, c := range ps.Cases {
+			if !exists[c] {
+				return nil, fmt.Errorf("%w: 子任务%d的测试点%d不存在", ErrorSubtaskInvalid, index, c)
+			}
+			if seen[c] {
+				return nil, fmt.Errorf("%w: 子任务%d的测试点%d重复", ErrorSubtaskInvalid, index, c)
+			}
+			seen[c] = true
+		}
+		for _, d := range ps.Dependencies {
+			if d <= 0 || d >= index {
+				return nil, fmt.Errorf("%w: 子任务%d只能依赖编号更小的子任务", ErrorSubtaskInvalid, index)
+			}
+		}
+		subtasks = append(subtasks, &models.Subtask{
+			ProblemID:    problemID,
+			Index:        index,
+			Score:        ps.Score,
+			Type:         ps.Type,
+			Cases:        ps.Cases,
+			Dependencies: ps.Dependencies,
+		})
+	}
+	if err = mysql.ReplaceSubtasks(problemID, subtasks); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func GetSubtaskList(problemID uint64) ([]*models.Subtask, error) {
+	return mysql.GetSubtaskList(problemID)
+}
+
+// scoreSubmission 根据各测试点的得分比例计算总分。
+// 没有设置子任务时每个测试点分值相同，满分为 models.FullScore
+func scoreSubmission(subtasks []*models.Subtask, cases []*models.SubmissionCase) (score float64, results []*models.SubmissionSubtask) {
+	if len(cases) == 0 {
+		return 0, nil
+	}
+	if len(subtasks) == 0 {
+		for _, c := range cases {
+			score += c.Score
+		}
+		return roundScore(score * models.FullScore / float64(len(cases))), nil
+	}
+
+	caseMap := make(map[int]*models.SubmissionCase, len(cases))
+	for _, c := range cases {
+		caseMap[c.Index] = c
+	}
+	// 各子任务的得分比例，依赖只指向编号更小的子任务，按顺序计算即可
+	ratios := make(map[int]float64, len(subtasks))
+	results = make([]*models.SubmissionSubtask, 0, len(subtasks))
+	for _, st := range subtasks {
+		result := &models.SubmissionSubtask{Index: st.Index, Status: models.StatusAccepted}
+		minRatio, sum := 1.0, 0.0
+		for _, index := range st.Cases {
+			c, ok := caseMap[index]
+			if !ok {
+				// 子任务引用的测试点已被删除，按0分处理
+				minRatio, result.Status = 0, models.StatusSystemError
+				continue
+			}
+			if result.Status == models.StatusAccepted && c.Status != models.StatusAccepted {
+				result.Status = c.Status
+			}
+			minRatio = math.Min(minRatio, c.Score)
+			sum += c.Score
+		}
+		ratio := minRatio
+		if st.Type == models.SubtaskSum && len(st.Cases) > 0 {
+			ratio = sum / float64(len(st.Cases))
+		}
+		for _, d := range st.Dependencies {
+			if ratios[d] < 1 {
+				ratio = 0
+				if result.Status == models.StatusAccepted {
+					result.Status = models.StatusSkipped
+				}
+			}
+		}
+		ratios[st.Index] = ratio
+		result.Score = roundScore(st.Score * ratio)
+		score += result.Score
+		results = append(results, result)
+	}
+	return roundScore(score), results
+}
+
+// roundScore 保留两位小数，避免浮点误差
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
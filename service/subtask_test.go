@@ -0,0 +1,94 @@
+package service
+
+import (
+	"LanShan/models"
+	"reflect"
+	"testing"
+)
+
+// caseResult 构造测试点结果，score 为得分比例
+func caseResult(index int, status string, score float64) *models.SubmissionCase {
+	return &models.SubmissionCase{Index: index, Status: status, Score: score}
+}
+
+func TestScoreSubmission(t *testing.T) {
+	ac := func(index int) *models.SubmissionCase { return caseResult(index, models.StatusAccepted, 1) }
+	wa := func(index int) *models.SubmissionCase { return caseResult(index, models.StatusWrongAnswer, 0) }
+	pc := func(index int, score float64) *models.SubmissionCase {
+		return caseResult(index, models.StatusPartialCorrect, score)
+	}
+	subtask := func(index int, score float64, typ string, cases []int, deps ...int) *models.Subtask {
+		return &models.Subtask{Index: index, Score: score, Type: typ, Cases: cases, Dependencies: deps}
+	}
+	tests := []struct {
+		name         string
+		subtasks     []*models.Subtask
+		cases        []*models.SubmissionCase
+		wantScore    float64
+		wantSubtasks []*models.SubmissionSubtask
+	}{
+		{"no cases", nil, nil, 0, nil},
+		{"no subtasks", nil, []*models.SubmissionCase{ac(1), pc(2, 0.5), wa(3)}, 50, nil},
+		{"min", []*models.Subtask{
+			subtask(1, 30, models.SubtaskMin, []int{1, 2}),
+			subtask(2, 70, models.SubtaskMin, []int{3}),
+		}, []*models.SubmissionCase{ac(1), pc(2, 0.5), ac(3)}, 85, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusPartialCorrect, Score: 15},
+			{Index: 2, Status: models.StatusAccepted, Score: 70},
+		}},
+		{"min fails on wrong answer", []*models.Subtask{
+			subtask(1, 40, models.SubtaskMin, []int{1, 2}),
+		}, []*models.SubmissionCase{ac(1), wa(2)}, 0, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusWrongAnswer, Score: 0},
+		}},
+		{"sum", []*models.Subtask{
+			subtask(1, 40, models.SubtaskSum, []int{1, 2}),
+		}, []*models.SubmissionCase{ac(1), wa(2)}, 20, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusWrongAnswer, Score: 20},
+		}},
+		{"sum with partial checker scores", []*models.Subtask{
+			subtask(1, 40, models.SubtaskSum, []int{1, 2}),
+			subtask(2, 60, models.SubtaskSum, []int{3}),
+		}, []*models.SubmissionCase{pc(1, 0.5), pc(2, 0.25), pc(3, 1.0/3)}, 35, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusPartialCorrect, Score: 15},
+			{Index: 2, Status: models.StatusPartialCorrect, Score: 20},
+		}},
+		{"failed dependency zeroes subtask", []*models.Subtask{
+			subtask(1, 30, models.SubtaskMin, []int{1}),
+			subtask(2, 70, models.SubtaskMin, []int{2}, 1),
+		}, []*models.SubmissionCase{wa(1), ac(2)}, 0, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusWrongAnswer, Score: 0},
+			{Index: 2, Status: models.StatusSkipped, Score: 0},
+		}},
+		{"partial dependency zeroes subtask", []*models.Subtask{
+			subtask(1, 30, models.SubtaskSum, []int{1, 2}),
+			subtask(2, 70, models.SubtaskMin, []int{3}, 1),
+		}, []*models.SubmissionCase{ac(1), wa(2), ac(3)}, 15, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusWrongAnswer, Score: 15},
+			{Index: 2, Status: models.StatusSkipped, Score: 0},
+		}},
+		{"satisfied dependency", []*models.Subtask{
+			subtask(1, 30, models.SubtaskMin, []int{1}),
+			subtask(2, 70, models.SubtaskMin, []int{1, 2}, 1),
+		}, []*models.SubmissionCase{ac(1), ac(2)}, 100, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusAccepted, Score: 30},
+			{Index: 2, Status: models.StatusAccepted, Score: 70},
+		}},
+		{"missing case", []*models.Subtask{
+			subtask(1, 50, models.SubtaskSum, []int{1, 9}),
+		}, []*models.SubmissionCase{ac(1)}, 25, []*models.SubmissionSubtask{
+			{Index: 1, Status: models.StatusSystemError, Score: 25},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, subtasks := scoreSubmission(tt.subtasks, tt.cases)
+			if score != tt.wantScore {
+				t.Errorf("scoreSubmission() score = %v, want %v", score, tt.wantScore)
+			}
+			if !reflect.DeepEqual(subtasks, tt.wantSubtasks) {
+				t.Errorf("scoreSubmission() subtasks = %+v, want %+v", subtasks, tt.wantSubtasks)
+			}
+		})
+	}
+}
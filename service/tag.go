@@ -0,0 +1,89 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/utils/snowflake"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+)
+
+var ErrorTagNotExist = errors.New("标签不存在")
+
+func CreateTag(p *models.ParamTag) (tag *models.Tag, err error) {
+	tagID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	tag = &models.Tag{TagID: tagID, Name: strings.TrimSpace(p.Name)}
+	if err = mysql.CreateTag(tag); err != nil {
+		return nil, err
+	}
+	return mysql.GetTagByID(tagID)
+}
+
+func UpdateTag(tagID uint64, p *models.ParamTag) (tag *models.Tag, err error) {
+	if err = mysql.UpdateTag(tagID, strings.TrimSpace(p.Name)); err != nil {
+		return nil, err
+	}
+	return mysql.GetTagByID(tagID)
+}
+
+func DeleteTag(tagID uint64) error {
+	return mysql.DeleteTag(tagID)
+}
+
+func GetTagList() ([]*models.Tag, error) {
+	return mysql.GetTagList()
+}
+
+// getTagIDs 按标签名查询标签id，有不存在的标签时返回错误
+func getTagIDs(names []string) (tagIDs []uint64, err error) {
+	names = models.UniqueTagNames(names)
+	tags, err := mysql.GetTagsByNames(names)
+	if err != nil {
+		return
+	}
+	if len(tags) != len(names) {
+		found := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			found[strings.ToLower(tag.Name)] = true
+		}
+		for _, name := range names {
+			if !found[strings.ToLower(name)] {
+				return nil, fmt.Errorf("%w: %s", ErrorTagNotExist, name)
+			}
+		}
+	}
+	tagIDs = make([]uint64, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.TagID)
+	}
+	return
+}
+
+// fillProblemTags 查询并填充题目的标签名
+func fillProblemTags(problems ...*models.Problem) (err error) {
+	ids := make([]uint64, 0, len(problems))
+	for _, problem := range problems {
+		ids = append(ids, problem.ProblemID)
+	}
+	tags, err := mysql.GetProblemTags(ids...)
+	if err != nil {
+		return
+	}
+	names := make(map[uint64][]string, len(problems))
+	for _, tag := range tags {
+		names[tag.ProblemID] = append(names[tag.ProblemID], tag.Name)
+	}
+	for _, problem := range problems {
+		problem.Tags = names[problem.ProblemID]
+		if problem.Tags == nil {
+			problem.Tags = []string{}
+		}
+	}
+	return
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/utils/snowflake"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+var (
+	ErrorTeamFull          = errors.New("队伍人数已满")
+	ErrorNotTeamCaptain    = errors.New("只有队长可以进行该操作")
+	ErrorTeamMember        = errors.New("该用户已是队员")
+	ErrorInvitationExists  = errors.New("已邀请过该用户")
+	ErrorInvitationHandled = errors.New("邀请已处理")
+	ErrorTeamLocked        = errors.New("队伍已报名尚未结束的比赛，不能加入新队员")
+	ErrorTeamConflict      = errors.New("有队员已单独或随其他队伍报名该比赛")
+)
+
+// CreateTeam 创建队伍，创建者为队长
+func CreateTeam(userID uint64, p *models.ParamTeam) (data *models.ApiTeamDetail, err error) {
+	teamID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	team := &models.Team{TeamID: teamID, Name: p.Name, CaptainID: userID}
+	if err = mysql.CreateTeam(team); err != nil {
+		return nil, err
+	}
+	return GetTeamDetail(teamID)
+}
+
+// GetTeamDetail 查询队伍及队员
+func GetTeamDetail(teamID uint64) (data *models.ApiTeamDetail, err error) {
+	team, err := mysql.GetTeamByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := mysql.GetTeamMembers(teamID)
+	if err != nil {
+		return nil, err
+	}
+	data = &models.ApiTeamDetail{Team: team, Members: members}
+	return
+}
+
+// GetUserTeams 查询用户所在的队伍
+func GetUserTeams(userID uint64) ([]*models.Team, error) {
+	return mysql.GetUserTeams(userID)
+}
+
+// InviteTeamMember 队长邀请用户入队，被邀请者接受后才成为队员
+func InviteTeamMember(teamID, captainID uint64, p *models.ParamTeamInvite) (inv *models.TeamInvitation, err error) {
+	data, err := GetTeamDetail(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if data.CaptainID != captainID {
+		return nil, ErrorNotTeamCaptain
+	}
+	if err = checkTeamJoinable(data, p.UserID); err != nil {
+		return nil, err
+	}
+	if _, err = mysql.GetUserByID(p.UserID); err != nil {
+		return nil, err
+	}
+	exists, err := mysql.HasPendingInvitation(teamID, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrorInvitationExists
+	}
+	invitationID, err := snowflake.GetID()
+	if err != nil {
+		zap.L().Error("snowflake.GetID() failed", zap.Error(err))
+		return nil, mysql.ErrorGenIDFailed
+	}
+	inv = &models.TeamInvitation{
+		InvitationID: invitationID,
+		TeamID:       teamID,
+		TeamName:     data.Name,
+		UserID:       p.UserID,
+		InviterID:    captainID,
+		Status:       models.InvitationPending,
+	}
+	if err = mysql.CreateTeamInvitation(inv); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// checkTeamJoinable 用户能否加入队伍：不能重复加入、人数未满，且队伍没有报名尚未结束的比赛
+func checkTeamJoinable(data *models.ApiTeamDetail, userID uint64) error {
+	for _, m := range data.Members {
+		if m.UserID == userID {
+			return ErrorTeamMember
+		}
+	}
+	if len(data.Members) >= models.TeamMaxMembers {
+		return ErrorTeamFull
+	}
+	locked, err := mysql.IsTeamInActiveContest(data.TeamID, time.Now())
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrorTeamLocked
+	}
+	return nil
+}
+
+func GetPendingInvitations(userID uint64) ([]*models.TeamInvitation, error) {
+	return mysql.GetPendingInvitations(userID)
+}
+
+// ReplyTeamInvitation 被邀请者接受或拒绝邀请，接受时重新检查能否入队
+func ReplyTeamInvitation(invitationID, userID uint64, accept bool) error {
+	inv, err := mysql.GetTeamInvitationByID(invitationID)
+	if err != nil {
+		return err
+	}
+	if inv.UserID != userID {
+		return mysql.ErrorInvalidID
+	}
+	if inv.Status != models.InvitationPending {
+		return ErrorInvitationHandled
+	}
+	if !accept {
+		return mysql.DeclineTeamInvitation(invitationID)
+	}
+	data, err := GetTeamDetail(inv.TeamID)
+	if err != nil {
+		return err
+	}
+	if err = checkTeamJoinable(data, userID); err != nil {
+		return err
+	}
+	return mysql.AcceptTeamInvitation(inv)
+}
+
+// registerContestTeam 队长为队伍报名比赛，队员不能已单独或随其他队伍报名
+func registerContestTeam(contestID, userID, teamID uint64) error {
+	data, err := GetTeamDetail(teamID)
+	if err != nil {
+		return err
+	}
+	if data.CaptainID != userID {
+		return ErrorNotTeamCaptain
+	}
+	for _, m := range data.Members {
+		registered, err := mysql.IsContestParticipant(contestID, m.UserID)
+		if err != nil {
+			return err
+		}
+		other, err := mysql.GetContestTeamOfUser(contestID, m.UserID)
+		if err != nil {
+			return err
+		}
+		if registered || (other != 0 && other != teamID) {
+			return ErrorTeamConflict
+		}
+	}
+	return mysql.RegisterContestTeam(contestID, teamID)
+}
+
+// isContestRegistered 用户是否单独或随队伍报名了比赛
+func isContestRegistered(contestID, userID uint64) (bool, error) {
+	ok, err := mysql.IsContestParticipant(contestID, userID)
+	if err != nil || ok {
+		return ok, err
+	}
+	teamID, err := mysql.GetContestTeamOfUser(contestID, userID)
+	return teamID != 0, err
+}
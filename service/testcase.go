@@ -0,0 +1,197 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/settings"
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 单个测试文件最大256MB
+const maxTestFileSize = 256 << 20
+
+var (
+	ErrorTestCaseFormat   = errors.New("测试数据格式错误")
+	ErrorTestCaseTooLarge = errors.New("测试数据文件过大")
+)
+
+// testCaseDir 题目测试数据所在目录
+func testCaseDir(problemID uint64) string {
+	return filepath.Join(settings.Get().JudgeConfig.DataDir, "testcase", strconv.FormatUint(problemID, 10))
+}
+
+// TestCaseFiles 返回测试点输入、输出文件的路径
+func TestCaseFiles(problemID uint64, index int) (input, output string) {
+	dir := testCaseDir(problemID)
+	name := strconv.Itoa(index)
+	return filepath.Join(dir, name+".in"), filepath.Join(dir, name+".out")
+}
+
+// UploadTestCases 解析zip压缩包中的 N.in/N.out 文件，替换题目的全部测试数据
+func UploadTestCases(problemID uint64, r io.ReaderAt, size int64) (cases []*models.TestCase, err error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrorTestCaseFormat
+	}
+	// 1、按编号配对输入输出文件，忽略目录层级和无关文件
+	pairs := make(map[int]*[2]*zip.File)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Base(f.Name)
+		ext := path.Ext(name)
+		if ext != ".in" && ext != ".out" {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(name, ext))
+		if err != nil || index <= 0 {
+			continue
+		}
+		pair, ok := pairs[index]
+		if !ok {
+			pair = new([2]*zip.File)
+			pairs[index] = pair
+		}
+		slot := 0
+		if ext == ".out" {
+			slot = 1
+		}
+		if pair[slot] != nil {
+			return nil, ErrorTestCaseFormat // 重复的测试点
+		}
+		pair[slot] = f
+	}
+	if len(pairs) == 0 {
+		return nil, ErrorTestCaseFormat
+	}
+	indexes := make([]int, 0, len(pairs))
+	for index, pair := range pairs {
+		if pair[0] == nil || pair[1] == nil {
+			return nil, ErrorTestCaseFormat // 输入输出不成对
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	// 2、先解压到临时目录，全部成功后再替换旧数据
+	dir := testCaseDir(problemID)
+	if err = os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".upload-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	cases = make([]*models.TestCase, 0, len(indexes))
+	for _, index := range indexes {
+		tc := &models.TestCase{ProblemID: problemID, Index: index}
+		name := strconv.Itoa(index)
+		if tc.InputSize, tc.InputMD5, err = extractZipFile(pairs[index][0], filepath.Join(tmp, name+".in")); err != nil {
+			return nil, err
+		}
+		if tc.OutputSize, tc.OutputMD5, err = extractZipFile(pairs[index][1], filepath.Join(tmp, name+".out")); err != nil {
+			return nil, err
+		}
+		cases = append(cases, tc)
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+
+	// 3、保存元信息
+	if err = mysql.ReplaceTestCases(problemID, cases); err != nil {
+		zap.L().Error("mysql.ReplaceTestCases() failed", zap.Uint64("problemID", problemID), zap.Error(err))
+		return nil, err
+	}
+	return
+}
+
+// SaveTestCase 新增或覆盖单个测试点
+func SaveTestCase(problemID uint64, index int, input, output io.Reader) (tc *models.TestCase, err error) {
+	dir := testCaseDir(problemID)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	inPath, outPath := TestCaseFiles(problemID, index)
+	tc = &models.TestCase{ProblemID: problemID, Index: index}
+	if tc.InputSize, tc.InputMD5, err = saveFile(inPath, input); err != nil {
+		return nil, err
+	}
+	if tc.OutputSize, tc.OutputMD5, err = saveFile(outPath, output); err != nil {
+		return nil, err
+	}
+	if err = mysql.SaveTestCase(tc); err != nil {
+		zap.L().Error("mysql.SaveTestCase() failed", zap.Uint64("problemID", problemID), zap.Error(err))
+		return nil, err
+	}
+	return
+}
+
+func GetTestCaseList(problemID uint64) ([]*models.TestCase, error) {
+	return mysql.GetTestCaseList(problemID)
+}
+
+// DeleteTestCase 删除单个测试点
+func DeleteTestCase(problemID uint64, index int) (err error) {
+	if err = mysql.DeleteTestCase(problemID, index); err != nil {
+		return err
+	}
+	inPath, outPath := TestCaseFiles(problemID, index)
+	if err = os.Remove(inPath); err != nil && !os.IsNotExist(err) {
+		zap.L().Error("remove testcase input failed", zap.String("path", inPath), zap.Error(err))
+	}
+	if err = os.Remove(outPath); err != nil && !os.IsNotExist(err) {
+		zap.L().Error("remove testcase output failed", zap.String("path", outPath), zap.Error(err))
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, dst string) (size int64, sum string, err error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, "", ErrorTestCaseFormat
+	}
+	defer rc.Close()
+	return saveFile(dst, rc)
+}
+
+// saveFile 先写临时文件再改名，返回文件大小和md5
+func saveFile(dst string, r io.Reader) (size int64, sum string, err error) {
+	tmp := dst + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, "", err
+	}
+	h := md5.New()
+	size, err = io.Copy(io.MultiWriter(f, h), io.LimitReader(r, maxTestFileSize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && size > maxTestFileSize {
+		err = ErrorTestCaseTooLarge
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, "", err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
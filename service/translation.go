@@ -0,0 +1,150 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"unicode/utf8"
+)
+
+var (
+	ErrorLocaleUnsupported   = errors.New("不支持的语言")
+	ErrorTranslationOriginal = errors.New("原始语言的题面请直接修改题目")
+	ErrorTranslationInvalid  = errors.New("译文格式错误")
+)
+
+// pickLocale 按偏好顺序选择题面语言，偏好的语言都没有译文时回退到原始语言
+func pickLocale(prefs []string, original string, available map[string]bool) string {
+	for _, locale := range prefs {
+		if locale == original || available[locale] {
+			return locale
+		}
+	}
+	return original
+}
+
+// applyTranslation 用译文替换题目的标题和题面
+func applyTranslation(problem *models.Problem, t *models.ProblemTranslation) {
+	problem.Title = t.Title
+	problem.Content = t.Content
+	problem.Statement = t.Statement
+	problem.Locale = t.Locale
+}
+
+// GetLocalizedProblem 题目详情，按 prefs 的顺序选择题面语言
+func GetLocalizedProblem(problemID int64, prefs []string, viewer *models.ProblemViewer) (data *models.ApiProblemDetail, err error) {
+	data, err = GetProblemById(problemID)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := mysql.IsProblemHidden(data.ProblemID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrorProblemNotStarted
+	}
+	translations, err := mysql.GetProblemTranslations([]uint64{data.ProblemID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	original := data.Locale
+	data.OriginalLocale = original
+	data.Locales = []string{original}
+	byLocale := make(map[string]*models.ProblemTranslation, len(translations))
+	available := make(map[string]bool, len(translations))
+	for _, t := range translations {
+		byLocale[t.Locale] = t
+		available[t.Locale] = true
+		data.Locales = append(data.Locales, t.Locale)
+	}
+	if locale := pickLocale(prefs, original, available); locale != original {
+		applyTranslation(data.Problem, byLocale[locale])
+		data.Rendered = renderStatement(&data.Problem.Statement)
+	}
+	return
+}
+
+// localizeProblems 为列表中的题目批量替换译文
+func localizeProblems(problems []*models.Problem, prefs []string) error {
+	if len(problems) == 0 || len(prefs) == 0 {
+		return nil
+	}
+	ids := make([]uint64, 0, len(problems))
+	for _, problem := range problems {
+		ids = append(ids, problem.ProblemID)
+	}
+	translations, err := mysql.GetProblemTranslations(ids, prefs)
+	if err != nil {
+		return err
+	}
+	byProblem := make(map[uint64]map[string]*models.ProblemTranslation)
+	for _, t := range translations {
+		if byProblem[t.ProblemID] == nil {
+			byProblem[t.ProblemID] = make(map[string]*models.ProblemTranslation)
+		}
+		byProblem[t.ProblemID][t.Locale] = t
+	}
+	for _, problem := range problems {
+		locales := byProblem[problem.ProblemID]
+		available := make(map[string]bool, len(locales))
+		for l := range locales {
+			available[l] = true
+		}
+		if locale := pickLocale(prefs, problem.Locale, available); locale != problem.Locale {
+			applyTranslation(problem, locales[locale])
+		}
+	}
+	return nil
+}
+
+// GetProblemTranslations 题目的全部译文
+func GetProblemTranslations(problemID uint64, viewer *models.ProblemViewer) ([]*models.ProblemTranslation, error) {
+	if _, err := getVisibleProblem(problemID, viewer); err != nil {
+		return nil, err
+	}
+	return mysql.GetProblemTranslations([]uint64{problemID}, nil)
+}
+
+// SaveProblemTranslation 新增或修改题目某种语言的译文，译文之间以及与原始题面互不影响
+func SaveProblemTranslation(problemID uint64, locale string, p *models.ParamProblemTranslation, editorID uint64) (t *models.ProblemTranslation, err error) {
+	if !models.IsSupportedLocale(locale) {
+		return nil, ErrorLocaleUnsupported
+	}
+	problem, err := mysql.GetProblemByID(int64(problemID))
+	if err != nil {
+		return nil, err
+	}
+	if locale == problem.Locale {
+		return nil, ErrorTranslationOriginal
+	}
+	if err = p.Statement.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrorTranslationInvalid, err.Error())
+	}
+	t = &models.ProblemTranslation{
+		ProblemID: problemID,
+		Locale:    locale,
+		Title:     p.Title,
+		Content:   p.Statement.Markdown(),
+		Statement: *p.Statement,
+		EditorID:  editorID,
+	}
+	if utf8.RuneCountInString(t.Content) > models.MaxContentLength {
+		return nil, fmt.Errorf("%w: 题面超过%d字", ErrorTranslationInvalid, models.MaxContentLength)
+	}
+	if err = mysql.SaveProblemTranslation(t); err != nil {
+		zap.L().Error("mysql.SaveProblemTranslation() failed", zap.Error(err))
+		return nil, err
+	}
+	return
+}
+
+// DeleteProblemTranslation 删除题目某种语言的译文
+func DeleteProblemTranslation(problemID uint64, locale string) error {
+	if !models.IsSupportedLocale(locale) {
+		return ErrorLocaleUnsupported
+	}
+	return mysql.DeleteProblemTranslation(problemID, locale)
+}
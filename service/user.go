@@ -25,6 +25,7 @@ func SignUp(p *models.RegisterForm) (error error) {
 		UserID:   userId,
 		UserName: p.UserName,
 		Password: p.Password,
+		Role:     models.RoleUser,
 	}
 	// 3、保存进数据库
 	return mysql.InsertUser(&u)
@@ -40,7 +41,7 @@ func Login(p *models.LoginForm) (user *models.User, error error) {
 	}
 	// 生成JWT
 	//return jwt.GenToken(user.UserID,user.UserName)
-	atoken, rtoken, err := jwt.GenToken(user.UserID, user.UserName)
+	atoken, rtoken, err := jwt.GenToken(user.UserID, user.UserName, user.Role)
 	if err != nil {
 		return
 	}
@@ -48,3 +49,20 @@ func Login(p *models.LoginForm) (user *models.User, error error) {
 	user.RefreshToken = rtoken
 	return
 }
+
+// SetUserRole 修改用户角色
+func SetUserRole(userID uint64, role string) error {
+	return mysql.UpdateUserRole(userID, role)
+}
+
+// GetUserRole 查询用户当前的角色。Token 中的角色可能已经过时，鉴权时以数据库为准
+func GetUserRole(userID uint64) (string, error) {
+	user, err := mysql.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Role == "" {
+		return models.RoleUser, nil
+	}
+	return user.Role, nil
+}
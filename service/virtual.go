@@ -0,0 +1,151 @@
+package service
+
+import (
+	"LanShan/dao/mysql"
+	"LanShan/models"
+	"LanShan/ranking"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrorVirtualExists       = errors.New("已经虚拟参加过该比赛")
+	ErrorContestParticipated = errors.New("已参加过该比赛，不能虚拟参赛")
+	ErrorVirtualNotStarted   = errors.New("尚未开始虚拟参赛")
+)
+
+// StartVirtualParticipation 比赛结束后开始虚拟参赛，立即开始计时，时长与原比赛相同。
+// 在比赛中提交过的用户及随队伍报名的用户不能虚拟参赛，私有比赛需要已报名
+func StartVirtualParticipation(contestID, userID uint64) (v *models.VirtualParticipation, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.Before(contest.EndTime()) {
+		return nil, ErrorContestNotEnded
+	}
+	// 私有比赛只有报名者可以虚拟参赛
+	if contest.Visibility == models.ContestPrivate {
+		registered, err := isContestRegistered(contestID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !registered {
+			return nil, ErrorContestNotRegistered
+		}
+	}
+	if v, err = mysql.GetVirtualParticipation(contestID, userID); err != nil {
+		return nil, err
+	}
+	if v != nil {
+		return nil, ErrorVirtualExists
+	}
+	count, err := mysql.CountUserContestSubmissions(contestID, userID, contest.EndTime())
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrorContestParticipated
+	}
+	teamID, err := mysql.GetContestTeamOfUser(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if teamID != 0 {
+		return nil, ErrorContestParticipated
+	}
+	// MySQL timestamp 只保存到秒
+	start := now.Truncate(time.Second)
+	v = &models.VirtualParticipation{
+		ContestID: contestID,
+		UserID:    userID,
+		StartTime: start,
+		EndTime:   start.Add(contest.EndTime().Sub(contest.StartTime)),
+	}
+	if err = mysql.CreateVirtualParticipation(v); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// GetVirtualScoreboard 虚拟参赛者的排行榜：按虚拟比赛已进行的时间截取真实参赛者的提交，
+// 与虚拟参赛者的提交一起排名。原比赛封榜时，虚拟比赛在相同时间点封榜
+func GetVirtualScoreboard(contestID, userID uint64) (board *ranking.Scoreboard, err error) {
+	contest, err := mysql.GetContestByID(contestID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := mysql.GetVirtualParticipation(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrorVirtualNotStarted
+	}
+	now := time.Now()
+	running := v.Running(now)
+	if contest.IsOIRule() && running {
+		return nil, ErrorScoreboardHidden
+	}
+	duration := v.EndTime.Sub(v.StartTime)
+	elapsed := duration
+	if running {
+		elapsed = now.Sub(v.StartTime)
+	}
+	freezeAt := contest.FreezeTime().Sub(contest.StartTime)
+	frozen := running && contest.Freeze > 0 && elapsed >= freezeAt
+
+	problems, participants, teamOf, err := scoreboardMembers(contest)
+	if err != nil {
+		return nil, err
+	}
+	user, err := mysql.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	// 报名了原比赛但没有提交的用户只展示为虚拟参赛
+	members := participants[:0]
+	for _, p := range participants {
+		if p.UserID != userID {
+			members = append(members, p)
+		}
+	}
+	participants = append(members, ranking.Participant{UserID: userID, Username: user.UserName, Virtual: true})
+
+	all, err := mysql.GetContestSubmissions(contestID)
+	if err != nil {
+		return nil, err
+	}
+	var others, mine []*models.Submission
+	for _, s := range all {
+		switch {
+		case s.UserID == userID && !s.CreateTime.Before(v.StartTime) && s.CreateTime.Before(v.EndTime):
+			mine = append(mine, s)
+		case !s.CreateTime.Before(contest.StartTime) && s.CreateTime.Sub(contest.StartTime) < elapsed:
+			others = append(others, s)
+		}
+	}
+	submissions, err := rankingSubmissions(contest, append(others, mine...), contest.StartTime, teamOf)
+	if err != nil {
+		return nil, err
+	}
+	// 虚拟参赛者的提交从虚拟开始时间计时
+	shift := v.StartTime.Sub(contest.StartTime)
+	for i := range submissions {
+		if i >= len(others) {
+			submissions[i].Time -= shift
+		} else if frozen && submissions[i].Time >= freezeAt {
+			freezeSubmission(&submissions[i])
+		}
+	}
+	sort.SliceStable(submissions, func(i, j int) bool {
+		return submissions[i].Time < submissions[j].Time
+	})
+	board = &ranking.Scoreboard{
+		Frozen: frozen,
+		Rows:   ranking.Get(contest.Rule).Rank(problems, participants, submissions),
+	}
+	return
+}
@@ -4,10 +4,20 @@ import (
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"sync"
 )
 
+// Conf 启动时读取的配置，热加载不会修改它，运行中需要最新配置时使用 Get
 var Conf = new(AppConfig)
 
+var (
+	confMu  sync.RWMutex
+	current *AppConfig // 热加载后的最新配置
+)
+
+// changeHooks 配置文件修改后的回调
+var changeHooks []func(*AppConfig)
+
 type AppConfig struct {
 	Mode         string `mapstructure:"mode"`
 	Port         int    `mapstructure:"port"`
@@ -18,6 +28,8 @@ type AppConfig struct {
 	*LogConfig   `mapstructure:"log"`
 	*MySQLConfig `mapstructure:"mysql"`
 	*RedisConfig `mapstructure:"redis"`
+	*JudgeConfig `mapstructure:"judge"`
+	Languages    []*LanguageConfig `mapstructure:"languages"`
 }
 
 type MySQLConfig struct {
@@ -39,6 +51,29 @@ type RedisConfig struct {
 	MinIdleConns int    `mapstructure:"min_idle_conns"`
 }
 
+type JudgeConfig struct {
+	DataDir string `mapstructure:"data_dir"` // 测试数据等评测文件的存放目录
+	WorkDir string `mapstructure:"work_dir"` // 编译运行的临时目录，为空时使用系统临时目录
+	Workers int    `mapstructure:"workers"`  // 评测机数量，为0时本实例只接收提交不评测
+	// 编译运行选手程序的用户和组，为0时以评测服务自身的用户运行，评测服务为root时拒绝启动评测机。
+	// 设置后评测服务需以root运行，数据目录会被设为仅评测服务可读
+	RunUID uint32 `mapstructure:"run_uid"`
+	RunGID uint32 `mapstructure:"run_gid"`
+}
+
+// LanguageConfig 评测语言配置，命令按空白切分后在评测临时目录中执行
+type LanguageConfig struct {
+	Name              string  `mapstructure:"name"`
+	DisplayName       string  `mapstructure:"display_name"`
+	SourceFile        string  `mapstructure:"source_file"`
+	Compile           string  `mapstructure:"compile"` // 为空表示无需编译
+	Run               string  `mapstructure:"run"`
+	TimeFactor        float64 `mapstructure:"time_factor"`
+	MemoryFactor      float64 `mapstructure:"memory_factor"`
+	LimitAddressSpace bool    `mapstructure:"limit_address_space"`
+	Enabled           bool    `mapstructure:"enabled"`
+}
+
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
 	Filename   string `mapstructure:"filename"`
@@ -53,15 +88,46 @@ func Init() error {
 	viper.WatchConfig()
 	viper.OnConfigChange(func(in fsnotify.Event) {
 		fmt.Println("夭寿啦~配置文件被人修改啦...")
-		viper.Unmarshal(&Conf)
+		// 解析到新的结构体再整体替换，正在使用旧配置的请求和评测机不受影响
+		conf := new(AppConfig)
+		if err := viper.Unmarshal(conf); err != nil {
+			fmt.Printf("unmarshal changed config failed, err:%v\n", err)
+			return
+		}
+		confMu.Lock()
+		current = conf
+		hooks := changeHooks
+		confMu.Unlock()
+		for _, fn := range hooks {
+			fn(conf)
+		}
 	})
 
 	err := viper.ReadInConfig()
 	if err != nil {
 		panic(fmt.Errorf("ReadInConfig failed, err: %v", err))
 	}
-	if err = viper.Unmarshal(&Conf); err != nil {
+	if err = viper.Unmarshal(Conf); err != nil {
 		panic(fmt.Errorf("unmarshal to Conf failed, err:%v", err))
 	}
+	confMu.Lock()
+	if current == nil {
+		current = Conf
+	}
+	confMu.Unlock()
 	return err
 }
+
+// Get 返回最新的配置，返回值不会再被修改，调用方不能修改它
+func Get() *AppConfig {
+	confMu.RLock()
+	defer confMu.RUnlock()
+	return current
+}
+
+// OnChange 注册配置文件修改后的回调，用于热加载
+func OnChange(fn func(*AppConfig)) {
+	confMu.Lock()
+	defer confMu.Unlock()
+	changeHooks = append(changeHooks, fn)
+}
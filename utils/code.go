@@ -13,6 +13,7 @@ const (
 	CodeInvalidToken      MyCode = 1006
 	CodeInvalidAuthFormat MyCode = 1007
 	CodeNotLogin          MyCode = 1008
+	CodeNoPermission      MyCode = 1009
 )
 
 var msgFlags = map[MyCode]string{
@@ -26,6 +27,7 @@ var msgFlags = map[MyCode]string{
 	CodeInvalidToken:      "无效的Token",
 	CodeInvalidAuthFormat: "认证格式有误",
 	CodeNotLogin:          "未登录",
+	CodeNoPermission:      "没有权限",
 }
 
 func (c MyCode) Msg() string {
@@ -7,7 +7,7 @@ import (
 
 type ResponseData struct {
 	Code    MyCode      `json:"code"`
-	Message string      `json:"message"`
+	Message interface{} `json:"message"`
 	Data    interface{} `json:"data,omitempty"` // omitempty当data为空时,不展示这个字段
 }
 
@@ -23,7 +23,7 @@ func ResponseError(ctx *gin.Context, c MyCode) {
 func ResponseErrorWithMsg(ctx *gin.Context, code MyCode, data interface{}) {
 	rd := &ResponseData{
 		Code:    code,
-		Message: code.Msg(),
+		Message: data,
 		Data:    nil,
 	}
 	ctx.JSON(http.StatusOK, rd)